@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"bada/internal/config"
+	"bada/internal/secure"
+	"bada/internal/storage"
+)
+
+const dbUsage = `Usage: bada db <command>
+
+Commands:
+  backup            Snapshot the database into the backup dir
+  restore [N|PATH]  List snapshots, preview one and swap it in
+  check             Run PRAGMA integrity_check
+  vacuum            Compact the database file`
+
+func runDB(store *storage.Store, cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		fmt.Println(dbUsage)
+		return nil
+	}
+	dir := cfg.Maintenance.BackupDir
+	switch args[0] {
+	case "backup":
+		path, err := store.Backup(dir)
+		if err != nil {
+			return err
+		}
+		pruned, err := store.PruneSnapshots(dir, cfg.Maintenance.BackupKeep)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("snapshot written to %s\n", path)
+		if pruned > 0 {
+			fmt.Printf("pruned %d old snapshot(s)\n", pruned)
+		}
+		return nil
+	case "restore":
+		return restoreDB(store, cfg, dir, args[1:])
+	case "check":
+		problems, err := store.IntegrityCheck()
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			fmt.Printf("%s: ok\n", store.DBPath())
+			return nil
+		}
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		return fmt.Errorf("%d integrity problem(s) found", len(problems))
+	case "vacuum":
+		before := fileSize(store.DBPath())
+		if err := store.Vacuum(); err != nil {
+			return err
+		}
+		fmt.Printf("vacuumed %s (%d -> %d bytes)\n", store.DBPath(), before, fileSize(store.DBPath()))
+		return nil
+	case "-h", "--help", "help":
+		fmt.Println(dbUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], dbUsage)
+	}
+}
+
+func restoreDB(store *storage.Store, cfg config.Config, dir string, args []string) error {
+	snaps, err := store.ListSnapshots(dir)
+	if err != nil {
+		return err
+	}
+	in := bufio.NewReader(os.Stdin)
+	choice := ""
+	if len(args) > 0 {
+		choice = args[0]
+	} else {
+		if len(snaps) == 0 {
+			return fmt.Errorf("no snapshots in %s", dir)
+		}
+		for i, snap := range snaps {
+			enc := ""
+			if snap.Encrypted {
+				enc = "  (encrypted)"
+			}
+			fmt.Printf("%3d  %s  %s%s\n", i+1, snap.CreatedAt.Local().Format("2006-01-02 15:04:05"), filepath.Base(snap.Path), enc)
+		}
+		fmt.Print("Snapshot number: ")
+		line, _ := in.ReadString('\n')
+		choice = strings.TrimSpace(line)
+	}
+	snap, err := pickSnapshot(snaps, choice)
+	if err != nil {
+		return err
+	}
+	info, err := store.InspectSnapshot(snap)
+	if errors.Is(err, secure.ErrPassphraseRequired) {
+		if err := unlockStore(store, cfg); err != nil {
+			return err
+		}
+		info, err = store.InspectSnapshot(snap)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read snapshot: %w", err)
+	}
+	fmt.Printf("%s\n  created %s\n  tasks %d (open %d, done %d), topics %d\n",
+		snap.Path, snap.CreatedAt.Local().Format("2006-01-02 15:04:05"), info.Tasks, info.Open, info.Done, info.Topics)
+	if len(info.Integrity) > 0 {
+		return fmt.Errorf("snapshot failed integrity check: %s", info.Integrity[0])
+	}
+	fmt.Printf("Replace %s with this snapshot? [y/N] ", store.DBPath())
+	line, _ := in.ReadString('\n')
+	if answer := strings.ToLower(strings.TrimSpace(line)); answer != "y" && answer != "yes" {
+		fmt.Println("restore cancelled")
+		return nil
+	}
+	safety, err := store.RestoreSnapshot(snap, dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored; previous database saved to %s\n", safety)
+	return nil
+}
+
+func pickSnapshot(snaps []storage.Snapshot, choice string) (storage.Snapshot, error) {
+	if choice == "" {
+		return storage.Snapshot{}, errors.New("no snapshot chosen")
+	}
+	if n, err := strconv.Atoi(choice); err == nil {
+		if n < 1 || n > len(snaps) {
+			return storage.Snapshot{}, fmt.Errorf("snapshot %d out of range (1-%d)", n, len(snaps))
+		}
+		return snaps[n-1], nil
+	}
+	info, err := os.Stat(choice)
+	if err != nil {
+		return storage.Snapshot{}, err
+	}
+	return storage.Snapshot{Path: choice, CreatedAt: info.ModTime(), Size: info.Size()}, nil
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"bada/internal/config"
+	"bada/internal/query"
+	"bada/internal/storage"
+)
+
+// runList prints the tasks matching a query, in the same language as the
+// TUI search: bada list topic:work is:open due<=+7d
+func runList(store *storage.Store, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the tasks as JSON (export format)")
+	viewName := fs.String("view", "", "run a saved view from [[views]]")
+	all := fs.Bool("all", false, "include snoozed tasks")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	src := strings.Join(fs.Args(), " ")
+	if *viewName != "" {
+		view, ok := findView(cfg.Views, *viewName)
+		if !ok {
+			return fmt.Errorf("no view named %q", *viewName)
+		}
+		terms := []string{viewFilterTerm(view.Filter), src}
+		if view.Query != "" {
+			terms = append([]string{"(" + view.Query + ")"}, terms...)
+		}
+		src = strings.Join(strings.Fields(strings.Join(terms, " ")), " ")
+	}
+	q, err := query.Parse(src)
+	if err != nil {
+		var qerr *query.Error
+		if errors.As(err, &qerr) {
+			fmt.Fprintf(os.Stderr, "  %s\n  %s\n", src, qerr.Marker(src))
+		}
+		return err
+	}
+	tasks, err := store.FetchTasks()
+	if err != nil {
+		return err
+	}
+	env := query.Env{Now: time.Now(), States: cfg.States}
+	matched := []storage.Task{}
+	for _, t := range tasks {
+		if !*all && t.SnoozedUntil.Valid && env.Now.Before(t.SnoozedUntil.Time) {
+			continue
+		}
+		if q.Match(t, env) {
+			matched = append(matched, t)
+		}
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(matched)
+	}
+	for _, t := range matched {
+		st, _ := config.ResolveState(cfg.States, t.Status, t.Done)
+		due := ""
+		if t.Due.Valid {
+			due = t.Due.Time.Local().Format("2006-01-02 15:04")
+			if h, m, s := t.Due.Time.Clock(); h == 0 && m == 0 && s == 0 {
+				// A date without a time is that day in any zone.
+				due = t.Due.Time.Format("2006-01-02")
+			}
+		}
+		line := fmt.Sprintf("#%-4d %-10s %-40s %-16s", t.ID, st.Name, t.Title, due)
+		if len(t.Topics) > 0 {
+			line += " [" + strings.Join(t.Topics, ",") + "]"
+		}
+		if tags := strings.Trim(t.Tags, ", "); tags != "" {
+			line += " " + tags
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	return nil
+}
+
+func findView(views []config.View, name string) (config.View, bool) {
+	for _, v := range views {
+		if strings.EqualFold(v.Name, name) {
+			return v, true
+		}
+	}
+	return config.View{}, false
+}
+
+// viewFilterTerm turns a view's :filter value into the matching query term.
+func viewFilterTerm(filter string) string {
+	switch filter {
+	case "", "all":
+		return ""
+	case "open", "closed":
+		return "is:" + filter
+	}
+	return "state:" + filter
+}
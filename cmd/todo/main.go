@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"bada/internal/config"
 	"bada/internal/storage"
@@ -22,15 +23,86 @@ func main() {
 		os.Exit(1)
 	}
 
-	store, err := storage.Open(cfg.DBPath, cfg.TrashDir)
+	workspace, args := splitWorkspaceFlag(os.Args[1:])
+	workspace, paths, err := cfg.ResolveWorkspace(workspace)
+	if err != nil {
+		fmt.Printf("failed to select workspace: %v\n", err)
+		os.Exit(1)
+	}
+
+	store, err := storage.Open(paths.DBPath, paths.TrashDir)
 	if err != nil {
 		fmt.Printf("failed to open database: %v\n", err)
 		os.Exit(1)
 	}
 	defer store.Close()
 
-	if err := ui.Run(store, cfg, configPath, firstLaunch); err != nil {
+	needsPassphrase := cfg.Encryption.Trash || cfg.Encryption.Backups
+	passphrase, err := resolvePassphrase(cfg, needsPassphrase)
+	if err != nil {
+		fmt.Printf("failed to read passphrase: %v\n", err)
+		store.Close()
+		os.Exit(1)
+	}
+	if err := store.SetEncryption(storage.EncryptionOptions{
+		Passphrase: passphrase,
+		Trash:      cfg.Encryption.Trash,
+		Backups:    cfg.Encryption.Backups,
+	}); err != nil {
+		fmt.Printf("failed to set up encryption: %v\n", err)
+		store.Close()
+		os.Exit(1)
+	}
+
+	if len(args) > 0 {
+		var run func(*storage.Store, config.Config, []string) error
+		switch args[0] {
+		case "db":
+			run = runDB
+		case "export":
+			run = runExport
+		case "import":
+			run = runImport
+		case "timesheet":
+			run = runTimesheet
+		case "remind":
+			run = runRemind
+		case "list":
+			run = runList
+		}
+		if run != nil {
+			cliCfg := cfg
+			cliCfg.Maintenance.BackupDir = paths.BackupDir
+			if err := run(store, cliCfg, args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "bada %s: %v\n", args[0], err)
+				store.Close()
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	if err := ui.Run(store, cfg, configPath, workspace, firstLaunch); err != nil {
 		fmt.Printf("error running program: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// splitWorkspaceFlag pulls a leading -w/--workspace NAME off the arguments.
+func splitWorkspaceFlag(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", args
+	}
+	switch arg := args[0]; {
+	case arg == "-w" || arg == "--workspace":
+		if len(args) < 2 {
+			return "", args[1:]
+		}
+		return args[1], args[2:]
+	case strings.HasPrefix(arg, "-w="):
+		return strings.TrimPrefix(arg, "-w="), args[1:]
+	case strings.HasPrefix(arg, "--workspace="):
+		return strings.TrimPrefix(arg, "--workspace="), args[1:]
+	}
+	return "", args
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+
+	"bada/internal/config"
+	"bada/internal/storage"
+)
+
+func lookupPassphrase(cfg config.Config) string {
+	if env := strings.TrimSpace(cfg.Encryption.PassphraseEnv); env != "" {
+		return os.Getenv(env)
+	}
+	return ""
+}
+
+func promptPassphrase(cfg config.Config, label string) (string, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("passphrase required: set %s", cfg.Encryption.PassphraseEnv)
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	pass, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if len(pass) == 0 {
+		return "", errors.New("empty passphrase")
+	}
+	return string(pass), nil
+}
+
+func resolvePassphrase(cfg config.Config, required bool) (string, error) {
+	if pass := lookupPassphrase(cfg); pass != "" || !required {
+		return pass, nil
+	}
+	return promptPassphrase(cfg, "bada passphrase")
+}
+
+func unlockStore(store *storage.Store, cfg config.Config) error {
+	pass, err := promptPassphrase(cfg, "bada passphrase")
+	if err != nil {
+		return err
+	}
+	return store.SetEncryption(storage.EncryptionOptions{
+		Passphrase: pass,
+		Trash:      cfg.Encryption.Trash,
+		Backups:    cfg.Encryption.Backups,
+	})
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"bada/internal/config"
+	"bada/internal/storage"
+)
+
+type reminder struct {
+	task   storage.Task
+	offset time.Duration
+}
+
+// reminderKey identifies one reminder the way the sent log does.
+type reminderKey struct {
+	id     int
+	due    int64
+	offset time.Duration
+}
+
+func (r reminder) key() reminderKey {
+	return reminderKey{id: r.task.ID, due: r.task.Due.Time.Unix(), offset: r.offset}
+}
+
+func runRemind(store *storage.Store, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("remind", flag.ContinueOnError)
+	daemon := fs.Bool("daemon", false, "keep running, checking every reminders.poll_interval")
+	dryRun := fs.Bool("dry-run", false, "print the reminders that are due without sending them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// A dry run marks nothing sent, so the daemon remembers what it already
+	// printed instead.
+	printed := map[reminderKey]bool{}
+	if !*daemon {
+		return sendDueReminders(store, cfg.Reminders, time.Now(), *dryRun, printed)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(cfg.Reminders.Interval())
+	defer ticker.Stop()
+	for {
+		// Each pass also catches up on reminders missed while asleep.
+		if err := sendDueReminders(store, cfg.Reminders, time.Now(), *dryRun, printed); err != nil {
+			fmt.Fprintf(os.Stderr, "bada remind: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func sendDueReminders(store *storage.Store, cfg config.Reminders, now time.Time, dryRun bool, printed map[reminderKey]bool) error {
+	tasks, err := store.FetchTasks()
+	if err != nil {
+		return err
+	}
+	// Offsets that came due together (say, after a suspend) share one
+	// notification.
+	pending := map[int][]reminder{}
+	var order []int
+	for _, r := range dueReminders(tasks, cfg.DefaultOffsets(), now, cfg.CatchUpWindow()) {
+		sent, err := store.ReminderSent(r.task.ID, r.task.Due.Time, r.offset)
+		if err != nil {
+			return err
+		}
+		if sent || dryRun && printed[r.key()] {
+			continue
+		}
+		if len(pending[r.task.ID]) == 0 {
+			order = append(order, r.task.ID)
+		}
+		pending[r.task.ID] = append(pending[r.task.ID], r)
+	}
+	var failed []string
+	for _, id := range order {
+		r := pending[id][0]
+		title, body := reminderText(r, now)
+		if dryRun {
+			fmt.Printf("%s: %s\n", title, body)
+			for _, r := range pending[id] {
+				printed[r.key()] = true
+			}
+			continue
+		}
+		if err := notify(cfg.Command, r, title, body); err != nil {
+			failed = append(failed, fmt.Sprintf("#%d: %v", id, err))
+			continue
+		}
+		for _, r := range pending[id] {
+			if err := store.MarkReminderSent(r.task.ID, r.task.Due.Time, r.offset, now); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("%s reminded #%d %s (%s)\n", now.Format("2006-01-02 15:04"), id, r.task.Title, body)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("notify failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// dueReminders lists the reminders whose time has come, skipping any older
+// than the catch-up window. A task's own offsets replace the defaults.
+func dueReminders(tasks []storage.Task, defaults []time.Duration, now time.Time, window time.Duration) []reminder {
+	var due []reminder
+	for _, t := range tasks {
+		if t.Done || !t.Due.Valid || t.SnoozedUntil.Valid && now.Before(t.SnoozedUntil.Time) {
+			continue
+		}
+		offsets := defaults
+		if strings.TrimSpace(t.Reminders) != "" {
+			if own, err := config.ParseReminders(t.Reminders); err == nil {
+				offsets = own
+			}
+		}
+		for _, offset := range offsets {
+			at := t.Due.Time.Add(-offset)
+			if at.After(now) || now.Sub(at) > window {
+				continue
+			}
+			due = append(due, reminder{task: t, offset: offset})
+		}
+	}
+	return due
+}
+
+func reminderText(r reminder, now time.Time) (string, string) {
+	due := r.task.Due.Time
+	when := due.Format("2006-01-02 15:04")
+	if due.Hour() == 0 && due.Minute() == 0 {
+		when = due.Format("2006-01-02")
+	} else if _, offset := due.Zone(); offset != localOffset(due) {
+		// Due in another zone: say which, and when that is here.
+		when += " " + due.Format("MST") + ", " + due.Local().Format("15:04") + " here"
+	}
+	left := due.Sub(now).Round(time.Minute)
+	switch {
+	case left > 0:
+		return r.task.Title, fmt.Sprintf("due %s (in %s)", when, shortDuration(left))
+	case left == 0:
+		return r.task.Title, fmt.Sprintf("due now (%s)", when)
+	default:
+		return r.task.Title, fmt.Sprintf("overdue since %s", when)
+	}
+}
+
+func localOffset(at time.Time) int {
+	_, offset := at.Local().Zone()
+	return offset
+}
+
+func shortDuration(d time.Duration) string {
+	mins := int(d / time.Minute)
+	switch {
+	case mins >= 48*60:
+		return fmt.Sprintf("%dd", mins/(24*60))
+	case mins < 60:
+		return fmt.Sprintf("%dm", mins)
+	case mins%60 == 0:
+		return fmt.Sprintf("%dh", mins/60)
+	default:
+		return fmt.Sprintf("%dh%02dm", mins/60, mins%60)
+	}
+}
+
+// notify runs reminders.command through sh -c, so quoting, pipes and
+// variables work as in a shell. {title}, {body}, {id} and {due} are
+// substituted shell-quoted; without {title} or {body} the two are appended,
+// as notify-send expects. Scripts also get BADA_TASK_ID, BADA_TITLE and
+// BADA_DUE.
+func notify(command string, r reminder, title, body string) error {
+	if strings.TrimSpace(command) == "" {
+		return errors.New("reminders.command is empty")
+	}
+	due := r.task.Due.Time.Format(time.RFC3339)
+	if !strings.Contains(command, "{title}") && !strings.Contains(command, "{body}") {
+		command += " {title} {body}"
+	}
+	command = strings.NewReplacer("{title}", shellQuote(title), "{body}", shellQuote(body),
+		"{id}", strconv.Itoa(r.task.ID), "{due}", shellQuote(due)).Replace(command)
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(), "BADA_TASK_ID="+strconv.Itoa(r.task.ID), "BADA_TITLE="+r.task.Title, "BADA_DUE="+due)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+func shellQuote(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"bada/internal/config"
+	"bada/internal/storage"
+)
+
+func runTimesheet(store *storage.Store, _ config.Config, args []string) error {
+	fs := flag.NewFlagSet("timesheet", flag.ContinueOnError)
+	asCSV := fs.Bool("csv", false, "print the entries as CSV instead of totals")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	now := time.Now()
+	from, to, err := storage.ParseTimeRange(strings.Join(fs.Args(), " "), now)
+	if err != nil {
+		return err
+	}
+	entries, err := store.TimeEntries(from, to)
+	if err != nil {
+		return err
+	}
+	if *asCSV {
+		return storage.WriteTimeCSV(os.Stdout, entries, now)
+	}
+	sum := storage.SummarizeTime(entries, from, to, now)
+	fmt.Printf("%s → %s  total %s\n", from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02"), storage.FormatClock(sum.Total))
+	for _, section := range []struct {
+		title  string
+		totals []storage.TimeTotal
+	}{{"day", sum.ByDay}, {"topic", sum.ByTopic}, {"tag", sum.ByTag}} {
+		fmt.Printf("\nby %s:\n", section.title)
+		for _, t := range section.totals {
+			fmt.Printf("  %-24s %10s\n", t.Key, storage.FormatClock(t.Duration))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"bada/internal/config"
+	"bada/internal/secure"
+	"bada/internal/storage"
+)
+
+func runExport(store *storage.Store, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := fs.String("o", "", "write to `file` instead of stdout")
+	encrypt := fs.Bool("encrypt", cfg.Encryption.Exports, "encrypt the export with the passphrase")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *encrypt && *out == "" {
+		return errors.New("encrypted exports need -o FILE")
+	}
+	data, err := store.MarshalExport()
+	if err != nil {
+		return err
+	}
+	if *encrypt {
+		pass, err := resolvePassphrase(cfg, true)
+		if err != nil {
+			return err
+		}
+		if data, err = secure.Encrypt(data, pass); err != nil {
+			return err
+		}
+	}
+	if *out == "" {
+		_, err := os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0o600); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported to %s\n", *out)
+	return nil
+}
+
+func runImport(store *storage.Store, cfg config.Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: bada import FILE (use - for stdin)")
+	}
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return err
+	}
+	if secure.IsEncrypted(data) {
+		pass, err := resolvePassphrase(cfg, true)
+		if err != nil {
+			return err
+		}
+		if data, err = secure.Decrypt(data, pass); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+	}
+	file, err := storage.ParseExport(data)
+	if err != nil {
+		return err
+	}
+	n, err := store.Import(file)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "imported %d task(s) into %s\n", n, store.DBPath())
+	return nil
+}
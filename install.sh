@@ -45,6 +45,7 @@ CACHE_DIR="${XDG_CACHE_HOME:-${HOME}/.cache}/bada"
 CONFIG_PATH="${CONFIG_DIR}/config.toml"
 DB_PATH="${CACHE_DIR}/bada.db"
 TRASH_DIR="${CACHE_DIR}/trash"
+BACKUP_DIR="${CACHE_DIR}/backups"
 
 mkdir -p "${CONFIG_DIR}" "${CACHE_DIR}"
 
@@ -52,6 +53,7 @@ if [[ ! -f "${CONFIG_PATH}" ]]; then
   tmpfile="$(mktemp)"
   sed -e "s|^db_path = .*|db_path = \"${DB_PATH}\"|" \
       -e "s|^trash_dir = .*|trash_dir = \"${TRASH_DIR}\"|" \
+      -e "s|^backup_dir = .*|backup_dir = \"${BACKUP_DIR}\"|" \
       "${ROOT_DIR}/config.example.toml" > "${tmpfile}"
   mv "${tmpfile}" "${CONFIG_PATH}"
   echo "Wrote default config to ${CONFIG_PATH}"
@@ -2,9 +2,14 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	toml "github.com/pelletier/go-toml/v2"
 )
@@ -14,6 +19,9 @@ const (
 	DefaultConfigPathFile = "config.path"
 	DefaultDBName         = "bada.db"
 	DefaultTrashDir       = "trash"
+	DefaultBackupDir      = "backups"
+	DefaultPassphraseEnv  = "BADA_PASSPHRASE"
+	DefaultWorkspaceName  = "default"
 )
 
 type Keymap struct {
@@ -39,6 +47,16 @@ type Keymap struct {
 	DeleteAllDone string `toml:"delete_all_done"`
 	Search        string `toml:"search"`
 	NoteView      string `toml:"note_view"`
+	Open          string `toml:"open_attachment"`
+	Timer         string `toml:"timer"`
+	Focus         string `toml:"focus"`
+	Status        string `toml:"status"`
+	Snooze        string `toml:"snooze"`
+	Finder        string `toml:"finder"`
+	SkipNext      string `toml:"skip_occurrence"`
+	Reschedule    string `toml:"reschedule_occurrence"`
+	UndoOverride  string `toml:"undo_occurrence"`
+	ZoneToggle    string `toml:"toggle_zone"`
 }
 
 type Theme struct {
@@ -58,12 +76,154 @@ type Theme struct {
 	StatusAltFg string `toml:"status_alt_fg"`
 }
 
+type Maintenance struct {
+	BackupDir      string `toml:"backup_dir"`
+	BackupInterval string `toml:"backup_interval"`
+	BackupKeep     int    `toml:"backup_keep"`
+	VacuumInterval string `toml:"vacuum_interval"`
+}
+
+type Encryption struct {
+	PassphraseEnv string `toml:"passphrase_env"`
+	Exports       bool   `toml:"exports"`
+	Backups       bool   `toml:"backups"`
+	Trash         bool   `toml:"trash"`
+}
+
+type Attachments struct {
+	Opener string `toml:"opener"`
+	Copy   bool   `toml:"copy"`
+}
+
+type Focus struct {
+	Work      string `toml:"work"`
+	Break     string `toml:"break"`
+	LongBreak string `toml:"long_break"`
+	LongEvery int    `toml:"long_break_every"`
+}
+
+// State is one step of the task workflow. Done states complete a task (set
+// completed_at, count as Recently Done); closed states only take it off the
+// open lists, like a cancelled task.
+type State struct {
+	Name   string `toml:"name"`
+	Key    string `toml:"key"`
+	Symbol string `toml:"symbol"`
+	Color  string `toml:"color"`
+	Done   bool   `toml:"done,omitempty"`
+	Closed bool   `toml:"closed,omitempty"`
+}
+
+func (s State) IsClosed() bool {
+	return s.Done || s.Closed
+}
+
+// ResolveState finds a task's workflow state from its status and done flag.
+// Tasks with no status, or one that is no longer configured, get the first
+// state matching their done flag; the index is len(states) when none does.
+func ResolveState(states []State, status string, done bool) (State, int) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	for i, st := range states {
+		if status != "" && st.Name == status && st.IsClosed() == done {
+			return st, i
+		}
+	}
+	for i, st := range states {
+		if done && st.Done || !done && !st.IsClosed() {
+			return st, i
+		}
+	}
+	return State{Name: "todo"}, len(states)
+}
+
+// WorkHours ("09:00-18:00") is the stretch of the day the week and day
+// timelines open on and draw as working time.
+type Planning struct {
+	DailyCapacity string `toml:"daily_capacity"`
+	WorkHours     string `toml:"work_hours"`
+}
+
+// Recurrence says what completing a recurring task does: "advance" moves it
+// to its next occurrence and reopens it, "spawn" keeps the completed task and
+// adds a copy for the next one. A task's own "On Complete" field wins.
+// HabitWeeks is how far back the habit grids go.
+type Recurrence struct {
+	OnComplete string `toml:"on_complete"`
+	HabitWeeks int    `toml:"habit_weeks"`
+}
+
+// NormalizeOnComplete checks an on_complete value; empty stays empty.
+func NormalizeOnComplete(mode string) (string, bool) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "", "advance", "spawn":
+		return mode, true
+	}
+	return mode, false
+}
+
+// Board configures the :board view. WIP limits are keyed by column name (a
+// state or a topic); zero or missing means no limit.
+type Board struct {
+	GroupBy   string         `toml:"group_by"`
+	WIPLimits map[string]int `toml:"wip_limits,omitempty"`
+}
+
+// Reminders drives "bada remind". Offsets are how long before the due time
+// to notify; the command gets {title} and {body} (appended when absent).
+type Reminders struct {
+	Offsets      []string `toml:"offsets"`
+	Command      string   `toml:"command"`
+	PollInterval string   `toml:"poll_interval"`
+	CatchUp      string   `toml:"catch_up"`
+}
+
+// Command is a [commands.<name>] entry run from command mode as :<name>.
+// Run goes through sh with the tasks as JSON on stdin; Input picks them
+// ("selected", the default, falls back to the current task; "current";
+// "visible") and Output says what to do with stdout ("pager", the default;
+// "update" to apply it as task changes; "none").
+type Command struct {
+	Run         string `toml:"run"`
+	Input       string `toml:"input,omitempty"`
+	Output      string `toml:"output,omitempty"`
+	Description string `toml:"description,omitempty"`
+}
+
+// View is a [[views]] entry: a saved search listed as a virtual topic.
+// Query uses the / search syntax; Filter (a :filter value) and Sort (a sort
+// mode) apply while the view is open.
+type View struct {
+	Name   string `toml:"name"`
+	Query  string `toml:"query"`
+	Filter string `toml:"filter,omitempty"`
+	Sort   string `toml:"sort,omitempty"`
+}
+
+type Workspace struct {
+	DBPath    string `toml:"db_path"`
+	TrashDir  string `toml:"trash_dir,omitempty"`
+	BackupDir string `toml:"backup_dir,omitempty"`
+}
+
 type Config struct {
-	DBPath        string `toml:"db_path"`
-	DefaultFilter string `toml:"default_filter"`
-	TrashDir      string `toml:"trash_dir"`
-	Keys          Keymap `toml:"keys"`
-	Theme         Theme  `toml:"theme"`
+	DBPath           string               `toml:"db_path"`
+	DefaultFilter    string               `toml:"default_filter"`
+	TrashDir         string               `toml:"trash_dir"`
+	DefaultWorkspace string               `toml:"default_workspace,omitempty"`
+	Workspaces       map[string]Workspace `toml:"workspaces,omitempty"`
+	Keys             Keymap               `toml:"keys"`
+	Theme            Theme                `toml:"theme"`
+	Maintenance      Maintenance          `toml:"maintenance"`
+	Encryption       Encryption           `toml:"encryption"`
+	Attachments      Attachments          `toml:"attachments"`
+	Focus            Focus                `toml:"focus"`
+	Planning         Planning             `toml:"planning"`
+	Recurrence       Recurrence           `toml:"recurrence"`
+	States           []State              `toml:"states"`
+	Board            Board                `toml:"board"`
+	Reminders        Reminders            `toml:"reminders"`
+	Commands         map[string]Command   `toml:"commands,omitempty"`
+	Views            []View               `toml:"views,omitempty"`
 }
 
 func LoadOrCreate(path string) (Config, error) {
@@ -86,12 +246,33 @@ func LoadOrCreate(path string) (Config, error) {
 		return cfg, err
 	}
 	applyKeyDefaults(&cfg)
+	cfg.States = normalizeStates(cfg.States)
+	cfg.Commands = normalizeCommands(cfg.Commands)
+	cfg.Views = normalizeViews(cfg.Views)
 	if cfg.DBPath == "" {
 		cfg.DBPath = DefaultDBPath()
 	}
 	if cfg.TrashDir == "" {
 		cfg.TrashDir = DefaultTrashPath()
 	}
+	if cfg.Maintenance.BackupDir == "" {
+		cfg.Maintenance.BackupDir = DefaultBackupPath()
+	}
+	if cfg.Encryption.PassphraseEnv == "" {
+		cfg.Encryption.PassphraseEnv = DefaultPassphraseEnv
+	}
+	if cfg.Attachments.Opener == "" {
+		cfg.Attachments.Opener = DefaultOpener()
+	}
+	if mode, ok := NormalizeOnComplete(cfg.Recurrence.OnComplete); !ok || mode == "" {
+		cfg.Recurrence.OnComplete = "advance"
+	} else {
+		cfg.Recurrence.OnComplete = mode
+	}
+	if cfg.Recurrence.HabitWeeks <= 0 {
+		cfg.Recurrence.HabitWeeks = defaultConfig().Recurrence.HabitWeeks
+	}
+	cfg.Recurrence.HabitWeeks = min(cfg.Recurrence.HabitWeeks, 53)
 	return cfg, nil
 }
 
@@ -163,6 +344,133 @@ func applyKeyDefaults(cfg *Config) {
 	if cfg.Keys.NoteView == "" {
 		cfg.Keys.NoteView = def.NoteView
 	}
+	if cfg.Keys.Open == "" {
+		cfg.Keys.Open = def.Open
+	}
+	if cfg.Keys.Timer == "" {
+		cfg.Keys.Timer = def.Timer
+	}
+	if cfg.Keys.Focus == "" {
+		cfg.Keys.Focus = def.Focus
+	}
+	if cfg.Keys.Status == "" {
+		cfg.Keys.Status = def.Status
+	}
+	if cfg.Keys.Snooze == "" {
+		cfg.Keys.Snooze = def.Snooze
+	}
+	if cfg.Keys.Finder == "" {
+		cfg.Keys.Finder = def.Finder
+	}
+	if cfg.Keys.SkipNext == "" {
+		cfg.Keys.SkipNext = def.SkipNext
+	}
+	if cfg.Keys.Reschedule == "" {
+		cfg.Keys.Reschedule = def.Reschedule
+	}
+	if cfg.Keys.UndoOverride == "" {
+		cfg.Keys.UndoOverride = def.UndoOverride
+	}
+	if cfg.Keys.ZoneToggle == "" {
+		cfg.Keys.ZoneToggle = def.ZoneToggle
+	}
+}
+
+// normalizeCommands lower-cases command names and fills in the default
+// input and output, dropping entries without anything to run.
+func normalizeCommands(cmds map[string]Command) map[string]Command {
+	if len(cmds) == 0 {
+		return nil
+	}
+	out := make(map[string]Command, len(cmds))
+	for name, c := range cmds {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || strings.ContainsAny(name, " \t:") || strings.TrimSpace(c.Run) == "" {
+			continue
+		}
+		c.Input = strings.ToLower(strings.TrimSpace(c.Input))
+		if c.Input == "" {
+			c.Input = "selected"
+		}
+		c.Output = strings.ToLower(strings.TrimSpace(c.Output))
+		if c.Output == "" {
+			c.Output = "pager"
+		}
+		out[name] = c
+	}
+	return out
+}
+
+// normalizeViews drops unnamed views and later duplicates of a name.
+func normalizeViews(views []View) []View {
+	out := make([]View, 0, len(views))
+	seen := map[string]bool{}
+	for _, v := range views {
+		v.Name = strings.TrimSpace(v.Name)
+		key := strings.ToLower(v.Name)
+		if v.Name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		v.Query = strings.TrimSpace(v.Query)
+		v.Filter = strings.ToLower(strings.TrimSpace(v.Filter))
+		v.Sort = strings.ToLower(strings.TrimSpace(v.Sort))
+		out = append(out, v)
+	}
+	return out
+}
+
+func DefaultStates() []State {
+	return []State{
+		{Name: "todo", Key: "t", Symbol: "⏳"},
+		{Name: "doing", Key: "i", Symbol: "▶", Color: "#5B8DEF"},
+		{Name: "waiting", Key: "w", Symbol: "⏸", Color: "#E9C46A"},
+		{Name: "done", Key: "d", Symbol: "✓", Color: "#3CB371", Done: true},
+		{Name: "cancelled", Key: "c", Symbol: "✗", Color: "#6B7280", Closed: true},
+	}
+}
+
+// normalizeStates drops unnamed and duplicate states and makes sure there is
+// at least one open and one done state, so toggling always has a target.
+func normalizeStates(states []State) []State {
+	if len(states) == 0 {
+		return DefaultStates()
+	}
+	seen := map[string]bool{}
+	out := make([]State, 0, len(states))
+	hasOpen, hasDone := false, false
+	for _, st := range states {
+		st.Name = strings.ToLower(strings.TrimSpace(st.Name))
+		if st.Name == "" || seen[st.Name] {
+			continue
+		}
+		seen[st.Name] = true
+		if st.Symbol == "" {
+			st.Symbol = "•"
+			if st.IsClosed() {
+				st.Symbol = "✓"
+			}
+		}
+		hasOpen = hasOpen || !st.IsClosed()
+		hasDone = hasDone || st.Done
+		out = append(out, st)
+	}
+	defaults := DefaultStates()
+	if !hasOpen {
+		open := defaults[0]
+		if seen[open.Name] {
+			open.Name = "open"
+		}
+		out = append([]State{open}, out...)
+	}
+	if !hasDone {
+		done := defaults[3]
+		if seen[done.Name] {
+			done.Name = "completed"
+		}
+		out = append(out, done)
+	}
+	return out
 }
 
 func write(path string, cfg Config) error {
@@ -204,6 +512,53 @@ func defaultConfig() Config {
 			DeleteAllDone: "X",
 			Search:        "/",
 			NoteView:      "enter",
+			Open:          "o",
+			Timer:         "t",
+			Focus:         "f",
+			Status:        "S",
+			Snooze:        "z",
+			Finder:        "ctrl+p",
+			SkipNext:      ">",
+			Reschedule:    "M",
+			UndoOverride:  "U",
+			ZoneToggle:    "Z",
+		},
+		Maintenance: Maintenance{
+			BackupDir:      DefaultBackupPath(),
+			BackupInterval: "1d",
+			BackupKeep:     7,
+			VacuumInterval: "7d",
+		},
+		Encryption: Encryption{
+			PassphraseEnv: DefaultPassphraseEnv,
+		},
+		Attachments: Attachments{
+			Opener: DefaultOpener(),
+		},
+		Focus: Focus{
+			Work:      "25m",
+			Break:     "5m",
+			LongBreak: "15m",
+			LongEvery: 4,
+		},
+		Planning: Planning{
+			DailyCapacity: "8h",
+			WorkHours:     "09:00-18:00",
+		},
+		Recurrence: Recurrence{
+			OnComplete: "advance",
+			HabitWeeks: 12,
+		},
+		States: DefaultStates(),
+		Reminders: Reminders{
+			Offsets:      []string{"1d", "1h"},
+			Command:      "notify-send {title} {body}",
+			PollInterval: "1m",
+			CatchUp:      "24h",
+		},
+		Board: Board{
+			GroupBy:   "state",
+			WIPLimits: map[string]int{"doing": 3},
 		},
 		Theme: Theme{
 			Title:       "#5B8DEF",
@@ -259,6 +614,202 @@ func DefaultTrashPath() string {
 	return filepath.Join(DefaultCacheDir(), DefaultTrashDir)
 }
 
+func DefaultBackupPath() string {
+	return filepath.Join(DefaultCacheDir(), DefaultBackupDir)
+}
+
+func DefaultOpener() string {
+	if runtime.GOOS == "darwin" {
+		return "open"
+	}
+	return "xdg-open"
+}
+
+// Lengths returns the work, short break and long break durations, falling
+// back to 25/5/15 minutes for unset or invalid values.
+func (f Focus) Lengths() (time.Duration, time.Duration, time.Duration) {
+	pick := func(v string, def time.Duration) time.Duration {
+		if d, err := ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		return def
+	}
+	return pick(f.Work, 25*time.Minute), pick(f.Break, 5*time.Minute), pick(f.LongBreak, 15*time.Minute)
+}
+
+// Capacity is the work that fits in a day; zero turns overload warnings off.
+func (p Planning) Capacity() time.Duration {
+	d, err := ParseDuration(p.DailyCapacity)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// Hours is the working day as minutes from midnight, falling back to
+// 09:00-18:00 for unset or invalid values.
+func (p Planning) Hours() (int, int) {
+	from, to, ok := strings.Cut(strings.TrimSpace(p.WorkHours), "-")
+	start, err1 := time.Parse("15:04", strings.TrimSpace(from))
+	end, err2 := time.Parse("15:04", strings.TrimSpace(to))
+	if !ok || err1 != nil || err2 != nil || !end.After(start) {
+		return 9 * 60, 18 * 60
+	}
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute()
+}
+
+// ParseReminders reads a comma-separated list of offsets such as
+// "1d, 1h, 0m"; "off" or "none" yields no reminders.
+func ParseReminders(spec string) ([]time.Duration, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "off" || spec == "none" {
+		return []time.Duration{}, nil
+	}
+	var offsets []time.Duration
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := ParseDuration(part)
+		if err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, d)
+	}
+	return offsets, nil
+}
+
+// DefaultOffsets returns the configured offsets, skipping invalid entries.
+func (r Reminders) DefaultOffsets() []time.Duration {
+	var offsets []time.Duration
+	for _, o := range r.Offsets {
+		if d, err := ParseDuration(o); err == nil {
+			offsets = append(offsets, d)
+		}
+	}
+	return offsets
+}
+
+func (r Reminders) Interval() time.Duration {
+	d, err := ParseDuration(r.PollInterval)
+	if err != nil || d < time.Second {
+		return time.Minute
+	}
+	return d
+}
+
+// CatchUpWindow is how late a missed reminder may still fire.
+func (r Reminders) CatchUpWindow() time.Duration {
+	d, err := ParseDuration(r.CatchUp)
+	if err != nil {
+		return 24 * time.Hour
+	}
+	return d
+}
+
+func (m Maintenance) BackupEvery() time.Duration {
+	d, _ := ParseDuration(m.BackupInterval)
+	return d
+}
+
+func (m Maintenance) VacuumEvery() time.Duration {
+	d, _ := ParseDuration(m.VacuumInterval)
+	return d
+}
+
+// ParseDuration accepts time.ParseDuration input plus d (day) and w (week)
+// units, e.g. "90m", "1d", "2w", "1d12h". "off" and "0" mean disabled.
+func ParseDuration(v string) (time.Duration, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" || v == "0" || v == "off" || v == "none" {
+		return 0, nil
+	}
+	var total time.Duration
+	rest := v
+	for _, unit := range []struct {
+		suffix string
+		size   time.Duration
+	}{{"w", 7 * 24 * time.Hour}, {"d", 24 * time.Hour}} {
+		idx := strings.Index(rest, unit.suffix)
+		if idx < 0 {
+			continue
+		}
+		n, err := strconv.Atoi(rest[:idx])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", v)
+		}
+		total += time.Duration(n) * unit.size
+		rest = rest[idx+1:]
+	}
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", v)
+		}
+		total += d
+	}
+	return total, nil
+}
+
+// WorkspaceNames lists "default" (the top-level db_path) followed by the
+// configured workspaces in name order.
+func (c Config) WorkspaceNames() []string {
+	names := []string{DefaultWorkspaceName}
+	for name := range c.Workspaces {
+		if name != DefaultWorkspaceName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// ResolveWorkspace returns the effective paths of a workspace. An empty name
+// selects default_workspace. Unset paths are derived from the workspace name
+// so two workspaces never share a database or trash dir by accident.
+func (c Config) ResolveWorkspace(name string) (string, Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = strings.TrimSpace(c.DefaultWorkspace)
+	}
+	if name == "" {
+		name = DefaultWorkspaceName
+	}
+	ws, ok := c.Workspaces[name]
+	if !ok {
+		if name != DefaultWorkspaceName {
+			return "", Workspace{}, fmt.Errorf("unknown workspace %q (have: %s)", name, strings.Join(c.WorkspaceNames(), ", "))
+		}
+		ws = Workspace{DBPath: c.DBPath, TrashDir: c.TrashDir}
+	}
+	if ws.DBPath == "" {
+		ws.DBPath = filepath.Join(DefaultCacheDir(), name+".db")
+	}
+	if ws.TrashDir == "" {
+		ws.TrashDir = filepath.Join(filepath.Dir(ws.DBPath), DefaultTrashDir+"-"+name)
+	}
+	if ws.BackupDir == "" {
+		ws.BackupDir = c.Maintenance.BackupDir
+	}
+	return name, ws, nil
+}
+
+// SetWorkspaceDBPath points a workspace at a new database file, updating the
+// top-level db_path for the default workspace.
+func (c *Config) SetWorkspaceDBPath(name, dbPath string) {
+	ws, ok := c.Workspaces[name]
+	if !ok && (name == "" || name == DefaultWorkspaceName) {
+		c.DBPath = dbPath
+		return
+	}
+	ws.DBPath = dbPath
+	if c.Workspaces == nil {
+		c.Workspaces = map[string]Workspace{}
+	}
+	c.Workspaces[name] = ws
+}
+
 func ResolveConfigPath() string {
 	if env := strings.TrimSpace(os.Getenv("BADA_CONFIG")); env != "" {
 		return env
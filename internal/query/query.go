@@ -0,0 +1,621 @@
+// Package query parses and evaluates the task search language shared by the
+// TUI search prompt, saved views and "bada list":
+//
+//	topic:work tag:urgent prio>=3 due<=+7d is:open -tag:someday (title:report OR notes:invoice)
+//
+// Terms next to each other must all match; OR, NOT/-, and parentheses work
+// as usual. Bare words search the title, topics, tags and due date.
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"bada/internal/config"
+	"bada/internal/storage"
+)
+
+// Env is what a query needs besides the task: the clock for relative dates
+// and the workflow states for is:/state: terms.
+type Env struct {
+	Now    time.Time
+	States []config.State
+}
+
+type matcher func(t storage.Task, env Env) bool
+
+// Query is a parsed query. The zero value (and an empty source) matches
+// every task.
+type Query struct {
+	src   string
+	match matcher
+}
+
+// Error is a parse error; Pos and Len mark the offending token in the
+// source, in bytes.
+type Error struct {
+	Pos int
+	Len int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (col %d)", e.Msg, e.Pos+1)
+}
+
+// Marker underlines the offending token when printed below the source.
+func (e *Error) Marker(src string) string {
+	pos := min(max(e.Pos, 0), len(src))
+	width := utf8.RuneCountInString(src[pos:min(len(src), pos+max(e.Len, 1))])
+	return strings.Repeat(" ", utf8.RuneCountInString(src[:pos])) + strings.Repeat("^", max(width, 1))
+}
+
+// Help lists the fields and operators, one per line, for help screens.
+var Help = []string{
+	"word \"a phrase\"     title, topics, tags or due date contain it",
+	"title:x notes:x     field contains x",
+	"tag:x topic:x       has the tag/topic (x* matches a prefix)",
+	"prio>=3 prio:0      priority (also = != < <= >)",
+	"due<=+7d due:today  due date; also start:, created:, completed:",
+	"                    dates: today tomorrow yesterday YYYY-MM-DD +3d -2w +1m, none, any",
+	"est>=1h             estimate (30m, 1h30m, or minutes)",
+	"is:open is:done     also closed, overdue, recurring, snoozed or a state name",
+	"state:doing         workflow state",
+	"has:notes           also due, start, tags, topic, estimate, reminders, attachments",
+	"id:12 id>100        task ID",
+	"a b  a OR b  -a  NOT a  (a OR b) c",
+}
+
+// Parse compiles src. Errors are *Error values pointing at the token that
+// could not be understood.
+func Parse(src string) (Query, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return Query{}, err
+	}
+	p := &parser{src: src, toks: toks}
+	if len(toks) == 0 {
+		return Query{src: src}, nil
+	}
+	m, err := p.parseOr()
+	if err != nil {
+		return Query{}, err
+	}
+	if p.pos < len(p.toks) {
+		tok := p.toks[p.pos]
+		return Query{}, &Error{Pos: tok.pos, Len: len(tok.raw), Msg: fmt.Sprintf("unexpected %q", tok.raw)}
+	}
+	return Query{src: src, match: m}, nil
+}
+
+// Empty reports whether the query has no terms and so matches everything.
+func (q Query) Empty() bool {
+	return q.match == nil
+}
+
+func (q Query) String() string {
+	return q.src
+}
+
+func (q Query) Match(t storage.Task, env Env) bool {
+	if q.match == nil {
+		return true
+	}
+	if env.Now.IsZero() {
+		env.Now = time.Now()
+	}
+	return q.match(t, env)
+}
+
+type tokKind int
+
+const (
+	tokWord tokKind = iota
+	tokOpen
+	tokClose
+)
+
+type token struct {
+	kind tokKind
+	pos  int
+	raw  string
+	// text is raw with quotes removed.
+	text   string
+	quoted bool
+}
+
+func tokenize(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			toks = append(toks, token{kind: tokOpen, pos: i, raw: "("})
+			i++
+		case c == ')':
+			toks = append(toks, token{kind: tokClose, pos: i, raw: ")"})
+			i++
+		default:
+			start := i
+			var text strings.Builder
+			quoted := false
+			for i < len(src) && !strings.ContainsRune(" \t\n()", rune(src[i])) {
+				if src[i] != '"' {
+					text.WriteByte(src[i])
+					i++
+					continue
+				}
+				end := strings.IndexByte(src[i+1:], '"')
+				if end < 0 {
+					return nil, &Error{Pos: i, Len: len(src) - i, Msg: "unterminated quote"}
+				}
+				text.WriteString(src[i+1 : i+1+end])
+				i += end + 2
+				quoted = true
+			}
+			toks = append(toks, token{kind: tokWord, pos: start, raw: src[start:i], text: text.String(), quoted: quoted})
+		}
+	}
+	return toks, nil
+}
+
+type parser struct {
+	src  string
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func isKeyword(tok token, word string) bool {
+	return tok.kind == tokWord && !tok.quoted && strings.EqualFold(tok.raw, word)
+}
+
+func (p *parser) parseOr() (matcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	alts := []matcher{left}
+	for {
+		tok, ok := p.peek()
+		if !ok || !isKeyword(tok, "or") {
+			break
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, right)
+	}
+	if len(alts) == 1 {
+		return left, nil
+	}
+	return func(t storage.Task, env Env) bool {
+		for _, m := range alts {
+			if m(t, env) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func (p *parser) parseAnd() (matcher, error) {
+	var all []matcher
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == tokClose || isKeyword(tok, "or") {
+			break
+		}
+		if isKeyword(tok, "and") {
+			p.pos++
+			continue
+		}
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, m)
+	}
+	if len(all) == 0 {
+		tok, ok := p.peek()
+		if !ok {
+			return nil, &Error{Pos: len(p.src), Len: 1, Msg: "missing term at end of query"}
+		}
+		return nil, &Error{Pos: tok.pos, Len: len(tok.raw), Msg: fmt.Sprintf("expected a term before %q", tok.raw)}
+	}
+	if len(all) == 1 {
+		return all[0], nil
+	}
+	return func(t storage.Task, env Env) bool {
+		for _, m := range all {
+			if !m(t, env) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func (p *parser) parseUnary() (matcher, error) {
+	tok, _ := p.peek()
+	switch {
+	case isKeyword(tok, "not"):
+		p.pos++
+		if _, ok := p.peek(); !ok {
+			return nil, &Error{Pos: tok.pos, Len: len(tok.raw), Msg: "NOT needs a term after it"}
+		}
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negate(m), nil
+	case tok.kind == tokOpen:
+		p.pos++
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := p.peek()
+		if !ok || closing.kind != tokClose {
+			return nil, &Error{Pos: tok.pos, Len: 1, Msg: "unclosed parenthesis"}
+		}
+		p.pos++
+		return m, nil
+	case tok.kind == tokClose:
+		return nil, &Error{Pos: tok.pos, Len: 1, Msg: "unmatched )"}
+	}
+	p.pos++
+	if strings.HasPrefix(tok.raw, "-") && len(tok.raw) > 1 {
+		inner := tok
+		inner.pos++
+		inner.raw = tok.raw[1:]
+		inner.text = strings.TrimPrefix(tok.text, "-")
+		m, err := parseTerm(inner)
+		if err != nil {
+			return nil, err
+		}
+		return negate(m), nil
+	}
+	return parseTerm(tok)
+}
+
+func negate(m matcher) matcher {
+	return func(t storage.Task, env Env) bool { return !m(t, env) }
+}
+
+var operators = []string{">=", "<=", "!=", ":", "=", ">", "<"}
+
+// fields are the names parseTerm knows; any other "word:rest" is reported
+// as an unknown field so a typo like tga:urgent does not quietly become text.
+var fields = map[string]bool{
+	"title": true, "notes": true, "text": true, "tag": true, "tags": true, "topic": true, "topics": true,
+	"prio": true, "priority": true, "p": true, "id": true, "est": true, "estimate": true,
+	"due": true, "start": true, "created": true, "completed": true, "done": true,
+	"is": true, "state": true, "status": true, "has": true,
+}
+
+// splitTerm finds "field op value" in a word. Quoted words, words that do not
+// start with letters followed by an operator (10:30, v2:x) and URLs
+// (https://...) are free text.
+func splitTerm(tok token) (field, op, value string, ok bool) {
+	if strings.HasPrefix(tok.raw, "\"") {
+		return "", "", "", false
+	}
+	end := 0
+	for end < len(tok.raw) && (tok.raw[end] >= 'a' && tok.raw[end] <= 'z' || tok.raw[end] >= 'A' && tok.raw[end] <= 'Z') {
+		end++
+	}
+	if end == 0 || strings.HasPrefix(tok.raw[end:], "://") {
+		return "", "", "", false
+	}
+	for _, o := range operators {
+		if strings.HasPrefix(tok.raw[end:], o) {
+			value = tok.text[end+len(o):]
+			return strings.ToLower(tok.raw[:end]), o, value, true
+		}
+	}
+	return "", "", "", false
+}
+
+func parseTerm(tok token) (matcher, error) {
+	field, op, value, ok := splitTerm(tok)
+	if !ok {
+		word := strings.ToLower(tok.text)
+		return func(t storage.Task, env Env) bool { return matchesText(t, word) }, nil
+	}
+	fail := func(format string, args ...any) (matcher, error) {
+		return nil, &Error{Pos: tok.pos, Len: len(tok.raw), Msg: fmt.Sprintf(format, args...)}
+	}
+	if value == "" && fields[field] {
+		return fail("%s%s needs a value", field, op)
+	}
+	lower := strings.ToLower(value)
+	switch field {
+	case "title", "notes", "text":
+		if op != ":" && op != "=" && op != "!=" {
+			return fail("%s only supports : and !=", field)
+		}
+		m := func(t storage.Task, env Env) bool {
+			switch field {
+			case "title":
+				return strings.Contains(strings.ToLower(t.Title), lower)
+			case "notes":
+				return strings.Contains(strings.ToLower(t.Notes), lower)
+			}
+			return matchesText(t, lower)
+		}
+		return withNot(op, m), nil
+	case "tag", "tags", "topic", "topics":
+		if op != ":" && op != "=" && op != "!=" {
+			return fail("%s only supports : and !=", field)
+		}
+		topics := strings.HasPrefix(field, "topic")
+		m := func(t storage.Task, env Env) bool {
+			list := t.Topics
+			if !topics {
+				list = strings.Split(t.Tags, ",")
+			}
+			for _, v := range list {
+				if matchesName(strings.ToLower(strings.TrimSpace(v)), lower) {
+					return true
+				}
+			}
+			return false
+		}
+		return withNot(op, m), nil
+	case "prio", "priority", "p", "id", "est", "estimate":
+		var n int
+		var err error
+		if field == "est" || field == "estimate" {
+			n, err = parseMinutes(lower)
+		} else {
+			n, err = strconv.Atoi(lower)
+		}
+		if err != nil {
+			return fail("%s needs a number, got %q", field, value)
+		}
+		get := func(t storage.Task) int {
+			switch field {
+			case "id":
+				return t.ID
+			case "est", "estimate":
+				return t.EstimateMinutes
+			}
+			return t.Priority
+		}
+		return func(t storage.Task, env Env) bool { return compare(get(t), op, n) }, nil
+	case "due", "start", "created", "completed", "done":
+		get := func(t storage.Task) (time.Time, bool) {
+			switch field {
+			case "start":
+				return t.Start.Time, t.Start.Valid
+			case "created":
+				return t.CreatedAt, !t.CreatedAt.IsZero()
+			case "completed", "done":
+				return t.CompletedAt.Time, t.CompletedAt.Valid
+			}
+			return t.Due.Time, t.Due.Valid
+		}
+		switch lower {
+		case "none", "any":
+			if op != ":" && op != "=" && op != "!=" {
+				return fail("%s:%s only supports : and !=", field, lower)
+			}
+			want := lower == "any"
+			return withNot(op, func(t storage.Task, env Env) bool {
+				_, ok := get(t)
+				return ok == want
+			}), nil
+		}
+		if _, err := parseDay(lower, time.Now()); err != nil {
+			return fail("%s: %v", field, err)
+		}
+		return func(t storage.Task, env Env) bool {
+			at, ok := get(t)
+			if !ok {
+				return op == "!="
+			}
+			day, _ := parseDay(lower, env.Now)
+			loc := env.Now.Location()
+			local := at.In(loc)
+			if h, m, s := at.Clock(); h == 0 && m == 0 && s == 0 {
+				// A date without a time is that day in whatever zone it is read.
+				local = at
+			}
+			got := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+			return compareTime(got, op, day)
+		}, nil
+	case "is":
+		if op != ":" && op != "=" && op != "!=" {
+			return fail("is only supports : and !=")
+		}
+		switch lower {
+		case "open", "closed", "done", "overdue", "recurring", "snoozed":
+		default:
+			if !isStateName(lower) {
+				return fail("unknown is:%s: use open, closed, done, overdue, recurring, snoozed or a state name", value)
+			}
+		}
+		return withNot(op, func(t storage.Task, env Env) bool { return isMatch(t, env, lower) }), nil
+	case "state", "status":
+		if op != ":" && op != "=" && op != "!=" {
+			return fail("%s only supports : and !=", field)
+		}
+		return withNot(op, func(t storage.Task, env Env) bool {
+			st, _ := config.ResolveState(env.States, t.Status, t.Done)
+			return st.Name == lower
+		}), nil
+	case "has":
+		if op != ":" && op != "=" && op != "!=" {
+			return fail("has only supports : and !=")
+		}
+		get, ok := hasFields[lower]
+		if !ok {
+			return fail("unknown has:%s: use due, start, notes, tags, topic, estimate, reminders or attachments", value)
+		}
+		return withNot(op, func(t storage.Task, env Env) bool { return get(t) }), nil
+	}
+	return fail("unknown field %q: quote the word to search for it as text", field)
+}
+
+// isStateName accepts any plausible state name at parse time; states are
+// only known when matching, so unknown ones simply match nothing.
+func isStateName(name string) bool {
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+var hasFields = map[string]func(storage.Task) bool{
+	"due":         func(t storage.Task) bool { return t.Due.Valid },
+	"start":       func(t storage.Task) bool { return t.Start.Valid },
+	"notes":       func(t storage.Task) bool { return strings.TrimSpace(t.Notes) != "" },
+	"tags":        func(t storage.Task) bool { return strings.Trim(t.Tags, ", ") != "" },
+	"tag":         func(t storage.Task) bool { return strings.Trim(t.Tags, ", ") != "" },
+	"topic":       func(t storage.Task) bool { return len(t.Topics) > 0 },
+	"topics":      func(t storage.Task) bool { return len(t.Topics) > 0 },
+	"estimate":    func(t storage.Task) bool { return t.EstimateMinutes > 0 },
+	"reminders":   func(t storage.Task) bool { return strings.TrimSpace(t.Reminders) != "" },
+	"attachments": func(t storage.Task) bool { return len(t.Attachments) > 0 },
+}
+
+func isMatch(t storage.Task, env Env, what string) bool {
+	switch what {
+	case "open":
+		return !t.Done
+	case "closed":
+		return t.Done
+	case "done":
+		st, _ := config.ResolveState(env.States, t.Status, t.Done)
+		return t.Done && (st.Done || len(env.States) == 0)
+	case "overdue":
+		return !t.Done && t.Due.Valid && env.Now.After(t.Due.Time)
+	case "recurring":
+		return t.Recurring
+	case "snoozed":
+		return t.SnoozedUntil.Valid && env.Now.Before(t.SnoozedUntil.Time)
+	}
+	st, _ := config.ResolveState(env.States, t.Status, t.Done)
+	return st.Name == what
+}
+
+func withNot(op string, m matcher) matcher {
+	if op == "!=" {
+		return negate(m)
+	}
+	return m
+}
+
+// matchesText is the bare-word search: title, topics, tags and the due date.
+func matchesText(t storage.Task, word string) bool {
+	fields := []string{t.Title, strings.Join(t.Topics, " "), t.Tags}
+	if t.Due.Valid {
+		layout := "2006-01-02 15:04"
+		if h, m, s := t.Due.Time.Clock(); h == 0 && m == 0 && s == 0 {
+			layout = "2006-01-02"
+		}
+		fields = append(fields, t.Due.Time.Format(layout))
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), word) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesName(have, want string) bool {
+	if prefix, ok := strings.CutSuffix(want, "*"); ok {
+		return strings.HasPrefix(have, prefix)
+	}
+	return have == want
+}
+
+func compare(a int, op string, b int) bool {
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case "!=":
+		return a != b
+	}
+	return a == b
+}
+
+func compareTime(a time.Time, op string, b time.Time) bool {
+	switch {
+	case a.Before(b):
+		return compare(0, op, 1)
+	case a.After(b):
+		return compare(1, op, 0)
+	}
+	return compare(0, op, 0)
+}
+
+// parseDay resolves a date value to the start of that day in now's zone.
+func parseDay(v string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch v {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	if d, err := time.ParseInLocation("2006-01-02", v, now.Location()); err == nil {
+		return d, nil
+	}
+	if len(v) >= 3 && (v[0] == '+' || v[0] == '-') {
+		n, err := strconv.Atoi(v[1 : len(v)-1])
+		if err == nil {
+			if v[0] == '-' {
+				n = -n
+			}
+			switch v[len(v)-1] {
+			case 'd':
+				return today.AddDate(0, 0, n), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*n), nil
+			case 'm':
+				return today.AddDate(0, n, 0), nil
+			case 'y':
+				return today.AddDate(n, 0, 0), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date %q: use today, tomorrow, YYYY-MM-DD or +3d/-2w/+1m", v)
+}
+
+func parseMinutes(v string) (int, error) {
+	if n, err := strconv.Atoi(v); err == nil {
+		return n, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, err
+	}
+	return int(d.Minutes()), nil
+}
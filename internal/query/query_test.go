@@ -0,0 +1,132 @@
+package query
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"bada/internal/storage"
+)
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	day := func(s string) sql.NullTime {
+		d, _ := time.Parse("2006-01-02", s)
+		return sql.NullTime{Time: d, Valid: true}
+	}
+	tasks := []storage.Task{
+		{ID: 1, Title: "Write report", Tags: "urgent,work", Topics: []string{"work"}, Priority: 3, Due: day("2026-03-10")},
+		{ID: 2, Title: "Pay invoice", Notes: "see https://bank.example/invoice", Tags: "home", Priority: 1, Due: day("2026-03-20"), EstimateMinutes: 90},
+		{ID: 3, Title: "Call mum at 10:30", Topics: []string{"family"}, Priority: 0, Done: true},
+		{ID: 4, Title: "Plan trip", Tags: "someday", Priority: 2, Due: day("2026-03-01")},
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "1 2 3 4"},
+		{"report", "1"},
+		{"10:30", "3"},
+		{"https://bank.example", ""},
+		{"notes:https://bank.example", "2"},
+		{`"tga:urgent"`, ""},
+
+		// Precedence: AND binds tighter than OR, parentheses override it.
+		{"report OR invoice trip", "1"},
+		{"report OR invoice OR trip", "1 2 4"},
+		{"(report OR invoice) prio>=1", "1 2"},
+		{"report invoice", ""},
+		{"report AND prio:3", "1"},
+
+		// Negation.
+		{"-tag:urgent", "2 3 4"},
+		{"NOT tag:urgent", "2 3 4"},
+		{"not (report OR invoice)", "3 4"},
+		{"NOT (is:done OR tag:someday) -due:today", "2"},
+		{"NOT NOT report", "1"},
+
+		// Field operators.
+		{"prio>=2", "1 4"},
+		{"prio>2", "1"},
+		{"prio<=1", "2 3"},
+		{"prio<1", "3"},
+		{"prio!=1", "1 3 4"},
+		{"p=1", "2"},
+		{"id>2", "3 4"},
+		{"est>=1h", "2"},
+		{"est<90", "1 3 4"},
+		{"title:REPORT", "1"},
+		{"title!=report", "2 3 4"},
+		{"tag:work", "1"},
+		{"tag:w*", "1"},
+		{"tag!=work", "2 3 4"},
+		{"topic:family", "3"},
+		{"due:today", "1"},
+		{"due<today", "4"},
+		{"due<=+10d", "1 2 4"},
+		{"due>2026-03-10", "2"},
+		{"due:none", "3"},
+		{"due!=none", "1 2 4"},
+		{"is:open", "1 2 4"},
+		{"is:overdue", "1 4"},
+		{"has:notes", "2"},
+		{"has!=due", "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.query, err)
+			}
+			var got []string
+			for _, task := range tasks {
+				if q.Match(task, Env{Now: now}) {
+					got = append(got, strconv.Itoa(task.ID))
+				}
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("got %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		len   int
+		msg   string
+	}{
+		{"tga:urgent", 0, 10, `unknown field "tga"`},
+		{"report -tga:urgent", 8, 10, `unknown field "tga"`},
+		{"foo=bar", 0, 7, `unknown field "foo"`},
+		{"tga:", 0, 4, `unknown field "tga"`},
+		{"prio>=", 0, 6, "prio>= needs a value"},
+		{"prio>=high", 0, 10, `prio needs a number, got "high"`},
+		{"title>x", 0, 7, "title only supports : and !="},
+		{"a due:someday", 2, 11, "due: unknown date"},
+		{"is:later!", 0, 9, "unknown is:later!"},
+		{"has:colour", 0, 10, "unknown has:colour"},
+		{`title:"half`, 6, 5, "unterminated quote"},
+		{"(report", 0, 1, "unclosed parenthesis"},
+		{"report)", 6, 1, `unexpected ")"`},
+		{"report OR", 9, 1, "missing term at end of query"},
+		{"OR report", 0, 2, `expected a term before "OR"`},
+		{"report NOT", 7, 3, "NOT needs a term after it"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			perr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("Parse(%q) = %v, want an *Error", tt.query, err)
+			}
+			if perr.Pos != tt.pos || perr.Len != tt.len || !strings.HasPrefix(perr.Msg, tt.msg) {
+				t.Errorf("got %q at %d+%d, want %q at %d+%d", perr.Msg, perr.Pos, perr.Len, tt.msg, tt.pos, tt.len)
+			}
+		})
+	}
+}
@@ -0,0 +1,499 @@
+package recur
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse reads a rule written as a raw RRULE ("RRULE:FREQ=WEEKLY;BYDAY=MO,WE"
+// or without the prefix, optionally followed by an EXDATE line) or in words
+// ("every Mon, Wed", "every month on the last day", "every 2nd Tue",
+// "yearly on Mar 15", "weekdays", "every Fri until 2026-12-31", "daily, 10
+// times").
+func Parse(input string) (Rule, error) {
+	var src string
+	var exdates []time.Time
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		upper := strings.ToUpper(line)
+		if strings.HasPrefix(upper, "EXDATE") {
+			_, value, _ := strings.Cut(upper, ":")
+			days, err := parseDates(strings.Split(value, ","), "20060102")
+			if err != nil {
+				return Rule{}, fmt.Errorf("bad EXDATE: %v", err)
+			}
+			exdates = append(exdates, days...)
+			continue
+		}
+		if line != "" && src == "" {
+			src = line
+		}
+	}
+	if src == "" {
+		return Rule{}, errors.New("empty rule")
+	}
+	var r Rule
+	var err error
+	upper := strings.ToUpper(src)
+	if strings.HasPrefix(upper, "RRULE:") || strings.Contains(upper, "FREQ=") {
+		r, err = parseRRule(strings.TrimPrefix(upper, "RRULE:"))
+	} else {
+		r, err = parseWords(strings.ToLower(src))
+	}
+	if err != nil || len(exdates) == 0 {
+		return r, err
+	}
+	r.Exdates = append(r.Exdates, exdates...)
+	return r.normalize()
+}
+
+// ParseEnd reads when a series stops: a date ("2026-12-31", "until
+// 2026-12-31") or a count ("5", "5 times"). Empty or "never" is no end.
+func ParseEnd(input string) (until time.Time, count int, err error) {
+	src := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	switch src {
+	case "", "never", "none":
+		return time.Time{}, 0, nil
+	}
+	if m := endRe.FindStringSubmatch(" " + src); m != nil && m[0] == " "+src {
+		return readEnd(m)
+	}
+	if n, err := strconv.Atoi(src); err == nil && n > 0 {
+		return time.Time{}, n, nil
+	}
+	if d, err := time.Parse("2006-01-02", src); err == nil {
+		return d, 0, nil
+	}
+	return time.Time{}, 0, fmt.Errorf("cannot read %q: use a date like 2026-12-31 or a count like 5 times", input)
+}
+
+// ParseExdates reads a list of days to skip, "2026-12-24, 2026-12-31".
+func ParseExdates(input string) ([]time.Time, error) {
+	days, err := parseDates(strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' || r == ';' }), "2006-01-02")
+	if err != nil {
+		return nil, err
+	}
+	return sortDates(days), nil
+}
+
+func parseDates(values []string, layout string) ([]time.Time, error) {
+	var days []time.Time
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if len(v) > len(layout) && layout == "20060102" {
+			v = v[:8]
+		}
+		d, err := time.Parse(layout, v)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a date", v)
+		}
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+func parseRRule(src string) (Rule, error) {
+	var r Rule
+	seenFreq := false
+	for _, part := range strings.Split(strings.Trim(src, "; "), ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("bad RRULE part %q", part)
+		}
+		var err error
+		switch key {
+		case "FREQ":
+			seenFreq = true
+			idx := indexOf(freqNames[:], value)
+			if idx < 0 {
+				return Rule{}, fmt.Errorf("unsupported FREQ %q: use DAILY, WEEKLY, MONTHLY or YEARLY", value)
+			}
+			r.Freq = Freq(idx)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = errors.New("must be at least 1")
+			}
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				d, ok := parseDayCode(code)
+				if !ok {
+					return Rule{}, fmt.Errorf("bad BYDAY %q", code)
+				}
+				r.ByDay = append(r.ByDay, d)
+			}
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(value, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseInts(value, 12)
+			for _, m := range months {
+				if m < 1 {
+					err = fmt.Errorf("month %d out of range", m)
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "BYSETPOS":
+			r.BySetPos, err = parseInts(value, 366)
+		case "UNTIL":
+			var days []time.Time
+			days, err = parseDates([]string{value}, "20060102")
+			if err == nil {
+				r.Until = days[0]
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err == nil && r.Count < 1 {
+				err = errors.New("must be at least 1")
+			}
+		case "WKST":
+			// Weeks always start on Monday.
+		default:
+			return Rule{}, fmt.Errorf("unsupported RRULE part %s", key)
+		}
+		if err != nil {
+			return Rule{}, fmt.Errorf("bad %s: %v", key, err)
+		}
+	}
+	if !seenFreq {
+		return Rule{}, errors.New("RRULE needs FREQ")
+	}
+	return r.normalize()
+}
+
+var dayCodeRe = regexp.MustCompile(`^([+-]?\d{1,2})?(MO|TU|WE|TH|FR|SA|SU)$`)
+
+func parseDayCode(code string) (Day, bool) {
+	m := dayCodeRe.FindStringSubmatch(strings.TrimSpace(code))
+	if m == nil {
+		return Day{}, false
+	}
+	d := Day{Weekday: weekdayFromCode(m[2])}
+	if m[1] != "" {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n == 0 || n > 53 || n < -53 {
+			return Day{}, false
+		}
+		d.N = n
+	}
+	return d, true
+}
+
+func weekdayFromCode(code string) time.Weekday {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if dayCode(wd) == code {
+			return wd
+		}
+	}
+	return time.Sunday
+}
+
+func parseInts(value string, limit int) ([]int, error) {
+	var out []int
+	for _, s := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", s)
+		}
+		if n == 0 || n > limit || n < -limit {
+			return nil, fmt.Errorf("%d out of range", n)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+var headRe = regexp.MustCompile(`^(?:every\s+(?:(\d+|other)\s+)?(day|week|month|year)s?|(daily|weekly|monthly|yearly|annually))\b\s*(.*)$`)
+
+// endRe matches an end written after the pattern: "until 2026-12-31",
+// ", 5 times", "for 5 occurrences".
+var endRe = regexp.MustCompile(`(?:,\s*|\s+)(?:(?:until|till|through|ending)\s+(\d{4}-\d{2}-\d{2})|(?:for\s+)?(\d+)\s+(?:times?|occurrences?))$`)
+
+func readEnd(m []string) (time.Time, int, error) {
+	if m[1] != "" {
+		d, err := time.Parse("2006-01-02", m[1])
+		if err != nil {
+			return time.Time{}, 0, fmt.Errorf("%q is not a date", m[1])
+		}
+		return d, 0, nil
+	}
+	n, _ := strconv.Atoi(m[2])
+	if n < 1 {
+		return time.Time{}, 0, errors.New("a count must be at least 1")
+	}
+	return time.Time{}, n, nil
+}
+
+var aliases = map[string]string{
+	"weekdays":      "every week on mon, tue, wed, thu, fri",
+	"workdays":      "every week on mon, tue, wed, thu, fri",
+	"every weekday": "every week on mon, tue, wed, thu, fri",
+	"every workday": "every week on mon, tue, wed, thu, fri",
+	"weekends":      "every week on sat, sun",
+	"every weekend": "every week on sat, sun",
+}
+
+// parseWords reads the plain-words form: an optional "every [N] unit" head
+// followed by days, ordinals and months in any order.
+func parseWords(src string) (Rule, error) {
+	src = strings.Join(strings.Fields(src), " ")
+	var r Rule
+	for m := endRe.FindStringSubmatch(src); m != nil; m = endRe.FindStringSubmatch(src) {
+		until, count, err := readEnd(m)
+		if err != nil {
+			return Rule{}, err
+		}
+		if !until.IsZero() {
+			r.Until = until
+		} else {
+			r.Count = count
+		}
+		src = strings.TrimSuffix(src, m[0])
+	}
+	if alias, ok := aliases[src]; ok {
+		src = alias
+	}
+	freqSet := false
+	rest := src
+	if m := headRe.FindStringSubmatch(src); m != nil {
+		freqSet = true
+		r.Interval = 1
+		switch {
+		case m[1] == "other":
+			r.Interval = 2
+		case m[1] != "":
+			r.Interval, _ = strconv.Atoi(m[1])
+		}
+		unit := m[2]
+		if unit == "" {
+			unit = map[string]string{"daily": "day", "weekly": "week", "monthly": "month", "yearly": "year", "annually": "year"}[m[3]]
+		}
+		r.Freq = Freq(indexOf(freqUnits[:], unit))
+		rest = m[4]
+	}
+	ws := wordSet{}
+	if err := ws.read(rest); err != nil {
+		return Rule{}, err
+	}
+	if !freqSet {
+		switch {
+		case len(ws.months) > 0:
+			r.Freq = Yearly
+		case len(ws.monthDays) > 0 || len(ws.setPos) > 0 || hasOrdinals(ws.days):
+			r.Freq = Monthly
+		case len(ws.days) > 0:
+			r.Freq = Weekly
+		default:
+			return Rule{}, fmt.Errorf("cannot read %q: try \"every 2 weeks on Mon\", \"every month on the 15th\" or an RRULE", src)
+		}
+	}
+	r.ByDay, r.ByMonthDay, r.ByMonth, r.BySetPos = ws.days, ws.monthDays, ws.months, ws.setPos
+	// "every month on Fri" has always meant the first Friday.
+	if r.Freq == Monthly && !ws.everyDay && len(r.ByDay) == 1 && r.ByDay[0].N == 0 && len(r.ByMonthDay) == 0 && len(r.BySetPos) == 0 {
+		r.ByDay[0].N = 1
+	}
+	return r.normalize()
+}
+
+type wordSet struct {
+	days      []Day
+	monthDays []int
+	months    []time.Month
+	setPos    []int
+	everyDay  bool
+}
+
+var fillers = map[string]bool{"on": true, "the": true, "and": true, "of": true, "in": true, "each": true, "if": true, "at": true,
+	"month": true, "months": true, "year": true, "years": true}
+
+var workdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+func (ws *wordSet) read(src string) error {
+	words := strings.Fields(strings.NewReplacer(",", " ", "/", " ", "&", " ", "-", " ").Replace(src))
+	var pending []int
+	flush := func() {
+		ws.monthDays = append(ws.monthDays, pending...)
+		pending = nil
+	}
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if n, ok := parseOrdinal(w); ok {
+			if i+2 < len(words) && words[i+1] == "to" && words[i+2] == "last" {
+				n, i = -n, i+2
+			}
+			pending = append(pending, n)
+			continue
+		}
+		if n, err := strconv.Atoi(w); err == nil {
+			pending = append(pending, n)
+			continue
+		}
+		if wd, ok := parseWeekday(w); ok {
+			if len(pending) == 0 {
+				ws.days = append(ws.days, Day{Weekday: wd})
+			}
+			for _, n := range pending {
+				ws.days = append(ws.days, Day{Weekday: wd, N: n})
+			}
+			pending = nil
+			continue
+		}
+		if m, ok := parseMonth(w); ok {
+			flush()
+			ws.months = append(ws.months, m)
+			continue
+		}
+		switch w {
+		case "day", "days":
+			flush()
+		case "weekday", "weekdays", "workday", "workdays", "weekend", "weekends":
+			group := workdays
+			if strings.HasPrefix(w, "weekend") {
+				group = []time.Weekday{time.Saturday, time.Sunday}
+				if i+1 < len(words) && words[i+1] == "day" {
+					i++
+				}
+			}
+			for _, wd := range group {
+				ws.days = append(ws.days, Day{Weekday: wd})
+			}
+			ws.setPos = append(ws.setPos, pending...)
+			pending = nil
+		case "every":
+			ws.everyDay = true
+		default:
+			if !fillers[w] {
+				return fmt.Errorf("unknown word %q", w)
+			}
+		}
+	}
+	flush()
+	return nil
+}
+
+var ordinalWords = map[string]int{"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1}
+var ordinalRe = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)$`)
+
+func parseOrdinal(w string) (int, bool) {
+	if n, ok := ordinalWords[w]; ok {
+		return n, true
+	}
+	if m := ordinalRe.FindStringSubmatch(w); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n, n > 0
+	}
+	return 0, false
+}
+
+func parseWeekday(w string) (time.Weekday, bool) {
+	w = strings.TrimSuffix(w, "s")
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if len(w) >= 3 && strings.HasPrefix(name, w) || w == strings.ToLower(dayCode(wd)) {
+			return wd, true
+		}
+	}
+	return time.Sunday, false
+}
+
+func parseMonth(w string) (time.Month, bool) {
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if w == name || len(w) >= 3 && strings.HasPrefix(name, w) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// normalize validates the rule and puts it in its canonical shape, so equal
+// rules store the same string.
+func (r Rule) normalize() (Rule, error) {
+	r.Interval = max(1, r.Interval)
+	if !r.Until.IsZero() && r.Count > 0 {
+		return Rule{}, errors.New("use either an end date or a count, not both")
+	}
+	if (r.Freq == Daily || r.Freq == Weekly) && hasOrdinals(r.ByDay) {
+		return Rule{}, errors.New("numbered weekdays (2nd Tue) need a monthly or yearly rule")
+	}
+	for _, md := range r.ByMonthDay {
+		if md == 0 || md > 31 || md < -31 {
+			return Rule{}, fmt.Errorf("day %d out of range", md)
+		}
+	}
+	if len(r.BySetPos) > 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByMonth) == 0 {
+		return Rule{}, errors.New("a position (BYSETPOS) needs days to pick from")
+	}
+	if r.Freq == Daily && r.Interval == 1 && len(r.ByDay) > 0 && len(r.ByMonthDay) == 0 && len(r.BySetPos) == 0 {
+		r.Freq = Weekly
+	}
+	seenDay := map[Day]bool{}
+	days := r.ByDay[:0:0]
+	for _, d := range r.ByDay {
+		if !seenDay[d] {
+			seenDay[d] = true
+			days = append(days, d)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		if days[i].N != days[j].N {
+			return ordinalKey(days[i].N) < ordinalKey(days[j].N)
+		}
+		return mondayOffset(days[i].Weekday) < mondayOffset(days[j].Weekday)
+	})
+	r.ByDay = days
+	r.ByMonthDay = uniqueSorted(r.ByMonthDay, ordinalKey)
+	r.BySetPos = uniqueSorted(r.BySetPos, ordinalKey)
+	months := make([]int, len(r.ByMonth))
+	for i, m := range r.ByMonth {
+		months[i] = int(m)
+	}
+	r.ByMonth = nil
+	for _, m := range uniqueSorted(months, func(n int) int { return n }) {
+		r.ByMonth = append(r.ByMonth, time.Month(m))
+	}
+	if len(r.Exdates) > 0 {
+		r.Exdates = sortDates(append([]time.Time(nil), r.Exdates...))
+	}
+	return r, nil
+}
+
+// ordinalKey orders 1st, 2nd, ... before the negative "from the end" ones.
+func ordinalKey(n int) int {
+	if n < 0 {
+		return 1000 - n
+	}
+	return n
+}
+
+func uniqueSorted(values []int, key func(int) int) []int {
+	if len(values) == 0 {
+		return nil
+	}
+	out := append([]int(nil), values...)
+	sort.Slice(out, func(i, j int) bool { return key(out[i]) < key(out[j]) })
+	uniq := out[:1]
+	for _, v := range out[1:] {
+		if v != uniq[len(uniq)-1] {
+			uniq = append(uniq, v)
+		}
+	}
+	return uniq
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,628 @@
+// Package recur is the recurrence engine behind recurring tasks. A Rule is
+// the date part of an RFC 5545 RRULE (FREQ, INTERVAL, BYDAY, BYMONTHDAY,
+// BYMONTH, BYSETPOS, UNTIL, COUNT) plus its EXDATE list, and can be written
+// either as a raw RRULE or in plain words:
+//
+//	every 2 weeks on Mon, Wed
+//	every month on the last day
+//	every month on the 2nd Tue
+//	every year on Mar 15
+//	every weekday
+//	every Fri until 2026-12-31
+//	every month on the 1st, 6 times
+//
+// Occurrences are calendar dates in the start date's location, so DST
+// changes never move them.
+package recur
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Freq int
+
+const (
+	Daily Freq = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+var freqNames = [...]string{"DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+var freqUnits = [...]string{"day", "week", "month", "year"}
+
+// Day is a BYDAY entry. N is the ordinal inside the month (or the year):
+// 2 for the second, -1 for the last; 0 means every such weekday.
+type Day struct {
+	Weekday time.Weekday
+	N       int
+}
+
+type Rule struct {
+	Freq       Freq
+	Interval   int
+	ByDay      []Day
+	ByMonthDay []int
+	ByMonth    []time.Month
+	BySetPos   []int
+	// Until is the last day an occurrence may fall on; Count is how many
+	// occurrences are left, counting from the start date. Zero means the
+	// series never ends.
+	Until time.Time
+	Count int
+	// Exdates are skipped days; like in RFC 5545 they still use up a count.
+	Exdates []time.Time
+}
+
+// periodLimit bounds the search for the next occurrence; a rule like "Feb
+// 29 every year" needs a few periods, one that can never match needs them
+// all.
+const periodLimit = 4000
+
+// Next returns the first occurrence on a day after after, counting from
+// start (which is the first candidate day, whether or not it matches).
+func (r Rule) Next(start, after time.Time) (time.Time, bool) {
+	next, _, ok := r.next(start, after)
+	return next, ok
+}
+
+// Advance is Next for a series that moves on to its next occurrence: the
+// returned rule has the occurrences before it taken off its count and the
+// skipped days before it dropped, so it continues from there.
+func (r Rule) Advance(start, after time.Time) (Rule, time.Time, bool) {
+	next, used, ok := r.next(start, after)
+	if !ok {
+		return r, time.Time{}, false
+	}
+	if r.Count > 0 {
+		r.Count -= used
+	}
+	var exdates []time.Time
+	for _, d := range r.Exdates {
+		if dayKey(d) > dayKey(next) {
+			exdates = append(exdates, d)
+		}
+	}
+	r.Exdates = exdates
+	return r, next, true
+}
+
+// next also reports how many occurrences from start came before the one
+// returned, which is what a count is measured in.
+func (r Rule) next(start, after time.Time) (time.Time, int, bool) {
+	start = dateOf(start)
+	after = dateOf(after.In(start.Location()))
+	first := 0
+	if r.Count == 0 {
+		first = r.firstPeriod(start, after)
+	}
+	used := 0
+	if r.Count > 0 && !r.matches(start) {
+		// As in RFC 5545, the start date is the first occurrence either way.
+		used++
+	}
+	for k := first; k < first+periodLimit; k++ {
+		for _, c := range r.period(start, k) {
+			if c.Before(start) {
+				continue
+			}
+			if !r.Until.IsZero() && dayKey(c) > dayKey(r.Until) || r.Count > 0 && used >= r.Count {
+				return time.Time{}, used, false
+			}
+			if c.After(after) && !r.skipped(c) {
+				return c, used, true
+			}
+			used++
+		}
+	}
+	return time.Time{}, used, false
+}
+
+func (r Rule) matches(start time.Time) bool {
+	for _, c := range r.period(start, 0) {
+		if c.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) skipped(d time.Time) bool {
+	for _, ex := range r.Exdates {
+		if dayKey(ex) == dayKey(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasEnd reports whether the series stops, by date or by count.
+func (r Rule) HasEnd() bool {
+	return !r.Until.IsZero() || r.Count > 0
+}
+
+// Pattern is the rule without its end and skipped days.
+func (r Rule) Pattern() Rule {
+	r.Until, r.Count, r.Exdates = time.Time{}, 0, nil
+	return r
+}
+
+// Upcoming lists up to n occurrences after after.
+func (r Rule) Upcoming(start, after time.Time, n int) []time.Time {
+	var out []time.Time
+	for len(out) < n {
+		next, ok := r.Next(start, after)
+		if !ok {
+			break
+		}
+		out = append(out, next)
+		after = next
+	}
+	return out
+}
+
+func (r Rule) interval() int {
+	return max(1, r.Interval)
+}
+
+// firstPeriod skips the periods that end before after, leaving one spare.
+func (r Rule) firstPeriod(start, after time.Time) int {
+	var k int
+	switch r.Freq {
+	case Daily:
+		k = daysBetween(start, after) / r.interval()
+	case Weekly:
+		k = daysBetween(weekStart(start), after) / 7 / r.interval()
+	case Monthly:
+		k = ((after.Year()-start.Year())*12 + int(after.Month()-start.Month())) / r.interval()
+	case Yearly:
+		k = (after.Year() - start.Year()) / r.interval()
+	}
+	return max(0, k-1)
+}
+
+// period expands the k-th period (day, week, month or year) into its
+// candidate dates, in order.
+func (r Rule) period(start time.Time, k int) []time.Time {
+	loc := start.Location()
+	step := k * r.interval()
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		d := start.AddDate(0, 0, step)
+		if r.keepDay(d) {
+			days = append(days, d)
+		}
+	case Weekly:
+		week := weekStart(start).AddDate(0, 0, 7*step)
+		if len(r.ByDay) == 0 {
+			days = append(days, week.AddDate(0, 0, mondayOffset(start.Weekday())))
+		}
+		for _, wd := range r.ByDay {
+			days = append(days, week.AddDate(0, 0, mondayOffset(wd.Weekday)))
+		}
+		days = r.filterMonths(days)
+	case Monthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+		if r.monthAllowed(first.Month()) {
+			days = r.monthDays(first, start.Day())
+		}
+	case Yearly:
+		year := start.Year() + step
+		if len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) > 0 {
+			days = r.yearDays(year, loc)
+			break
+		}
+		months := r.ByMonth
+		switch {
+		case len(months) == 0 && len(r.ByMonthDay) > 0:
+			// BYMONTHDAY alone picks those days in every month of the year.
+			for m := time.January; m <= time.December; m++ {
+				months = append(months, m)
+			}
+		case len(months) == 0:
+			months = []time.Month{start.Month()}
+		}
+		for _, month := range months {
+			days = append(days, r.monthDays(time.Date(year, month, 1, 0, 0, 0, 0, loc), start.Day())...)
+		}
+	}
+	return r.setPos(sortDates(days))
+}
+
+func (r Rule) keepDay(d time.Time) bool {
+	if !r.monthAllowed(d.Month()) {
+		return false
+	}
+	if len(r.ByDay) > 0 && !r.hasWeekday(d.Weekday()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 {
+		last := daysIn(d)
+		for _, md := range r.ByMonthDay {
+			if md == d.Day() || md < 0 && last+md+1 == d.Day() {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (r Rule) filterMonths(days []time.Time) []time.Time {
+	out := days[:0]
+	for _, d := range days {
+		if r.monthAllowed(d.Month()) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func (r Rule) monthAllowed(m time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, bm := range r.ByMonth {
+		if bm == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) hasWeekday(wd time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d.Weekday == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDays expands one month. Without BYMONTHDAY or BYDAY the start
+// date's day is used, clamped to shorter months (the 31st becomes the 30th
+// or Feb 28/29); an explicit BYMONTHDAY that does not exist is skipped.
+func (r Rule) monthDays(first time.Time, startDay int) []time.Time {
+	last := daysIn(first)
+	var days []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, md := range r.ByMonthDay {
+			day := md
+			if md < 0 {
+				day = last + md + 1
+			}
+			if day < 1 || day > last {
+				continue
+			}
+			d := first.AddDate(0, 0, day-1)
+			if len(r.ByDay) == 0 || r.hasWeekday(d.Weekday()) {
+				days = append(days, d)
+			}
+		}
+	case len(r.ByDay) > 0:
+		for _, wd := range r.ByDay {
+			days = append(days, nthWeekday(first, first.AddDate(0, 0, last-1), wd)...)
+		}
+	default:
+		days = append(days, first.AddDate(0, 0, min(startDay, last)-1))
+	}
+	return days
+}
+
+// yearDays expands BYDAY over a whole year: 20MO is the year's 20th Monday.
+func (r Rule) yearDays(year int, loc *time.Location) []time.Time {
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	last := time.Date(year, time.December, 31, 0, 0, 0, 0, loc)
+	var days []time.Time
+	for _, wd := range r.ByDay {
+		days = append(days, nthWeekday(first, last, wd)...)
+	}
+	return days
+}
+
+// nthWeekday lists the days between first and last (inclusive) matching
+// wd: all of them, or only the wd.N-th from the start (or end if negative).
+func nthWeekday(first, last time.Time, wd Day) []time.Time {
+	d := first.AddDate(0, 0, (int(wd.Weekday)-int(first.Weekday())+7)%7)
+	var all []time.Time
+	for !d.After(last) {
+		all = append(all, d)
+		d = d.AddDate(0, 0, 7)
+	}
+	switch {
+	case wd.N == 0:
+		return all
+	case wd.N > 0 && wd.N <= len(all):
+		return all[wd.N-1 : wd.N]
+	case wd.N < 0 && -wd.N <= len(all):
+		return all[len(all)+wd.N : len(all)+wd.N+1]
+	}
+	return nil
+}
+
+func (r Rule) setPos(days []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 {
+		return days
+	}
+	var out []time.Time
+	for _, pos := range r.BySetPos {
+		switch {
+		case pos > 0 && pos <= len(days):
+			out = append(out, days[pos-1])
+		case pos < 0 && -pos <= len(days):
+			out = append(out, days[len(days)+pos])
+		}
+	}
+	return sortDates(out)
+}
+
+func sortDates(days []time.Time) []time.Time {
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	out := days[:0]
+	for i, d := range days {
+		if i == 0 || !d.Equal(days[i-1]) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// dayKey compares calendar days across locations.
+func dayKey(t time.Time) int {
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween counts calendar days, ignoring DST-shortened days.
+func daysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func weekStart(t time.Time) time.Time {
+	return t.AddDate(0, 0, -mondayOffset(t.Weekday()))
+}
+
+func mondayOffset(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
+
+// String is the normalized RRULE form that gets stored, followed by an
+// EXDATE line when days are skipped.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + freqNames[r.Freq]}
+	if r.interval() > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.interval()))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = dayCode(d.Weekday)
+			if d.N != 0 {
+				days[i] = strconv.Itoa(d.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = int(m)
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	out := "RRULE:" + strings.Join(parts, ";")
+	if len(r.Exdates) > 0 {
+		days := make([]string, len(r.Exdates))
+		for i, d := range r.Exdates {
+			days[i] = d.Format("20060102")
+		}
+		out += "\nEXDATE;VALUE=DATE:" + strings.Join(days, ",")
+	}
+	return out
+}
+
+// EndText is the end as the editor shows it: "2026-12-31", "3 times" or
+// empty. ParseEnd reads it back.
+func (r Rule) EndText() string {
+	switch {
+	case !r.Until.IsZero():
+		return r.Until.Format("2006-01-02")
+	case r.Count == 1:
+		return "1 time"
+	case r.Count > 1:
+		return fmt.Sprintf("%d times", r.Count)
+	}
+	return ""
+}
+
+// ExdatesText lists the skipped days as "2026-12-24, 2026-12-31".
+func (r Rule) ExdatesText() string {
+	days := make([]string, len(r.Exdates))
+	for i, d := range r.Exdates {
+		days[i] = d.Format("2006-01-02")
+	}
+	return strings.Join(days, ", ")
+}
+
+func joinInts(values []int) string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strconv.Itoa(v)
+	}
+	return strings.Join(out, ",")
+}
+
+// Label is the rule's pattern in words, leaving out the end and skipped
+// days. Labels of rules that plain words can express parse back to the same
+// pattern.
+func (r Rule) Label() string {
+	unit := freqUnits[r.Freq]
+	head := "every " + unit
+	if r.interval() > 1 {
+		head = fmt.Sprintf("every %d %ss", r.interval(), unit)
+	}
+	plainDays := len(r.ByDay) > 0 && !hasOrdinals(r.ByDay)
+	if r.Freq == Weekly && r.interval() == 1 && plainDays && len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 && len(r.BySetPos) == 0 {
+		switch dayGroup(r.ByDay) {
+		case "workday":
+			return "every weekday"
+		case "weekend day":
+			return "every weekend"
+		}
+	}
+	if r.Freq == Yearly && len(r.ByMonth) == 1 && len(r.ByMonthDay) == 1 && r.ByMonthDay[0] > 0 && len(r.ByDay) == 0 && len(r.BySetPos) == 0 {
+		return fmt.Sprintf("%s on %s %d", head, monthShort(r.ByMonth[0]), r.ByMonthDay[0])
+	}
+	var on []string
+	switch {
+	case len(r.BySetPos) > 0 && plainDays:
+		pos := make([]string, len(r.BySetPos))
+		for i, p := range r.BySetPos {
+			pos[i] = ordinalLabel(p)
+		}
+		group := dayGroup(r.ByDay)
+		switch {
+		case group != "":
+		case len(r.ByDay) == 1:
+			group = r.ByDay[0].Weekday.String()
+		default:
+			group = "of " + dayList(r.ByDay)
+		}
+		on = append(on, "the "+strings.Join(pos, ", ")+" "+group)
+	case len(r.ByMonthDay) > 0:
+		days := make([]string, len(r.ByMonthDay))
+		for i, md := range r.ByMonthDay {
+			days[i] = ordinalLabel(md)
+		}
+		on = append(on, "the "+strings.Join(days, ", "))
+		if len(r.ByMonthDay) == 1 && r.ByMonthDay[0] < 0 {
+			on[0] += " day"
+		}
+		if plainDays {
+			on = append(on, "if "+dayList(r.ByDay))
+		}
+	case len(r.ByDay) > 0 && (r.Freq == Monthly || r.Freq == Yearly):
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = dayShort(d.Weekday)
+			if d.N != 0 {
+				days[i] = ordinalLabel(d.N) + " " + days[i]
+			} else {
+				days[i] = "every " + days[i]
+			}
+		}
+		on = append(on, "the "+strings.Join(days, ", "))
+		if plainDays {
+			on = []string{strings.Join(days, ", ")}
+		}
+	case len(r.ByDay) > 0:
+		on = append(on, dayList(r.ByDay))
+	}
+	label := head
+	if len(on) > 0 {
+		label += " on " + strings.Join(on, " ")
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = monthShort(m)
+		}
+		label += " in " + strings.Join(months, ", ")
+	}
+	return label
+}
+
+func hasOrdinals(days []Day) bool {
+	for _, d := range days {
+		if d.N != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func dayGroup(days []Day) string {
+	switch weekdayMask(days) {
+	case 0b0111110:
+		return "workday"
+	case 0b1000001:
+		return "weekend day"
+	}
+	return ""
+}
+
+func weekdayMask(days []Day) int {
+	mask := 0
+	for _, d := range days {
+		mask |= 1 << d.Weekday
+	}
+	return mask
+}
+
+func dayList(days []Day) string {
+	names := make([]string, len(days))
+	for i, d := range days {
+		names[i] = dayShort(d.Weekday)
+	}
+	return strings.Join(names, ", ")
+}
+
+func ordinalLabel(n int) string {
+	switch {
+	case n == -1:
+		return "last"
+	case n < -1:
+		return ordinalLabel(-n) + " to last"
+	}
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func dayShort(wd time.Weekday) string {
+	return wd.String()[:3]
+}
+
+func dayCode(wd time.Weekday) string {
+	return strings.ToUpper(wd.String()[:2])
+}
+
+func monthShort(m time.Month) string {
+	return m.String()[:3]
+}
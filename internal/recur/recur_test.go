@@ -0,0 +1,227 @@
+package recur
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpcoming(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		zone  string
+		n     int
+		want  string
+	}{
+		{
+			name:  "bymonthday 31 skips short months",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			start: "2026-01-31",
+			n:     4,
+			want:  "2026-01-31 2026-03-31 2026-05-31 2026-07-31",
+		},
+		{
+			name:  "bymonthday -1 is the last day of each month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: "2026-01-31",
+			n:     4,
+			want:  "2026-01-31 2026-02-28 2026-03-31 2026-04-30",
+		},
+		{
+			name:  "bymonthday -1 in a leap year",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: "2028-01-31",
+			n:     2,
+			want:  "2028-01-31 2028-02-29",
+		},
+		{
+			name:  "monthly without bymonthday clamps the start day",
+			rule:  "FREQ=MONTHLY",
+			start: "2026-01-31",
+			n:     4,
+			want:  "2026-01-31 2026-02-28 2026-03-31 2026-04-30",
+		},
+		{
+			name:  "feb 29 yearly waits for leap years",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+			start: "2024-02-29",
+			n:     3,
+			want:  "2024-02-29 2028-02-29 2032-02-29",
+		},
+		{
+			name:  "yearly from feb 29 clamps to feb 28",
+			rule:  "FREQ=YEARLY",
+			start: "2024-02-29",
+			n:     5,
+			want:  "2024-02-29 2025-02-28 2026-02-28 2027-02-28 2028-02-29",
+		},
+		{
+			name:  "yearly bymonthday without bymonth covers every month",
+			rule:  "FREQ=YEARLY;BYMONTHDAY=1",
+			start: "2026-01-01",
+			n:     4,
+			want:  "2026-01-01 2026-02-01 2026-03-01 2026-04-01",
+		},
+		{
+			name:  "weekly across the spring dst change",
+			rule:  "FREQ=WEEKLY;BYDAY=SU",
+			start: "2026-03-01",
+			zone:  "America/New_York",
+			n:     3,
+			want:  "2026-03-01 2026-03-08 2026-03-15",
+		},
+		{
+			name:  "weekly across the autumn dst change",
+			rule:  "FREQ=WEEKLY;BYDAY=SU,MO",
+			start: "2026-10-18",
+			zone:  "Europe/Berlin",
+			n:     4,
+			want:  "2026-10-18 2026-10-19 2026-10-25 2026-10-26",
+		},
+		{
+			name:  "count stops the series",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: "2026-01-01",
+			n:     5,
+			want:  "2026-01-01 2026-01-02 2026-01-03",
+		},
+		{
+			name:  "an unmatched start still uses up the count",
+			rule:  "FREQ=WEEKLY;BYDAY=MO;COUNT=3",
+			start: "2026-01-01",
+			n:     5,
+			want:  "2026-01-05 2026-01-12",
+		},
+		{
+			name:  "until is inclusive",
+			rule:  "FREQ=WEEKLY;BYDAY=FR;UNTIL=20260123",
+			start: "2026-01-02",
+			n:     6,
+			want:  "2026-01-02 2026-01-09 2026-01-16 2026-01-23",
+		},
+		{
+			name:  "exdate skips a day",
+			rule:  "FREQ=WEEKLY;BYDAY=MO\nEXDATE:20260112,20260119",
+			start: "2026-01-05",
+			n:     3,
+			want:  "2026-01-05 2026-01-26 2026-02-02",
+		},
+		{
+			name:  "exdate still uses up the count",
+			rule:  "FREQ=DAILY;COUNT=4\nEXDATE:20260102",
+			start: "2026-01-01",
+			n:     5,
+			want:  "2026-01-01 2026-01-03 2026-01-04",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			loc := time.UTC
+			if tt.zone != "" {
+				if loc, err = time.LoadLocation(tt.zone); err != nil {
+					t.Skipf("no zone %s: %v", tt.zone, err)
+				}
+			}
+			start, err := time.ParseInLocation("2006-01-02", tt.start, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range r.Upcoming(start, start.AddDate(0, 0, -1), tt.n) {
+				if d.Location() != loc {
+					t.Errorf("%s is in %s, want %s", d, d.Location(), loc)
+				}
+				if h, m, _ := d.Clock(); h != 0 || m != 0 {
+					t.Errorf("%s is not at midnight", d)
+				}
+				got = append(got, d.Format("2006-01-02"))
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("got  %s\nwant %s", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWords(t *testing.T) {
+	tests := []struct {
+		words string
+		want  string
+	}{
+		{"every Mon, Wed", "RRULE:FREQ=WEEKLY;BYDAY=MO,WE"},
+		{"Wed and Mon", "RRULE:FREQ=WEEKLY;BYDAY=MO,WE"},
+		{"weekdays", "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{"weekends", "RRULE:FREQ=WEEKLY;BYDAY=SA,SU"},
+		{"every day", "RRULE:FREQ=DAILY"},
+		{"every 2 weeks", "RRULE:FREQ=WEEKLY;INTERVAL=2"},
+		{"every other week on fri", "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR"},
+		{"last day of month", "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{"every 3 months on the 1st, 15th", "RRULE:FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1,15"},
+		{"second Tuesday", "RRULE:FREQ=MONTHLY;BYDAY=2TU"},
+		{"2nd and last fri", "RRULE:FREQ=MONTHLY;BYDAY=2FR,-1FR"},
+		{"every month on fri", "RRULE:FREQ=MONTHLY;BYDAY=1FR"},
+		{"every month on every fri", "RRULE:FREQ=MONTHLY;BYDAY=FR"},
+		{"every month on the last weekday", "RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"},
+		{"every year on mar 3", "RRULE:FREQ=YEARLY;BYMONTHDAY=3;BYMONTH=3"},
+		{"daily until 2026-12-31", "RRULE:FREQ=DAILY;UNTIL=20261231"},
+		{"every week on mon, 5 times", "RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.words, func(t *testing.T) {
+			r, err := Parse(tt.words)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.words, err)
+			}
+			if got := r.String(); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+	for _, words := range []string{"every fortnight", "sometimes", "every week on the 2nd tue"} {
+		if r, err := Parse(words); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", words, r)
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"FREQ=DAILY", "every day"},
+		{"FREQ=DAILY;INTERVAL=3", "every 3 days"},
+		{"FREQ=WEEKLY;BYDAY=MO,WE", "every week on Mon, Wed"},
+		{"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", "every weekday"},
+		{"FREQ=WEEKLY;BYDAY=SA,SU", "every weekend"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH", "every 2 weeks on Tue, Thu"},
+		{"FREQ=MONTHLY;BYMONTHDAY=15", "every month on the 15th"},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", "every month on the last day"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-2", "every month on the 1st, 2nd to last"},
+		{"FREQ=MONTHLY;BYMONTHDAY=13;BYDAY=FR", "every month on the 13th if Fri"},
+		{"FREQ=MONTHLY;BYDAY=2TU", "every month on the 2nd Tue"},
+		{"FREQ=MONTHLY;BYDAY=FR", "every month on every Fri"},
+		{"FREQ=MONTHLY;BYDAY=TU;BYSETPOS=2", "every month on the 2nd Tuesday"},
+		{"FREQ=MONTHLY;BYDAY=TU,TH;BYSETPOS=2,-1", "every month on the 2nd, last of Tue, Thu"},
+		{"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", "every month on the last workday"},
+		{"FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=3", "every year on Mar 3"},
+		{"FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", "every year on the 4th Thu in Nov"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			if got := r.Label(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
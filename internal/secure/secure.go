@@ -0,0 +1,154 @@
+package secure
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// File layout: magic | iterations (uint32 BE) | salt | nonce | AES-256-GCM ciphertext.
+// The header is authenticated as additional data.
+const (
+	magic      = "BADAENC1"
+	saltSize   = 16
+	keySize    = 32
+	iterations = 600_000
+	headerSize = len(magic) + 4 + saltSize
+)
+
+var (
+	ErrPassphraseRequired = errors.New("file is encrypted: passphrase required")
+	ErrWrongPassphrase    = errors.New("wrong passphrase or corrupted file")
+)
+
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// Cipher seals and opens files with one passphrase. Key derivation is the slow
+// part, so derived keys are cached per salt and every file sealed by the same
+// Cipher shares one salt.
+type Cipher struct {
+	passphrase string
+	mu         sync.Mutex
+	salt       []byte
+	keys       map[string][]byte
+}
+
+func New(passphrase string) *Cipher {
+	return &Cipher{passphrase: passphrase, keys: map[string][]byte{}}
+}
+
+func (c *Cipher) Enabled() bool {
+	return c != nil && c.passphrase != ""
+}
+
+func (c *Cipher) Seal(plain []byte) ([]byte, error) {
+	if !c.Enabled() {
+		return nil, ErrPassphraseRequired
+	}
+	c.mu.Lock()
+	if c.salt == nil {
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			c.mu.Unlock()
+			return nil, err
+		}
+		c.salt = salt
+	}
+	salt := c.salt
+	c.mu.Unlock()
+
+	key, err := c.key(salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = binary.BigEndian.AppendUint32(header, iterations)
+	header = append(header, salt...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(header, nonce...)
+	return aead.Seal(out, nonce, plain, header), nil
+}
+
+func (c *Cipher) Open(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if !c.Enabled() {
+		return nil, ErrPassphraseRequired
+	}
+	if len(data) < headerSize {
+		return nil, ErrWrongPassphrase
+	}
+	header := data[:headerSize]
+	iter := int(binary.BigEndian.Uint32(header[len(magic):]))
+	salt := header[len(magic)+4:]
+	key, err := c.key(salt, iter)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	rest := data[headerSize:]
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], header)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+func (c *Cipher) key(salt []byte, iter int) ([]byte, error) {
+	// the cost comes from the file header; a forged one must not stall us
+	if iter <= 0 || iter > 10*iterations {
+		return nil, fmt.Errorf("invalid key derivation cost %d", iter)
+	}
+	cacheKey := fmt.Sprintf("%x:%d", salt, iter)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.keys[cacheKey]; ok {
+		return key, nil
+	}
+	key, err := pbkdf2.Key(sha256.New, c.passphrase, salt, iter, keySize)
+	if err != nil {
+		return nil, err
+	}
+	c.keys[cacheKey] = key
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func Encrypt(plain []byte, passphrase string) ([]byte, error) {
+	return New(passphrase).Seal(plain)
+}
+
+func Decrypt(data []byte, passphrase string) ([]byte, error) {
+	return New(passphrase).Open(data)
+}
@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Attachment struct {
+	ID        int
+	Target    string
+	Label     string
+	Managed   bool
+	CreatedAt time.Time
+	// Data carries the bytes of a managed file inside exports only.
+	Data []byte `json:",omitempty"`
+}
+
+func (a Attachment) Name() string {
+	if a.Label != "" {
+		return a.Label
+	}
+	return filepath.Base(a.Target)
+}
+
+func IsURL(target string) bool {
+	return strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:")
+}
+
+func (s *Store) ensureAttachmentsTable() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS attachments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	target TEXT NOT NULL,
+	label TEXT NOT NULL DEFAULT '',
+	managed INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL
+);`); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_attachments_task_id ON attachments(task_id);`)
+	return err
+}
+
+// AttachmentsDir is where managed copies live, next to the database. It is
+// named after the database so workspaces sharing a directory never share files.
+func (s *Store) AttachmentsDir() string {
+	return filepath.Join(filepath.Dir(s.dbPath), s.snapshotPrefix()+"-attachments")
+}
+
+// AttachmentLocation resolves an attachment to something an opener accepts.
+func (s *Store) AttachmentLocation(a Attachment) (string, error) {
+	if a.Managed {
+		return s.managedPath(a.Target)
+	}
+	return a.Target, nil
+}
+
+// managedPath is where a managed file lives. Targets come from the database
+// but also from import files and trash snapshots, so anything but a plain
+// file name is refused rather than joined onto the directory.
+func (s *Store) managedPath(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || filepath.IsAbs(name) || strings.ContainsAny(name, `/\`) || filepath.Base(name) != name {
+		return "", fmt.Errorf("invalid managed attachment name %q", name)
+	}
+	return filepath.Join(s.AttachmentsDir(), name), nil
+}
+
+// AddAttachment links a file or URL to a task. With managed set, the file is
+// copied into AttachmentsDir so it survives the original being moved.
+func (s *Store) AddAttachment(taskID int, target, label string, managed bool) (Attachment, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return Attachment{}, errors.New("attachment path is empty")
+	}
+	if !IsURL(target) {
+		if strings.HasPrefix(target, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				target = filepath.Join(home, target[2:])
+			}
+		}
+		abs, err := filepath.Abs(target)
+		if err != nil {
+			return Attachment{}, err
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return Attachment{}, err
+		}
+		if managed && info.IsDir() {
+			return Attachment{}, fmt.Errorf("%s is a directory; only files can be copied", target)
+		}
+		target = abs
+	} else if managed {
+		return Attachment{}, errors.New("only files can be copied into managed storage")
+	}
+	if managed {
+		name, err := s.copyIntoManaged(taskID, target)
+		if err != nil {
+			return Attachment{}, err
+		}
+		target = name
+	}
+	a := Attachment{Target: target, Label: strings.TrimSpace(label), Managed: managed, CreatedAt: time.Now().UTC()}
+	res, err := s.db.Exec(`INSERT INTO attachments (task_id, target, label, managed, created_at) VALUES (?, ?, ?, ?, ?);`,
+		taskID, a.Target, a.Label, boolToInt(a.Managed), a.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		if managed {
+			if path, pathErr := s.managedPath(target); pathErr == nil {
+				_ = os.Remove(path)
+			}
+		}
+		return Attachment{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Attachment{}, err
+	}
+	a.ID = int(id)
+	return a, nil
+}
+
+func (s *Store) RemoveAttachment(a Attachment) error {
+	if _, err := s.db.Exec(`DELETE FROM attachments WHERE id = ?;`, a.ID); err != nil {
+		return err
+	}
+	return s.releaseManaged([]Attachment{a})
+}
+
+func (s *Store) copyIntoManaged(taskID int, src string) (string, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	return s.writeManaged(fmt.Sprintf("%d-%s", taskID, filepath.Base(src)), data)
+}
+
+// writeManaged stores data under name, or a numbered variant when taken.
+// Only the last element of name is used.
+func (s *Store) writeManaged(name string, data []byte) (string, error) {
+	name = filepath.Base(filepath.FromSlash(strings.ReplaceAll(name, `\`, "/")))
+	if _, err := s.managedPath(name); err != nil {
+		return "", err
+	}
+	dir := s.AttachmentsDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for i := 1; fileExists(filepath.Join(dir, name)); i++ {
+		name = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// releaseManaged deletes managed files no attachment row refers to anymore.
+// Files of trashed tasks are still referenced by their snapshot, so callers
+// only release after a purge or an explicit detach.
+func (s *Store) releaseManaged(atts []Attachment) error {
+	for _, a := range atts {
+		if !a.Managed {
+			continue
+		}
+		var refs int
+		if err := s.db.QueryRow(`SELECT COUNT(1) FROM attachments WHERE managed = 1 AND target = ?;`, a.Target).Scan(&refs); err != nil {
+			return err
+		}
+		if refs > 0 {
+			continue
+		}
+		path, err := s.managedPath(a.Target)
+		if err != nil {
+			// never ours to delete
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) attachAttachments(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	rows, err := s.db.Query(`SELECT id, task_id, target, label, managed, created_at FROM attachments ORDER BY id;`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	byTask := map[int][]Attachment{}
+	for rows.Next() {
+		var a Attachment
+		var taskID, managed int
+		var created string
+		if err := rows.Scan(&a.ID, &taskID, &a.Target, &a.Label, &managed, &created); err != nil {
+			return err
+		}
+		a.Managed = managed == 1
+		a.CreatedAt = parseTimeWithFallback(created)
+		byTask[taskID] = append(byTask[taskID], a)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].Attachments = byTask[tasks[i].ID]
+	}
+	return nil
+}
+
+func insertAttachmentsTx(tx *sql.Tx, taskID int, atts []Attachment) error {
+	for _, a := range atts {
+		created := a.CreatedAt
+		if created.IsZero() {
+			created = time.Now()
+		}
+		if _, err := tx.Exec(`INSERT INTO attachments (task_id, target, label, managed, created_at) VALUES (?, ?, ?, ?, ?);`,
+			taskID, a.Target, a.Label, boolToInt(a.Managed), created.UTC().Format(time.RFC3339)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// embedManaged loads the bytes of managed files so an export is self-contained.
+func (s *Store) embedManaged(tasks []Task) error {
+	for i := range tasks {
+		for j, a := range tasks[i].Attachments {
+			if !a.Managed {
+				continue
+			}
+			path, err := s.AttachmentLocation(a)
+			if err != nil {
+				return fmt.Errorf("attachment %s of task %d: %w", a.Name(), tasks[i].ID, err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("attachment %s of task %d: %w", a.Name(), tasks[i].ID, err)
+			}
+			tasks[i].Attachments[j].Data = data
+		}
+	}
+	return nil
+}
+
+// adoptManaged makes managed attachments point at files in this store's
+// attachment dir, writing embedded data or copying from src as needed.
+func (s *Store) adoptManaged(atts []Attachment, src *Store) ([]Attachment, error) {
+	out := make([]Attachment, 0, len(atts))
+	for _, a := range atts {
+		if a.Managed {
+			data := a.Data
+			if data == nil && src != nil {
+				path, err := src.AttachmentLocation(a)
+				if err != nil {
+					return nil, err
+				}
+				if data, err = os.ReadFile(path); err != nil {
+					return nil, err
+				}
+			}
+			if path, err := s.AttachmentLocation(a); err != nil {
+				// an unsafe name is rewritten below under its base name
+				if data == nil {
+					return nil, err
+				}
+			} else if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+				data = nil
+			}
+			if data != nil {
+				name, err := s.writeManaged(a.Target, data)
+				if err != nil {
+					return nil, err
+				}
+				a.Target = name
+			}
+		}
+		a.Data = nil
+		out = append(out, a)
+	}
+	return out, nil
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := Open(filepath.Join(dir, "db", "tasks.db"), filepath.Join(dir, "db", "trash"))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestImportManagedAttachmentTraversal(t *testing.T) {
+	s := openTestStore(t)
+	root := filepath.Dir(filepath.Dir(s.dbPath))
+	victim := filepath.Join(root, "victim.txt")
+	if err := os.WriteFile(victim, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	targets := []string{"../victim.txt", "../../evil.txt", "/tmp/evil.txt", `..\evil.txt`}
+	var atts []Attachment
+	for _, target := range targets {
+		atts = append(atts, Attachment{Target: target, Managed: true, Data: []byte("payload")})
+	}
+	if _, err := s.Import(ExportFile{Version: exportVersion, Tasks: []Task{{ID: 1, Title: "evil", Attachments: atts}}}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if data, err := os.ReadFile(victim); err != nil || string(data) != "keep" {
+		t.Fatalf("file outside the attachments dir was touched: %q, %v", data, err)
+	}
+	task, err := s.TaskByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range task.Attachments {
+		path, err := s.AttachmentLocation(a)
+		if err != nil {
+			t.Fatalf("imported attachment %q has no location: %v", a.Target, err)
+		}
+		if filepath.Dir(path) != s.AttachmentsDir() {
+			t.Errorf("imported attachment %q lives at %s, outside %s", a.Target, path, s.AttachmentsDir())
+		}
+	}
+
+	// a trash snapshot can still carry a bad name; releasing it must not delete anything
+	if err := s.releaseManaged([]Attachment{{Target: "../victim.txt", Managed: true}}); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if _, err := os.Stat(victim); err != nil {
+		t.Fatalf("release removed a file outside the attachments dir: %v", err)
+	}
+	for _, target := range []string{"", ".", "..", "../x", "a/b", "/etc/passwd"} {
+		if _, err := s.AttachmentLocation(Attachment{Target: target, Managed: true}); err == nil {
+			t.Errorf("AttachmentLocation(%q) accepted an unsafe name", target)
+		}
+	}
+}
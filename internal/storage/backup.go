@@ -0,0 +1,417 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"bada/internal/secure"
+)
+
+const (
+	snapshotTimeLayout   = "20060102T150405Z"
+	snapshotExt          = ".db"
+	encryptedSnapshotExt = ".db.enc"
+)
+
+type Snapshot struct {
+	Path      string
+	CreatedAt time.Time
+	Size      int64
+	Encrypted bool
+}
+
+type SnapshotInfo struct {
+	Snapshot
+	Tasks     int
+	Open      int
+	Done      int
+	Topics    int
+	Integrity []string
+}
+
+type MaintenanceOptions struct {
+	BackupDir   string
+	BackupEvery time.Duration
+	Keep        int
+	VacuumEvery time.Duration
+}
+
+type MaintenanceReport struct {
+	Snapshot string
+	Pruned   int
+	Vacuumed bool
+	Problems []string
+}
+
+func (s *Store) ensureMaintenanceTable() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS maintenance (
+	name TEXT PRIMARY KEY,
+	ran_at TEXT NOT NULL
+);`)
+	return err
+}
+
+func (s *Store) IntegrityCheck() ([]string, error) {
+	return integrityCheck(s.db)
+}
+
+func integrityCheck(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`PRAGMA integrity_check;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+func (s *Store) Vacuum() error {
+	if _, err := s.db.Exec(`VACUUM;`); err != nil {
+		return err
+	}
+	return s.markMaintenance("vacuum", time.Now())
+}
+
+func (s *Store) Backup(dir string) (string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return "", errors.New("backup dir is empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	ext := snapshotExt
+	if s.encryption.Backups {
+		ext = encryptedSnapshotExt
+	}
+	base := fmt.Sprintf("%s-%s", s.snapshotPrefix(), now.Format(snapshotTimeLayout))
+	path := filepath.Join(dir, base+ext)
+	for i := 1; fileExists(path); i++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, i, ext))
+	}
+	if s.encryption.Backups {
+		if err := s.encryptedBackup(path); err != nil {
+			return "", err
+		}
+	} else if _, err := s.db.Exec(`VACUUM INTO ?;`, path); err != nil {
+		return "", err
+	}
+	if err := s.markMaintenance("backup", now); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+// encryptedBackup vacuums into a private (0700) temporary directory, so the
+// plaintext copy is never readable by others or left next to the backups.
+func (s *Store) encryptedBackup(path string) error {
+	dir, err := os.MkdirTemp(filepath.Dir(s.dbPath), ".bada-backup-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	tmp := filepath.Join(dir, "snapshot.db")
+	if _, err := s.db.Exec(`VACUUM INTO ?;`, tmp); err != nil {
+		return err
+	}
+	plain, err := os.ReadFile(tmp)
+	if err != nil {
+		return err
+	}
+	sealed, err := s.cipher.Seal(plain)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, sealed, 0o600)
+}
+
+// plainSnapshot returns a path to an unencrypted copy of snap; cleanup removes
+// any temporary file it had to create.
+func (s *Store) plainSnapshot(snap Snapshot) (string, func(), error) {
+	data, err := os.ReadFile(snap.Path)
+	if err != nil {
+		return "", func() {}, err
+	}
+	if !secure.IsEncrypted(data) {
+		return snap.Path, func() {}, nil
+	}
+	plain, err := s.cipher.Open(data)
+	if err != nil {
+		return "", func() {}, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.dbPath), ".bada-snapshot-*.db")
+	if err != nil {
+		return "", func() {}, err
+	}
+	cleanup := func() { _ = os.Remove(tmp.Name()) }
+	if _, err := tmp.Write(plain); err != nil {
+		tmp.Close()
+		cleanup()
+		return "", func() {}, err
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	return tmp.Name(), cleanup, nil
+}
+
+func (s *Store) ListSnapshots(dir string) ([]Snapshot, error) {
+	snapshots := []Snapshot{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return snapshots, nil
+		}
+		return nil, err
+	}
+	prefix := s.snapshotPrefix() + "-"
+	for _, de := range entries {
+		name := de.Name()
+		if de.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		encrypted := strings.HasSuffix(name, encryptedSnapshotExt)
+		if !encrypted && !strings.HasSuffix(name, snapshotExt) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, prefix)
+		if len(stamp) > len(snapshotTimeLayout) {
+			stamp = stamp[:len(snapshotTimeLayout)]
+		}
+		created, err := time.Parse(snapshotTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, Snapshot{
+			Path:      filepath.Join(dir, name),
+			CreatedAt: created,
+			Size:      info.Size(),
+			Encrypted: encrypted,
+		})
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].Path > snapshots[j].Path
+		}
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+func (s *Store) PruneSnapshots(dir string, keep int) (int, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+	snapshots, err := s.ListSnapshots(dir)
+	if err != nil {
+		return 0, err
+	}
+	pruned := 0
+	for _, snap := range snapshots[min(keep, len(snapshots)):] {
+		if err := os.Remove(snap.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return pruned, err
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+func (s *Store) InspectSnapshot(snap Snapshot) (SnapshotInfo, error) {
+	info := SnapshotInfo{Snapshot: snap}
+	path, cleanup, err := s.plainSnapshot(snap)
+	if err != nil {
+		return info, err
+	}
+	defer cleanup()
+	db, err := sql.Open("sqlite", sqliteDSNWithMode(path, "ro"))
+	if err != nil {
+		return info, err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	problems, err := integrityCheck(db)
+	if err != nil {
+		return info, err
+	}
+	info.Integrity = problems
+	if err := db.QueryRow(`SELECT COUNT(1), COALESCE(SUM(done = 1), 0) FROM tasks;`).Scan(&info.Tasks, &info.Done); err != nil {
+		return info, err
+	}
+	info.Open = info.Tasks - info.Done
+	if err := db.QueryRow(`SELECT COUNT(DISTINCT topic) FROM task_topics;`).Scan(&info.Topics); err != nil {
+		info.Topics = 0
+	}
+	return info, nil
+}
+
+// RestoreSnapshot swaps the snapshot in as the live database. The current
+// database is snapshotted into dir first so the swap itself can be undone.
+func (s *Store) RestoreSnapshot(snap Snapshot, dir string) (string, error) {
+	info, err := s.InspectSnapshot(snap)
+	if err != nil {
+		return "", fmt.Errorf("snapshot unreadable: %w", err)
+	}
+	if len(info.Integrity) > 0 {
+		return "", fmt.Errorf("snapshot failed integrity check: %s", info.Integrity[0])
+	}
+	safety, err := s.Backup(dir)
+	if err != nil {
+		return "", fmt.Errorf("safety backup failed: %w", err)
+	}
+	src, cleanup, err := s.plainSnapshot(snap)
+	if err != nil {
+		return safety, err
+	}
+	defer cleanup()
+	tmp := s.dbPath + ".restore"
+	if err := copyFile(src, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return safety, err
+	}
+	if err := s.db.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return safety, err
+	}
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		_ = os.Remove(s.dbPath + suffix)
+	}
+	renameErr := os.Rename(tmp, s.dbPath)
+	db, err := openDB(s.dbPath)
+	if err != nil {
+		return safety, err
+	}
+	s.db = db
+	if renameErr != nil {
+		_ = os.Remove(tmp)
+		return safety, renameErr
+	}
+	return safety, s.ensureSchema()
+}
+
+func (s *Store) RunMaintenance(opts MaintenanceOptions) (MaintenanceReport, error) {
+	var report MaintenanceReport
+	now := time.Now()
+	backupDue := false
+	if opts.BackupEvery > 0 && strings.TrimSpace(opts.BackupDir) != "" {
+		last, err := s.lastMaintenance("backup")
+		if err != nil {
+			return report, err
+		}
+		backupDue = last.IsZero() || now.Sub(last) >= opts.BackupEvery
+	}
+	vacuumDue := false
+	if opts.VacuumEvery > 0 {
+		last, err := s.lastMaintenance("vacuum")
+		if err != nil {
+			return report, err
+		}
+		vacuumDue = last.IsZero() || now.Sub(last) >= opts.VacuumEvery
+	}
+	if !backupDue && !vacuumDue {
+		return report, nil
+	}
+	problems, err := s.IntegrityCheck()
+	if err != nil {
+		return report, err
+	}
+	if len(problems) > 0 {
+		// never rotate good snapshots out in favour of a damaged database
+		report.Problems = problems
+		return report, nil
+	}
+	if backupDue {
+		path, err := s.Backup(opts.BackupDir)
+		if err != nil {
+			return report, err
+		}
+		report.Snapshot = path
+		pruned, err := s.PruneSnapshots(opts.BackupDir, opts.Keep)
+		if err != nil {
+			return report, err
+		}
+		report.Pruned = pruned
+	}
+	if vacuumDue {
+		if err := s.Vacuum(); err != nil {
+			return report, err
+		}
+		report.Vacuumed = true
+	}
+	return report, nil
+}
+
+func (s *Store) lastMaintenance(name string) (time.Time, error) {
+	var ranAt string
+	err := s.db.QueryRow(`SELECT ran_at FROM maintenance WHERE name = ?;`, name).Scan(&ranAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return parseTimeWithFallback(ranAt), nil
+}
+
+func (s *Store) markMaintenance(name string, at time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO maintenance (name, ran_at) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET ran_at = excluded.ran_at;`,
+		name, at.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (s *Store) snapshotPrefix() string {
+	name := strings.TrimSuffix(filepath.Base(s.dbPath), filepath.Ext(s.dbPath))
+	if name == "" || name == "." {
+		return "bada"
+	}
+	return name
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
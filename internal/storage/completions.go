@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Completion is one finished occurrence of a recurring task. Due is the
+// occurrence's due date before the task moved on.
+type Completion struct {
+	TaskID      int
+	Due         sql.NullTime
+	CompletedAt time.Time
+}
+
+func (s *Store) ensureCompletionsTable() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS completions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	due TEXT DEFAULT NULL,
+	completed_at TEXT NOT NULL
+);`)
+	return err
+}
+
+func (s *Store) RecordCompletion(taskID int, due sql.NullTime, at time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO completions (task_id, due, completed_at) VALUES (?, ?, ?);`,
+		taskID, nullTimeToString(due), formatEntryTime(at))
+	return err
+}
+
+// MoveCompletions hands a series' history to the task that continues it.
+func (s *Store) MoveCompletions(fromID, toID int) error {
+	_, err := s.db.Exec(`UPDATE completions SET task_id = ? WHERE task_id = ?;`, toID, fromID)
+	return err
+}
+
+// Completions lists a task's recorded completions, oldest first.
+func (s *Store) Completions(taskID int) ([]Completion, error) {
+	rows, err := s.db.Query(`SELECT task_id, due, completed_at FROM completions WHERE task_id = ? ORDER BY completed_at, id;`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Completion
+	for rows.Next() {
+		var c Completion
+		var due sql.NullString
+		var at string
+		if err := rows.Scan(&c.TaskID, &due, &at); err != nil {
+			return nil, err
+		}
+		if due.Valid {
+			if parsed := parseTimeWithFallback(due.String); !parsed.IsZero() {
+				c.Due = sql.NullTime{Time: parsed, Valid: true}
+			}
+		}
+		c.CompletedAt = parseTimeWithFallback(at)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
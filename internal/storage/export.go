@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const exportVersion = zoneFormatVersion
+
+type ExportFile struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Tasks      []Task            `json:"tasks"`
+	TopicNotes map[string]string `json:"topic_notes,omitempty"`
+	// History holds each task's completions and tracked time, by task id.
+	History map[int]taskHistory `json:"history,omitempty"`
+}
+
+func (s *Store) Export() (ExportFile, error) {
+	tasks, err := s.FetchTasks()
+	if err != nil {
+		return ExportFile{}, err
+	}
+	if err := s.embedManaged(tasks); err != nil {
+		return ExportFile{}, err
+	}
+	notes, err := s.topicNotes()
+	if err != nil {
+		return ExportFile{}, err
+	}
+	history := map[int]taskHistory{}
+	for _, t := range tasks {
+		h, err := s.taskHistory(t.ID)
+		if err != nil {
+			return ExportFile{}, err
+		}
+		if len(h.Completions) > 0 || len(h.TimeEntries) > 0 {
+			history[t.ID] = h
+		}
+	}
+	return ExportFile{
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+		Tasks:      tasks,
+		TopicNotes: notes,
+		History:    history,
+	}, nil
+}
+
+func (s *Store) MarshalExport() ([]byte, error) {
+	file, err := s.Export()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+func ParseExport(data []byte) (ExportFile, error) {
+	var file ExportFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return ExportFile{}, fmt.Errorf("not a bada export: %w", err)
+	}
+	if file.Version == 0 || file.Version > exportVersion {
+		return ExportFile{}, fmt.Errorf("unsupported export version %d", file.Version)
+	}
+	return file, nil
+}
+
+// Import adds the exported tasks, reusing their ids when free just like trash
+// restore. Topic notes are merged into existing ones.
+func (s *Store) Import(file ExportFile) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	for _, task := range file.Tasks {
+		normalizeLegacyZone(file.Version, &task, nil)
+		id, err := restoreTaskTx(tx, task)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := s.setTaskTopicsTx(tx, id, task.Topics); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		atts, err := s.adoptManaged(task.Attachments, nil)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := insertAttachmentsTx(tx, id, atts); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := insertOverridesTx(tx, id, task.Overrides); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		h := file.History[task.ID]
+		h.stopRunning(file.ExportedAt)
+		if err := insertHistoryTx(tx, id, h); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	for topic, note := range file.TopicNotes {
+		existing, err := s.TopicNote(topic)
+		if err != nil {
+			return len(file.Tasks), err
+		}
+		if existing == note {
+			continue
+		}
+		if err := s.UpdateTopicNote(topic, mergeNotes(existing, note)); err != nil {
+			return len(file.Tasks), err
+		}
+	}
+	return len(file.Tasks), nil
+}
+
+func (s *Store) topicNotes() (map[string]string, error) {
+	rows, err := s.db.Query(`SELECT topic, notes FROM topic_notes ORDER BY topic;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	notes := map[string]string{}
+	for rows.Next() {
+		var topic string
+		var note sql.NullString
+		if err := rows.Scan(&topic, &note); err != nil {
+			return nil, err
+		}
+		if note.Valid && note.String != "" {
+			notes[topic] = note.String
+		}
+	}
+	return notes, rows.Err()
+}
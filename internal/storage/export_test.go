@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := openTestStore(t)
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	id, err := src.CreateTask(Task{Title: "standup", Timezone: "UTC", Recurring: true, RecurrenceRule: "FREQ=WEEKLY;BYDAY=MO",
+		Due: sql.NullTime{Time: due, Valid: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := src.TaskByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.Due.Time = due.AddDate(0, 0, 7)
+	skip := Override{Kind: OverrideSkip, Occurrence: due, MovedTo: due.AddDate(0, 0, 7),
+		PrevDue: sql.NullTime{Time: due, Valid: true}, PrevRule: task.RecurrenceRule}
+	if err := src.ApplyOverride(task, skip); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.RecordCompletion(id, sql.NullTime{Time: due.AddDate(0, 0, -7), Valid: true}, due.AddDate(0, 0, -7)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := src.StartTimer(id); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := src.MarshalExport()
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	file, err := ParseExport(data)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	dst := openTestStore(t)
+	if _, err := dst.Import(file); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	got, err := dst.TaskByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Overrides) != 1 || got.Overrides[0].Kind != OverrideSkip || !got.Overrides[0].Occurrence.Equal(due.Truncate(24*time.Hour)) {
+		t.Errorf("overrides after round trip = %+v, want the skip of %s", got.Overrides, due.Format("2006-01-02"))
+	}
+	if !got.Due.Valid || !got.Due.Time.Equal(due.AddDate(0, 0, 7)) {
+		t.Errorf("due after round trip = %v, want %v", got.Due, due.AddDate(0, 0, 7))
+	}
+	completions, err := dst.Completions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(completions) != 1 {
+		t.Errorf("%d completions after round trip, want 1", len(completions))
+	}
+	h, err := dst.taskHistory(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.TimeEntries) != 1 || !h.TimeEntries[0].End.Valid {
+		t.Errorf("time entries after round trip = %+v, want one stopped entry", h.TimeEntries)
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+type FocusSession struct {
+	ID      int
+	TaskID  int
+	Title   string
+	Start   time.Time
+	End     time.Time
+	Minutes int
+}
+
+func (s *Store) ensureFocusSessionsTable() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS focus_sessions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	started_at TEXT NOT NULL,
+	ended_at TEXT NOT NULL,
+	minutes INTEGER NOT NULL DEFAULT 0
+);`)
+	return err
+}
+
+func (s *Store) LogFocusSession(taskID int, start, end time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO focus_sessions (task_id, started_at, ended_at, minutes) VALUES (?, ?, ?, ?);`,
+		taskID, formatEntryTime(start), formatEntryTime(end), int(end.Sub(start).Round(time.Minute).Minutes()))
+	return err
+}
+
+// FocusSessionsSince returns completed sessions that ended at or after since.
+func (s *Store) FocusSessionsSince(since time.Time) ([]FocusSession, error) {
+	rows, err := s.db.Query(`SELECT f.id, f.task_id, COALESCE(t.title, ''), f.started_at, f.ended_at, f.minutes
+FROM focus_sessions f LEFT JOIN tasks t ON t.id = f.task_id WHERE f.ended_at >= ? ORDER BY f.ended_at;`, formatEntryTime(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sessions []FocusSession
+	for rows.Next() {
+		var f FocusSession
+		var start, end string
+		if err := rows.Scan(&f.ID, &f.TaskID, &f.Title, &start, &end, &f.Minutes); err != nil {
+			return nil, err
+		}
+		f.Start = parseTimeWithFallback(start)
+		f.End = parseTimeWithFallback(end)
+		if f.Title == "" {
+			f.Title = fmt.Sprintf("(deleted #%d)", f.TaskID)
+		}
+		sessions = append(sessions, f)
+	}
+	return sessions, rows.Err()
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// taskHistory is what a task keeps outside its own row: finished occurrences
+// and tracked time. Moves and the trash carry it along with the task; its
+// occurrence overrides travel in Task.Overrides.
+type taskHistory struct {
+	Completions []Completion `json:"completions,omitempty"`
+	TimeEntries []TimeEntry  `json:"time_entries,omitempty"`
+}
+
+func (s *Store) taskHistory(taskID int) (taskHistory, error) {
+	var h taskHistory
+	completions, err := s.Completions(taskID)
+	if err != nil {
+		return h, err
+	}
+	h.Completions = completions
+	rows, err := s.db.Query(`SELECT id, task_id, started_at, ended_at FROM time_entries WHERE task_id = ? ORDER BY started_at, id;`, taskID)
+	if err != nil {
+		return h, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e TimeEntry
+		var started string
+		var ended sql.NullString
+		if err := rows.Scan(&e.ID, &e.TaskID, &started, &ended); err != nil {
+			return h, err
+		}
+		e.Start = parseTimeWithFallback(started)
+		e.End = parseNullTime(ended)
+		h.TimeEntries = append(h.TimeEntries, e)
+	}
+	return h, rows.Err()
+}
+
+// stopRunning ends a timer that was still running when the history was
+// written down, so restoring it never adds a second running timer.
+func (h taskHistory) stopRunning(at time.Time) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	for i, e := range h.TimeEntries {
+		if !e.End.Valid {
+			h.TimeEntries[i].End = sql.NullTime{Time: at, Valid: true}
+		}
+	}
+}
+
+func insertHistoryTx(tx *sql.Tx, taskID int, h taskHistory) error {
+	for _, c := range h.Completions {
+		if _, err := tx.Exec(`INSERT INTO completions (task_id, due, completed_at) VALUES (?, ?, ?);`,
+			taskID, nullTimeToString(c.Due), formatEntryTime(c.CompletedAt)); err != nil {
+			return err
+		}
+	}
+	for _, e := range h.TimeEntries {
+		var ended sql.NullString
+		if e.End.Valid {
+			ended = sql.NullString{String: formatEntryTime(e.End.Time), Valid: true}
+		}
+		if _, err := tx.Exec(`INSERT INTO time_entries (task_id, started_at, ended_at) VALUES (?, ?, ?);`,
+			taskID, formatEntryTime(e.Start), ended); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteHistory removes the rows taskHistory and Task.Overrides cover.
+func (s *Store) deleteHistory(taskID int) error {
+	for _, table := range []string{"completions", "occurrence_overrides", "time_entries"} {
+		if _, err := s.db.Exec(`DELETE FROM `+table+` WHERE task_id = ?;`, taskID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
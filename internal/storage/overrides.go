@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Override changes a single occurrence of a recurring task without touching
+// its rule: the occurrence due on Occurrence was skipped (the task went on
+// to MovedTo, the next one) or moved to MovedTo. The task's dates and rule
+// from before the change are kept so it can be undone.
+type Override struct {
+	ID         int
+	TaskID     int
+	Kind       string
+	Occurrence time.Time
+	MovedTo    time.Time
+	PrevDue    sql.NullTime
+	PrevStart  sql.NullTime
+	PrevRule   string
+	CreatedAt  time.Time
+}
+
+const (
+	OverrideSkip = "skip"
+	OverrideMove = "move"
+)
+
+func (s *Store) ensureOverridesTable() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS occurrence_overrides (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	occurrence TEXT NOT NULL,
+	moved_to TEXT NOT NULL,
+	prev_due TEXT DEFAULT NULL,
+	prev_start TEXT DEFAULT NULL,
+	prev_rule TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL
+);`)
+	return err
+}
+
+// ApplyOverride saves t, whose dates or rule a skip or move changed, and
+// records o for it in one transaction.
+func (s *Store) ApplyOverride(t Task, o Override) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := s.updateTaskTx(tx, t); err != nil {
+		return err
+	}
+	if err := insertOverridesTx(tx, t.ID, []Override{o}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RevertOverride saves t as the undone skip or move left it and drops the
+// override in one transaction.
+func (s *Store) RevertOverride(t Task, overrideID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := s.updateTaskTx(tx, t); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM occurrence_overrides WHERE id = ?;`, overrideID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertOverridesTx(tx *sql.Tx, taskID int, overrides []Override) error {
+	for _, o := range overrides {
+		created := o.CreatedAt
+		if created.IsZero() {
+			created = time.Now()
+		}
+		if _, err := tx.Exec(`INSERT INTO occurrence_overrides (task_id, kind, occurrence, moved_to, prev_due, prev_start, prev_rule, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+			taskID, o.Kind, o.Occurrence.Format("2006-01-02"), o.MovedTo.Format("2006-01-02"),
+			nullTimeToString(o.PrevDue), nullTimeToString(o.PrevStart), o.PrevRule, formatEntryTime(created)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MoveOverrides hands a series' overrides to the task that continues it.
+func (s *Store) MoveOverrides(fromID, toID int) error {
+	_, err := s.db.Exec(`UPDATE occurrence_overrides SET task_id = ? WHERE task_id = ?;`, toID, fromID)
+	return err
+}
+
+func (s *Store) attachOverrides(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	rows, err := s.db.Query(`SELECT id, task_id, kind, occurrence, moved_to, prev_due, prev_start, prev_rule, created_at FROM occurrence_overrides ORDER BY id;`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	byTask := map[int][]Override{}
+	for rows.Next() {
+		var o Override
+		var occurrence, movedTo, created string
+		var prevDue, prevStart sql.NullString
+		if err := rows.Scan(&o.ID, &o.TaskID, &o.Kind, &occurrence, &movedTo, &prevDue, &prevStart, &o.PrevRule, &created); err != nil {
+			return err
+		}
+		o.Occurrence = parseTimeWithFallback(occurrence)
+		o.MovedTo = parseTimeWithFallback(movedTo)
+		o.PrevDue = parseNullTime(prevDue)
+		o.PrevStart = parseNullTime(prevStart)
+		o.CreatedAt = parseTimeWithFallback(created)
+		byTask[o.TaskID] = append(byTask[o.TaskID], o)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].Overrides = byTask[tasks[i].ID]
+	}
+	return nil
+}
+
+func parseNullTime(v sql.NullString) sql.NullTime {
+	if !v.Valid {
+		return sql.NullTime{}
+	}
+	parsed := parseTimeWithFallback(v.String)
+	return sql.NullTime{Time: parsed, Valid: !parsed.IsZero()}
+}
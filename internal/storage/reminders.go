@@ -0,0 +1,29 @@
+package storage
+
+import "time"
+
+// Sent reminders are keyed by the due time they were computed from, so
+// moving the due date (or a recurrence advancing it) re-arms them.
+func (s *Store) ensureRemindersTable() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS reminders_sent (
+	task_id INTEGER NOT NULL,
+	due TEXT NOT NULL,
+	offset_minutes INTEGER NOT NULL,
+	sent_at TEXT NOT NULL,
+	PRIMARY KEY (task_id, due, offset_minutes)
+);`)
+	return err
+}
+
+func (s *Store) ReminderSent(taskID int, due time.Time, offset time.Duration) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(1) FROM reminders_sent WHERE task_id = ? AND due = ? AND offset_minutes = ?;`,
+		taskID, formatEntryTime(due), int(offset/time.Minute)).Scan(&n)
+	return n > 0, err
+}
+
+func (s *Store) MarkReminderSent(taskID int, due time.Time, offset time.Duration, at time.Time) error {
+	_, err := s.db.Exec(`INSERT OR IGNORE INTO reminders_sent (task_id, due, offset_minutes, sent_at) VALUES (?, ?, ?, ?);`,
+		taskID, formatEntryTime(due), int(offset/time.Minute), formatEntryTime(at))
+	return err
+}
@@ -13,12 +13,15 @@ import (
 	"time"
 
 	_ "modernc.org/sqlite"
+
+	"bada/internal/secure"
 )
 
 type Task struct {
 	ID                 int
 	Title              string
 	Done               bool
+	Status             string
 	Topics             []string
 	Timezone           string
 	Tags               string
@@ -28,20 +31,49 @@ type Task struct {
 	Recurring          bool
 	RecurrenceRule     string
 	RecurrenceInterval int
+	OnComplete         string
+	EstimateMinutes    int
+	BlockMinutes       int
+	SnoozedUntil       sql.NullTime
+	Reminders          string
 	Notes              string
 	CreatedAt          time.Time
 	CompletedAt        sql.NullTime
+	Attachments        []Attachment
+	Overrides          []Override
 }
 
+// taskColumns is the column list scanTask expects, in order.
+const taskColumns = `id, title, done, status, tags, due, start_at, timezone, priority, recurring, recurrence_rule, recurrence_interval, on_complete, estimate_minutes, block_minutes, snoozed_until, reminders, notes, created_at, completed_at`
+
 type Store struct {
-	db       *sql.DB
-	trashDir string
+	db         *sql.DB
+	dbPath     string
+	trashDir   string
+	cipher     *secure.Cipher
+	encryption EncryptionOptions
+}
+
+type EncryptionOptions struct {
+	Passphrase string
+	Trash      bool
+	Backups    bool
 }
 
 type TrashEntry struct {
 	Path      string
 	DeletedAt time.Time
+	Batch     string
 	Task      Task
+	history   taskHistory
+}
+
+type trashPayload struct {
+	Version   int          `json:"version,omitempty"`
+	DeletedAt time.Time    `json:"deleted_at"`
+	Batch     string       `json:"batch,omitempty"`
+	Task      Task         `json:"task"`
+	History   *taskHistory `json:"history,omitempty"`
 }
 
 type rowScanner interface {
@@ -58,12 +90,13 @@ func Open(dbPath, trashDir string) (*Store, error) {
 	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil && !errors.Is(err, os.ErrExist) {
 		return nil, err
 	}
-	dsn := sqliteDSN(dbPath)
-	db, err := sql.Open("sqlite", dsn)
+	if abs, err := filepath.Abs(dbPath); err == nil && !strings.HasPrefix(dbPath, "file:") {
+		dbPath = abs
+	}
+	db, err := openDB(dbPath)
 	if err != nil {
 		return nil, err
 	}
-	db.SetMaxOpenConns(1)
 
 	absTrash := trashDir
 	if !filepath.IsAbs(absTrash) {
@@ -72,7 +105,7 @@ func Open(dbPath, trashDir string) (*Store, error) {
 		}
 	}
 
-	s := &Store{db: db, trashDir: absTrash}
+	s := &Store{db: db, dbPath: dbPath, trashDir: absTrash}
 	if err := s.ensureSchema(); err != nil {
 		db.Close()
 		return nil, err
@@ -80,6 +113,34 @@ func Open(dbPath, trashDir string) (*Store, error) {
 	return s, nil
 }
 
+func openDB(dbPath string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", sqliteDSN(dbPath))
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+func (s *Store) SetEncryption(opts EncryptionOptions) error {
+	s.cipher = secure.New(opts.Passphrase)
+	if (opts.Trash || opts.Backups) && !s.cipher.Enabled() {
+		return errors.New("encryption is enabled but no passphrase was given")
+	}
+	s.encryption = opts
+	return nil
+}
+
+// Encryption returns the options last passed to SetEncryption so another
+// store (e.g. a second workspace) can be opened with the same settings.
+func (s *Store) Encryption() EncryptionOptions {
+	return s.encryption
+}
+
+func (s *Store) DBPath() string {
+	return s.dbPath
+}
+
 func (s *Store) Close() error {
 	if s.db == nil {
 		return nil
@@ -93,6 +154,7 @@ CREATE TABLE IF NOT EXISTS tasks (
 	id INTEGER PRIMARY KEY AUTOINCREMENT,
 	title TEXT NOT NULL,
 	done INTEGER NOT NULL DEFAULT 0,
+	status TEXT NOT NULL DEFAULT '',
 	tags TEXT DEFAULT '',
 	due TEXT DEFAULT NULL,
 	start_at TEXT DEFAULT NULL,
@@ -101,6 +163,10 @@ CREATE TABLE IF NOT EXISTS tasks (
 	recurring INTEGER NOT NULL DEFAULT 0,
 	recurrence_rule TEXT DEFAULT '',
 	recurrence_interval INTEGER NOT NULL DEFAULT 0,
+	on_complete TEXT NOT NULL DEFAULT '',
+	estimate_minutes INTEGER NOT NULL DEFAULT 0,
+	snoozed_until TEXT DEFAULT NULL,
+	reminders TEXT NOT NULL DEFAULT '',
 	notes TEXT DEFAULT '',
 	created_at TEXT NOT NULL
 );`
@@ -119,6 +185,37 @@ CREATE TABLE IF NOT EXISTS tasks (
 	if err := s.ensureTaskTopics(); err != nil {
 		return err
 	}
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS task_id_remap (
+	old_id INTEGER PRIMARY KEY,
+	new_id INTEGER NOT NULL,
+	restored_at TEXT NOT NULL
+);`); err != nil {
+		return err
+	}
+	if err := s.ensureAttachmentsTable(); err != nil {
+		return err
+	}
+	if err := s.ensureOverridesTable(); err != nil {
+		return err
+	}
+	if err := s.ensureTimeEntriesTable(); err != nil {
+		return err
+	}
+	if err := s.ensureFocusSessionsTable(); err != nil {
+		return err
+	}
+	if err := s.ensureRemindersTable(); err != nil {
+		return err
+	}
+	if err := s.ensureCompletionsTable(); err != nil {
+		return err
+	}
+	if err := s.ensureMaintenanceTable(); err != nil {
+		return err
+	}
+	if err := s.migrateOffsetZones(); err != nil {
+		return err
+	}
 	if err := s.dropLegacyTopicColumn(); err != nil {
 		return err
 	}
@@ -133,8 +230,14 @@ func (s *Store) ensureTaskColumns() error {
 		"recurring":           "ALTER TABLE tasks ADD COLUMN recurring INTEGER NOT NULL DEFAULT 0;",
 		"recurrence_rule":     "ALTER TABLE tasks ADD COLUMN recurrence_rule TEXT DEFAULT '';",
 		"recurrence_interval": "ALTER TABLE tasks ADD COLUMN recurrence_interval INTEGER NOT NULL DEFAULT 0;",
+		"on_complete":         "ALTER TABLE tasks ADD COLUMN on_complete TEXT NOT NULL DEFAULT '';",
+		"estimate_minutes":    "ALTER TABLE tasks ADD COLUMN estimate_minutes INTEGER NOT NULL DEFAULT 0;",
+		"block_minutes":       "ALTER TABLE tasks ADD COLUMN block_minutes INTEGER NOT NULL DEFAULT 0;",
+		"snoozed_until":       "ALTER TABLE tasks ADD COLUMN snoozed_until TEXT DEFAULT NULL;",
+		"reminders":           "ALTER TABLE tasks ADD COLUMN reminders TEXT NOT NULL DEFAULT '';",
 		"completed_at":        "ALTER TABLE tasks ADD COLUMN completed_at TEXT DEFAULT NULL;",
 		"notes":               "ALTER TABLE tasks ADD COLUMN notes TEXT DEFAULT '';",
+		"status":              "ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT '';",
 	}
 	existing := map[string]struct{}{}
 	rows, err := s.db.Query(`PRAGMA table_info(tasks);`)
@@ -152,6 +255,9 @@ func (s *Store) ensureTaskColumns() error {
 		}
 		existing[name] = struct{}{}
 	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
 	for col, alter := range required {
 		if _, ok := existing[col]; ok {
 			continue
@@ -159,8 +265,14 @@ func (s *Store) ensureTaskColumns() error {
 		if _, err := s.db.Exec(alter); err != nil {
 			return err
 		}
+		if col == "status" {
+			// map the old done flag onto the default workflow
+			if _, err := s.db.Exec(`UPDATE tasks SET status = CASE WHEN done = 1 THEN 'done' ELSE 'todo' END WHERE status = '';`); err != nil {
+				return err
+			}
+		}
 	}
-	return rows.Err()
+	return nil
 }
 
 func (s *Store) ensureTaskTopics() error {
@@ -242,7 +354,7 @@ func (s *Store) ensureTopicNoteColumns() error {
 }
 
 func (s *Store) FetchTasks() ([]Task, error) {
-	rows, err := s.db.Query(`SELECT id, title, done, tags, due, start_at, timezone, priority, recurring, recurrence_rule, recurrence_interval, notes, created_at, completed_at FROM tasks ORDER BY id;`)
+	rows, err := s.db.Query(`SELECT ` + taskColumns + ` FROM tasks ORDER BY id;`)
 	if err != nil {
 		return nil, err
 	}
@@ -264,31 +376,67 @@ func (s *Store) FetchTasks() ([]Task, error) {
 	if err := s.attachTopics(tasks, ids); err != nil {
 		return nil, err
 	}
+	if err := s.attachAttachments(tasks); err != nil {
+		return nil, err
+	}
+	if err := s.attachOverrides(tasks); err != nil {
+		return nil, err
+	}
 	return tasks, nil
 }
 
-func (s *Store) AddTask(title string) (int, error) {
-	now := time.Now().UTC().Format(time.RFC3339)
-	res, err := s.db.Exec(`INSERT INTO tasks (title, done, created_at) VALUES (?, 0, ?);`, title, now)
+// CreateTask inserts a fully populated task, topics included, in one
+// transaction and returns its id.
+func (s *Store) CreateTask(t Task) (int, error) {
+	t.ID = 0
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	tx, err := s.db.Begin()
 	if err != nil {
 		return 0, err
 	}
-	id, err := res.LastInsertId()
+	id, err := restoreTaskTx(tx, t)
 	if err != nil {
+		tx.Rollback()
 		return 0, err
 	}
-	return int(id), nil
+	if err := s.setTaskTopicsTx(tx, id, t.Topics); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return id, tx.Commit()
 }
 
-func (s *Store) SetDone(id int, done bool) error {
-	val := 0
-	completed := sql.NullString{}
-	if done {
-		val = 1
-		completed = sql.NullString{String: time.Now().UTC().Format(time.RFC3339), Valid: true}
+func (s *Store) TaskByID(id int) (Task, error) {
+	return s.fetchTaskByID(id)
+}
+
+// UpdateTask writes every editable field of t, topics included, in one
+// transaction. Attachments and created_at are left alone.
+func (s *Store) UpdateTask(t Task) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
 	}
-	_, err := s.db.Exec(`UPDATE tasks SET done = ?, completed_at = ? WHERE id = ?;`, val, completed, id)
-	return err
+	if err := s.updateTaskTx(tx, t); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) updateTaskTx(tx *sql.Tx, t Task) error {
+	t.Timezone = stampZone(t.Timezone)
+	t.Priority = max(0, min(5, t.Priority))
+	_, err := tx.Exec(`UPDATE tasks SET title = ?, done = ?, status = ?, tags = ?, due = ?, start_at = ?, timezone = ?, priority = ?, recurring = ?,
+recurrence_rule = ?, recurrence_interval = ?, on_complete = ?, estimate_minutes = ?, block_minutes = ?, snoozed_until = ?, reminders = ?, notes = ?, completed_at = ? WHERE id = ?;`,
+		t.Title, boolToInt(t.Done), t.Status, t.Tags, nullTimeToString(t.Due), nullTimeToString(t.Start), t.Timezone, t.Priority, boolToInt(t.Recurring),
+		t.RecurrenceRule, t.RecurrenceInterval, t.OnComplete, t.EstimateMinutes, t.BlockMinutes, nullTimeToString(t.SnoozedUntil), t.Reminders, t.Notes, nullTimeToString(t.CompletedAt), t.ID)
+	if err != nil {
+		return err
+	}
+	return s.setTaskTopicsTx(tx, t.ID, t.Topics)
 }
 
 func (s *Store) DeleteTask(id int) error {
@@ -296,22 +444,118 @@ func (s *Store) DeleteTask(id int) error {
 	if err != nil {
 		return err
 	}
+	// the snapshot keeps the task's tracked time, so a running timer ends here
+	if err := s.stopTimerFor(id); err != nil {
+		return err
+	}
 	if err := s.moveToTrash([]Task{task}); err != nil {
 		return err
 	}
 	if _, err := s.db.Exec(`DELETE FROM task_topics WHERE task_id = ?;`, id); err != nil {
 		return err
 	}
+	if _, err := s.db.Exec(`DELETE FROM attachments WHERE task_id = ?;`, id); err != nil {
+		return err
+	}
+	if err := s.deleteHistory(id); err != nil {
+		return err
+	}
 	_, err = s.db.Exec(`DELETE FROM tasks WHERE id = ?;`, id)
 	return err
 }
 
+// MoveTasks copies tasks, with their completions, occurrence overrides and
+// tracked time, into dst (keeping their ids when free) and then deletes them
+// here without going through the trash. The copy is committed before the
+// delete, so a failure leaves a duplicate rather than a loss.
+func (s *Store) MoveTasks(tasks []Task, dst *Store) (map[int]int, error) {
+	if s == dst || s.dbPath == dst.dbPath {
+		return nil, errors.New("source and destination are the same database")
+	}
+	tasks = append([]Task(nil), tasks...)
+	if err := s.attachOverrides(tasks); err != nil {
+		return nil, err
+	}
+	histories := make([]taskHistory, len(tasks))
+	for i, task := range tasks {
+		// a running timer ends here; the other workspace gets the finished entry
+		if err := s.stopTimerFor(task.ID); err != nil {
+			return nil, err
+		}
+		h, err := s.taskHistory(task.ID)
+		if err != nil {
+			return nil, err
+		}
+		histories[i] = h
+	}
+	tx, err := dst.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]int, len(tasks))
+	for i, task := range tasks {
+		id, err := restoreTaskTx(tx, task)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		if err := insertOverridesTx(tx, id, task.Overrides); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		if err := insertHistoryTx(tx, id, histories[i]); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		if err := dst.setTaskTopicsTx(tx, id, task.Topics); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		atts, err := dst.adoptManaged(task.Attachments, s)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		if err := insertAttachmentsTx(tx, id, atts); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		ids[task.ID] = id
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if _, err := s.db.Exec(`DELETE FROM task_topics WHERE task_id = ?;`, task.ID); err != nil {
+			return ids, err
+		}
+		if _, err := s.db.Exec(`DELETE FROM attachments WHERE task_id = ?;`, task.ID); err != nil {
+			return ids, err
+		}
+		if err := s.deleteHistory(task.ID); err != nil {
+			return ids, err
+		}
+		if _, err := s.db.Exec(`DELETE FROM tasks WHERE id = ?;`, task.ID); err != nil {
+			return ids, err
+		}
+		if err := s.releaseManaged(task.Attachments); err != nil {
+			return ids, err
+		}
+	}
+	return ids, nil
+}
+
 func (s *Store) DeleteDoneTasks() (int64, error) {
 	doneTasks, err := s.fetchDoneTasks()
 	if err != nil {
 		return 0, err
 	}
 	if len(doneTasks) > 0 {
+		for _, task := range doneTasks {
+			if err := s.stopTimerFor(task.ID); err != nil {
+				return 0, err
+			}
+		}
 		if err := s.moveToTrash(doneTasks); err != nil {
 			return 0, err
 		}
@@ -319,6 +563,12 @@ func (s *Store) DeleteDoneTasks() (int64, error) {
 			if _, err := s.db.Exec(`DELETE FROM task_topics WHERE task_id = ?;`, task.ID); err != nil {
 				return 0, err
 			}
+			if _, err := s.db.Exec(`DELETE FROM attachments WHERE task_id = ?;`, task.ID); err != nil {
+				return 0, err
+			}
+			if err := s.deleteHistory(task.ID); err != nil {
+				return 0, err
+			}
 		}
 	}
 	res, err := s.db.Exec(`DELETE FROM tasks WHERE done = 1;`)
@@ -366,81 +616,6 @@ func (s *Store) DeleteTopic(topic string) (int64, error) {
 	return res.RowsAffected()
 }
 
-func (s *Store) UpdateTitle(id int, title string) error {
-	_, err := s.db.Exec(`UPDATE tasks SET title = ? WHERE id = ?;`, title, id)
-	return err
-}
-
-func (s *Store) UpdatePriority(id int, priority int) error {
-	if priority < 0 {
-		priority = 0
-	}
-	if priority > 5 {
-		priority = 5
-	}
-	_, err := s.db.Exec(`UPDATE tasks SET priority = ? WHERE id = ?;`, priority, id)
-	return err
-}
-
-func (s *Store) ShiftDue(id int, days int) error {
-	var current sql.NullString
-	err := s.db.QueryRow(`SELECT due FROM tasks WHERE id = ?;`, id).Scan(&current)
-	if err != nil {
-		return err
-	}
-	var base time.Time
-	if current.Valid {
-		base = parseTimeWithFallback(current.String)
-	} else {
-		base = time.Now().UTC()
-	}
-	newTime := base.AddDate(0, 0, days)
-	newStr := sql.NullString{String: newTime.UTC().Format(time.RFC3339), Valid: true}
-	_, err = s.db.Exec(`UPDATE tasks SET due = ? WHERE id = ?;`, newStr, id)
-	return err
-}
-
-func (s *Store) UpdateTaskMetadata(id int, topic, tags, timezone string, priority int, due, start sql.NullTime, recurring bool) error {
-	dueStr := sql.NullString{}
-	if due.Valid {
-		dueStr = sql.NullString{String: due.Time.UTC().Format(time.RFC3339), Valid: true}
-	}
-	startStr := sql.NullString{}
-	if start.Valid {
-		startStr = sql.NullString{String: start.Time.UTC().Format(time.RFC3339), Valid: true}
-	}
-	rec := 0
-	if recurring {
-		rec = 1
-	}
-	topics := splitTopics(topic)
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	_, err = tx.Exec(`UPDATE tasks SET tags = ?, timezone = ?, priority = ?, due = ?, start_at = ?, recurring = ? WHERE id = ?;`,
-		tags, timezone, priority, dueStr, startStr, rec, id)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-	if err := s.setTaskTopicsTx(tx, id, topics); err != nil {
-		tx.Rollback()
-		return err
-	}
-	return tx.Commit()
-}
-
-func (s *Store) UpdateRecurrence(id int, rule string, interval int) error {
-	_, err := s.db.Exec(`UPDATE tasks SET recurrence_rule = ?, recurrence_interval = ? WHERE id = ?;`, rule, interval, id)
-	return err
-}
-
-func (s *Store) UpdateTaskNotes(id int, notes string) error {
-	_, err := s.db.Exec(`UPDATE tasks SET notes = ? WHERE id = ?;`, notes, id)
-	return err
-}
-
 func (s *Store) TopicNote(topic string) (string, error) {
 	topic = strings.TrimSpace(topic)
 	if topic == "" {
@@ -478,8 +653,24 @@ func (s *Store) DeleteTopicNote(topic string) error {
 	return err
 }
 
+// UnreadableTrash reports snapshots ListTrash skipped because they could not
+// be decrypted; the entries it returns alongside are the readable ones.
+type UnreadableTrash struct {
+	Names []string
+	Err   error
+}
+
+func (e *UnreadableTrash) Error() string {
+	return fmt.Sprintf("%d trash item(s) unreadable (%s): %v", len(e.Names), strings.Join(e.Names, ", "), e.Err)
+}
+
+func (e *UnreadableTrash) Unwrap() error {
+	return e.Err
+}
+
 func (s *Store) ListTrash() ([]TrashEntry, error) {
 	entries := []TrashEntry{}
+	var unreadable *UnreadableTrash
 	dirEntries, err := os.ReadDir(s.trashDir)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -496,58 +687,125 @@ func (s *Store) ListTrash() ([]TrashEntry, error) {
 		if err != nil {
 			return nil, err
 		}
-		var payload struct {
-			DeletedAt time.Time `json:"deleted_at"`
-			Task      Task      `json:"task"`
+		if secure.IsEncrypted(data) {
+			if data, err = s.cipher.Open(data); err != nil {
+				if unreadable == nil {
+					unreadable = &UnreadableTrash{Err: err}
+				}
+				unreadable.Names = append(unreadable.Names, de.Name())
+				continue
+			}
 		}
+		var payload trashPayload
 		if err := json.Unmarshal(data, &payload); err != nil {
 			continue
 		}
-		entries = append(entries, TrashEntry{
+		normalizeLegacyZone(payload.Version, &payload.Task, payload.History)
+		batch := payload.Batch
+		if batch == "" {
+			// snapshots written before batches existed share a timestamp per delete call
+			batch = payload.DeletedAt.UTC().Format(time.RFC3339Nano)
+		}
+		entry := TrashEntry{
 			Path:      path,
 			DeletedAt: payload.DeletedAt,
+			Batch:     batch,
 			Task:      payload.Task,
-		})
+		}
+		if payload.History != nil {
+			entry.history = *payload.History
+			entry.history.stopRunning(payload.DeletedAt)
+		}
+		entries = append(entries, entry)
 	}
 	sort.Slice(entries, func(i, j int) bool {
 		return entries[i].DeletedAt.After(entries[j].DeletedAt)
 	})
+	if unreadable != nil {
+		return entries, unreadable
+	}
 	return entries, nil
 }
 
-func (s *Store) RestoreTrash(entries []TrashEntry) error {
+func (s *Store) RestoreTrash(entries []TrashEntry) (map[int]int, error) {
+	remapped := map[int]int{}
 	if len(entries) == 0 {
-		return nil
+		return remapped, nil
 	}
+	now := time.Now().UTC().Format(time.RFC3339)
 	tx, err := s.db.Begin()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, e := range entries {
+		// keep the original id when it is free so "#42"-style references survive
 		task := e.Task
-		res, err := tx.Exec(`INSERT INTO tasks (title, done, tags, due, start_at, timezone, priority, recurring, recurrence_rule, recurrence_interval, notes, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
-			task.Title, boolToInt(task.Done), task.Tags, nullTimeToString(task.Due), nullTimeToString(task.Start), task.Timezone, task.Priority, boolToInt(task.Recurring), task.RecurrenceRule, task.RecurrenceInterval, task.Notes, task.CreatedAt.Format(time.RFC3339))
+		id, err := restoreTaskTx(tx, task)
 		if err != nil {
 			tx.Rollback()
-			return err
+			return nil, err
 		}
-		id, err := res.LastInsertId()
-		if err != nil {
+		if task.ID > 0 && id != task.ID {
+			if _, err := tx.Exec(`INSERT INTO task_id_remap (old_id, new_id, restored_at) VALUES (?, ?, ?)
+ON CONFLICT(old_id) DO UPDATE SET new_id = excluded.new_id, restored_at = excluded.restored_at;`, task.ID, id, now); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			remapped[task.ID] = id
+		}
+		if err := s.setTaskTopicsTx(tx, id, task.Topics); err != nil {
 			tx.Rollback()
-			return err
+			return nil, err
 		}
-		if err := s.setTaskTopicsTx(tx, int(id), task.Topics); err != nil {
+		if err := insertAttachmentsTx(tx, id, task.Attachments); err != nil {
 			tx.Rollback()
-			return err
+			return nil, err
+		}
+		if err := insertOverridesTx(tx, id, task.Overrides); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := insertHistoryTx(tx, id, e.history); err != nil {
+			tx.Rollback()
+			return nil, err
 		}
 	}
 	if err := tx.Commit(); err != nil {
-		return err
+		return nil, err
 	}
 	for _, e := range entries {
 		_ = os.Remove(e.Path)
 	}
-	return nil
+	return remapped, nil
+}
+
+func restoreTaskTx(tx *sql.Tx, task Task) (int, error) {
+	task.Timezone = stampZone(task.Timezone)
+	args := []any{task.Title, boolToInt(task.Done), task.Status, task.Tags, nullTimeToString(task.Due), nullTimeToString(task.Start), task.Timezone, task.Priority,
+		boolToInt(task.Recurring), task.RecurrenceRule, task.RecurrenceInterval, task.OnComplete, task.EstimateMinutes, task.BlockMinutes, nullTimeToString(task.SnoozedUntil), task.Reminders, task.Notes, task.CreatedAt.UTC().Format(time.RFC3339), nullTimeToString(task.CompletedAt)}
+	if task.ID > 0 {
+		var taken int
+		if err := tx.QueryRow(`SELECT COUNT(1) FROM tasks WHERE id = ?;`, task.ID).Scan(&taken); err != nil {
+			return 0, err
+		}
+		if taken == 0 {
+			_, err := tx.Exec(`INSERT INTO tasks (id, title, done, status, tags, due, start_at, timezone, priority, recurring, recurrence_rule, recurrence_interval, on_complete, estimate_minutes, block_minutes, snoozed_until, reminders, notes, created_at, completed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+				append([]any{task.ID}, args...)...)
+			if err != nil {
+				return 0, err
+			}
+			return task.ID, nil
+		}
+	}
+	res, err := tx.Exec(`INSERT INTO tasks (title, done, status, tags, due, start_at, timezone, priority, recurring, recurrence_rule, recurrence_interval, on_complete, estimate_minutes, block_minutes, snoozed_until, reminders, notes, created_at, completed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`, args...)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
 }
 
 func (s *Store) TrashDir() string {
@@ -559,12 +817,15 @@ func (s *Store) PurgeTrash(entries []TrashEntry) error {
 		if err := os.Remove(e.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
 			return err
 		}
+		if err := s.releaseManaged(e.Task.Attachments); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 func (s *Store) fetchTaskByID(id int) (Task, error) {
-	row := s.db.QueryRow(`SELECT id, title, done, tags, due, start_at, timezone, priority, recurring, recurrence_rule, recurrence_interval, notes, created_at, completed_at FROM tasks WHERE id = ?;`, id)
+	row := s.db.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ?;`, id)
 	task, err := scanTask(row)
 	if err != nil {
 		return Task{}, err
@@ -574,11 +835,18 @@ func (s *Store) fetchTaskByID(id int) (Task, error) {
 		return Task{}, err
 	}
 	task.Topics = topics
-	return task, nil
+	tasks := []Task{task}
+	if err := s.attachAttachments(tasks); err != nil {
+		return Task{}, err
+	}
+	if err := s.attachOverrides(tasks); err != nil {
+		return Task{}, err
+	}
+	return tasks[0], nil
 }
 
 func (s *Store) fetchDoneTasks() ([]Task, error) {
-	rows, err := s.db.Query(`SELECT id, title, done, tags, due, start_at, timezone, priority, recurring, recurrence_rule, recurrence_interval, notes, created_at, completed_at FROM tasks WHERE done = 1 ORDER BY id;`)
+	rows, err := s.db.Query(`SELECT ` + taskColumns + ` FROM tasks WHERE done = 1 ORDER BY id;`)
 	if err != nil {
 		return nil, err
 	}
@@ -600,12 +868,17 @@ func (s *Store) fetchDoneTasks() ([]Task, error) {
 	if err := s.attachTopics(tasks, ids); err != nil {
 		return nil, err
 	}
+	if err := s.attachAttachments(tasks); err != nil {
+		return nil, err
+	}
+	if err := s.attachOverrides(tasks); err != nil {
+		return nil, err
+	}
 	return tasks, nil
 }
 
 func (s *Store) fetchTasksByTopic(topic string) ([]Task, error) {
-	rows, err := s.db.Query(`SELECT DISTINCT tasks.id, tasks.title, tasks.done, tasks.tags, tasks.due, tasks.start_at, tasks.timezone, tasks.priority,
-tasks.recurring, tasks.recurrence_rule, tasks.recurrence_interval, tasks.notes, tasks.created_at, tasks.completed_at
+	rows, err := s.db.Query(`SELECT DISTINCT `+taskColumns+`
 FROM tasks
 INNER JOIN task_topics ON tasks.id = task_topics.task_id
 WHERE task_topics.topic = ?
@@ -631,6 +904,12 @@ ORDER BY tasks.id;`, topic)
 	if err := s.attachTopics(tasks, ids); err != nil {
 		return nil, err
 	}
+	if err := s.attachAttachments(tasks); err != nil {
+		return nil, err
+	}
+	if err := s.attachOverrides(tasks); err != nil {
+		return nil, err
+	}
 	return tasks, nil
 }
 
@@ -699,7 +978,9 @@ func (s *Store) fetchTopicsForTasks(ids []int) (map[int][]string, error) {
 	return m, nil
 }
 
-func splitTopics(raw string) []string {
+// SplitTopics parses a comma-separated topic list, dropping blanks and
+// duplicates.
+func SplitTopics(raw string) []string {
 	parts := strings.Split(raw, ",")
 	return normalizeTopics(parts)
 }
@@ -754,19 +1035,30 @@ func (s *Store) moveToTrash(tasks []Task) error {
 		return err
 	}
 	now := time.Now().UTC()
+	batch := fmt.Sprintf("%s-%d", now.Format("20060102T150405Z"), now.UnixNano()%1e9)
 	for i, t := range tasks {
-		payload := struct {
-			DeletedAt time.Time `json:"deleted_at"`
-			Task      Task      `json:"task"`
-		}{
+		history, err := s.taskHistory(t.ID)
+		if err != nil {
+			return err
+		}
+		payload := trashPayload{
+			Version:   zoneFormatVersion,
 			DeletedAt: now,
+			Batch:     batch,
 			Task:      t,
+			History:   &history,
 		}
 		data, err := json.MarshalIndent(payload, "", "  ")
 		if err != nil {
 			return err
 		}
 		name := fmt.Sprintf("%s-%d-%d-%s.json", now.Format("20060102T150405Z"), t.ID, i, sanitizeFilename(t.Title))
+		if s.encryption.Trash {
+			if data, err = s.cipher.Seal(data); err != nil {
+				return err
+			}
+			name += ".enc"
+		}
 		path := filepath.Join(s.trashDir, name)
 		if err := os.WriteFile(path, data, 0o644); err != nil {
 			return err
@@ -781,10 +1073,10 @@ func scanTask(scanner rowScanner) (Task, error) {
 	var rule sql.NullString
 	var interval int
 	var notes sql.NullString
-	var dueStr, startStr, completedStr sql.NullString
+	var dueStr, startStr, snoozedStr, completedStr sql.NullString
 	var createdStr string
 
-	if err := scanner.Scan(&t.ID, &t.Title, &doneInt, &t.Tags, &dueStr, &startStr, &t.Timezone, &priority, &recurring, &rule, &interval, &notes, &createdStr, &completedStr); err != nil {
+	if err := scanner.Scan(&t.ID, &t.Title, &doneInt, &t.Status, &t.Tags, &dueStr, &startStr, &t.Timezone, &priority, &recurring, &rule, &interval, &t.OnComplete, &t.EstimateMinutes, &t.BlockMinutes, &snoozedStr, &t.Reminders, &notes, &createdStr, &completedStr); err != nil {
 		return Task{}, err
 	}
 	t.Done = doneInt == 1
@@ -809,6 +1101,20 @@ func scanTask(scanner rowScanner) (Task, error) {
 			t.Start = sql.NullTime{Time: parsed, Valid: true}
 		}
 	}
+	if snoozedStr.Valid {
+		parsed := parseTimeWithFallback(snoozedStr.String)
+		if !parsed.IsZero() {
+			t.SnoozedUntil = sql.NullTime{Time: parsed, Valid: true}
+		}
+	}
+	// Due and start read as wall clock in the task's own zone.
+	loc := Location(t.Timezone)
+	if t.Due.Valid {
+		t.Due.Time = t.Due.Time.In(loc)
+	}
+	if t.Start.Valid {
+		t.Start.Time = t.Start.Time.In(loc)
+	}
 	if created, err := time.Parse(time.RFC3339, createdStr); err == nil {
 		t.CreatedAt = created
 	}
@@ -903,6 +1209,10 @@ func parseTimeWithFallback(val string) time.Time {
 }
 
 func sqliteDSN(path string) string {
+	return sqliteDSNWithMode(path, "rwc")
+}
+
+func sqliteDSNWithMode(path, mode string) string {
 	if strings.HasPrefix(path, "file:") {
 		return path
 	}
@@ -915,7 +1225,7 @@ func sqliteDSN(path string) string {
 		Path:   path,
 	}
 	q := u.Query()
-	q.Set("mode", "rwc")
+	q.Set("mode", mode)
 	q.Set("_pragma", "busy_timeout(5000)")
 	u.RawQuery = q.Encode()
 	return u.String()
@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TimeEntry struct {
+	ID     int
+	TaskID int
+	Title  string
+	Topics []string
+	Tags   string
+	Start  time.Time
+	End    sql.NullTime
+}
+
+func (e TimeEntry) Running() bool {
+	return !e.End.Valid
+}
+
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	if e.End.Valid {
+		return e.End.Time.Sub(e.Start)
+	}
+	return now.Sub(e.Start)
+}
+
+type TimeTotal struct {
+	Key      string
+	Duration time.Duration
+}
+
+type TimeSummary struct {
+	From    time.Time
+	To      time.Time
+	Total   time.Duration
+	ByDay   []TimeTotal
+	ByTopic []TimeTotal
+	ByTag   []TimeTotal
+}
+
+func (s *Store) ensureTimeEntriesTable() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS time_entries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	started_at TEXT NOT NULL,
+	ended_at TEXT DEFAULT NULL
+);`); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_time_entries_started_at ON time_entries(started_at);`)
+	return err
+}
+
+// RunningTimer returns the open time entry, if any. Only one can be open.
+func (s *Store) RunningTimer() (TimeEntry, bool, error) {
+	entries, err := s.queryTimeEntries(`WHERE e.ended_at IS NULL`)
+	if err != nil || len(entries) == 0 {
+		return TimeEntry{}, false, err
+	}
+	return entries[len(entries)-1], true, nil
+}
+
+// StartTimer stops whatever timer is running and starts one on taskID. The
+// stopped entry is returned so callers can report it.
+func (s *Store) StartTimer(taskID int) (TimeEntry, bool, error) {
+	stopped, wasRunning, err := s.StopTimer()
+	if err != nil {
+		return TimeEntry{}, false, err
+	}
+	_, err = s.db.Exec(`INSERT INTO time_entries (task_id, started_at) VALUES (?, ?);`, taskID, formatEntryTime(time.Now()))
+	return stopped, wasRunning, err
+}
+
+func (s *Store) StopTimer() (TimeEntry, bool, error) {
+	running, ok, err := s.RunningTimer()
+	if err != nil || !ok {
+		return TimeEntry{}, false, err
+	}
+	now := time.Now()
+	if _, err := s.db.Exec(`UPDATE time_entries SET ended_at = ? WHERE ended_at IS NULL;`, formatEntryTime(now)); err != nil {
+		return TimeEntry{}, false, err
+	}
+	running.End = sql.NullTime{Time: now.UTC().Truncate(time.Second), Valid: true}
+	return running, true, nil
+}
+
+func (s *Store) stopTimerFor(taskID int) error {
+	_, err := s.db.Exec(`UPDATE time_entries SET ended_at = ? WHERE ended_at IS NULL AND task_id = ?;`, formatEntryTime(time.Now()), taskID)
+	return err
+}
+
+// TimeEntries returns entries overlapping [from, to), oldest first.
+func (s *Store) TimeEntries(from, to time.Time) ([]TimeEntry, error) {
+	if !to.After(from) {
+		return nil, errors.New("empty time range")
+	}
+	return s.queryTimeEntries(`WHERE e.started_at < ? AND (e.ended_at IS NULL OR e.ended_at > ?)`,
+		formatEntryTime(to), formatEntryTime(from))
+}
+
+func (s *Store) queryTimeEntries(where string, args ...any) ([]TimeEntry, error) {
+	rows, err := s.db.Query(`SELECT e.id, e.task_id, e.started_at, e.ended_at, COALESCE(t.title, ''), COALESCE(t.tags, '')
+FROM time_entries e LEFT JOIN tasks t ON t.id = e.task_id `+where+` ORDER BY e.started_at, e.id;`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []TimeEntry
+	var ids []int
+	for rows.Next() {
+		var e TimeEntry
+		var started string
+		var ended sql.NullString
+		if err := rows.Scan(&e.ID, &e.TaskID, &started, &ended, &e.Title, &e.Tags); err != nil {
+			return nil, err
+		}
+		e.Start = parseTimeWithFallback(started)
+		if ended.Valid && ended.String != "" {
+			e.End = sql.NullTime{Time: parseTimeWithFallback(ended.String), Valid: true}
+		}
+		if e.Title == "" {
+			e.Title = fmt.Sprintf("(deleted #%d)", e.TaskID)
+		}
+		entries = append(entries, e)
+		ids = append(ids, e.TaskID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return entries, nil
+	}
+	topics, err := s.fetchTopicsForTasks(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Topics = topics[entries[i].TaskID]
+	}
+	return entries, nil
+}
+
+// SummarizeTime totals entries clipped to [from, to), splitting entries that
+// cross midnight between days. Time on a task with several topics or tags
+// counts towards each of them.
+func SummarizeTime(entries []TimeEntry, from, to, now time.Time) TimeSummary {
+	sum := TimeSummary{From: from, To: to}
+	byDay := map[string]time.Duration{}
+	byTopic := map[string]time.Duration{}
+	byTag := map[string]time.Duration{}
+	for _, e := range entries {
+		start := maxTime(e.Start, from)
+		end := now
+		if e.End.Valid {
+			end = e.End.Time
+		}
+		end = minTime(end, to)
+		if !end.After(start) {
+			continue
+		}
+		d := end.Sub(start)
+		sum.Total += d
+		for cur := start; cur.Before(end); {
+			local := cur.In(from.Location())
+			next := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, from.Location())
+			next = minTime(next, end)
+			byDay[local.Format("2006-01-02")] += next.Sub(cur)
+			cur = next
+		}
+		topics := e.Topics
+		if len(topics) == 0 {
+			topics = []string{"(no topic)"}
+		}
+		for _, topic := range topics {
+			byTopic[topic] += d
+		}
+		tags := splitTagList(e.Tags)
+		if len(tags) == 0 {
+			tags = []string{"(no tag)"}
+		}
+		for _, tag := range tags {
+			byTag[tag] += d
+		}
+	}
+	sum.ByDay = sortedTotals(byDay, false)
+	sum.ByTopic = sortedTotals(byTopic, true)
+	sum.ByTag = sortedTotals(byTag, true)
+	return sum
+}
+
+func WriteTimeCSV(w io.Writer, entries []TimeEntry, now time.Time) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "task_id", "title", "topics", "tags", "start", "end", "minutes"}); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		end := ""
+		if e.End.Valid {
+			end = e.End.Time.Local().Format(time.RFC3339)
+		}
+		record := []string{
+			fmt.Sprint(e.ID),
+			fmt.Sprint(e.TaskID),
+			e.Title,
+			strings.Join(e.Topics, ";"),
+			strings.Join(splitTagList(e.Tags), ";"),
+			e.Start.Local().Format(time.RFC3339),
+			end,
+			fmt.Sprintf("%.1f", e.Duration(now).Minutes()),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func FormatClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+func sortedTotals(m map[string]time.Duration, byDuration bool) []TimeTotal {
+	totals := make([]TimeTotal, 0, len(m))
+	for k, d := range m {
+		totals = append(totals, TimeTotal{Key: k, Duration: d})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if byDuration && totals[i].Duration != totals[j].Duration {
+			return totals[i].Duration > totals[j].Duration
+		}
+		return totals[i].Key < totals[j].Key
+	})
+	return totals
+}
+
+func splitTagList(raw string) []string {
+	var tags []string
+	for _, tag := range strings.Split(raw, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func formatEntryTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// ParseTimeRange turns "week" (the default), "today", "month", "7d" (last
+// seven days including today), "2024-05-01" or "2024-05-01 2024-05-31"
+// (inclusive) into a half-open local range.
+func ParseTimeRange(arg string, now time.Time) (time.Time, time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	fields := strings.Fields(strings.ToLower(arg))
+	if len(fields) == 0 {
+		fields = []string{"week"}
+	}
+	if len(fields) == 1 {
+		switch f := fields[0]; {
+		case f == "today":
+			return today, today.AddDate(0, 0, 1), nil
+		case f == "yesterday":
+			return today.AddDate(0, 0, -1), today, nil
+		case f == "week":
+			monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+			return monday, monday.AddDate(0, 0, 7), nil
+		case f == "month":
+			first := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+			return first, first.AddDate(0, 1, 0), nil
+		case strings.HasSuffix(f, "d"):
+			if days, err := strconv.Atoi(strings.TrimSuffix(f, "d")); err == nil && days > 0 {
+				return today.AddDate(0, 0, 1-days), today.AddDate(0, 0, 1), nil
+			}
+		}
+	}
+	if len(fields) > 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid range %q", arg)
+	}
+	from, err := time.ParseInLocation("2006-01-02", fields[0], now.Location())
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid range %q: use week, month, today, 7d or YYYY-MM-DD [YYYY-MM-DD]", arg)
+	}
+	to := from
+	if len(fields) == 2 {
+		if to, err = time.ParseInLocation("2006-01-02", fields[1], now.Location()); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date %q", fields[1])
+		}
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("range ends before it starts")
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
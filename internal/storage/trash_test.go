@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestDeleteStopsRunningTimer(t *testing.T) {
+	s := openTestStore(t)
+	trashed, err := s.CreateTask(Task{Title: "trashed", Timezone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.StartTimer(trashed); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteTask(trashed); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	other, err := s.CreateTask(Task{Title: "other", Timezone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.StartTimer(other); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := s.ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RestoreTrash(entries); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	var running int
+	if err := s.db.QueryRow(`SELECT COUNT(1) FROM time_entries WHERE ended_at IS NULL;`).Scan(&running); err != nil {
+		t.Fatal(err)
+	}
+	if running != 1 {
+		t.Fatalf("%d running timers after restore, want 1", running)
+	}
+	timer, ok, err := s.RunningTimer()
+	if err != nil || !ok || timer.TaskID != other {
+		t.Fatalf("running timer = %+v, %v, %v; want task %d", timer, ok, err, other)
+	}
+}
@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// Zone names must load on machines without a zoneinfo database too.
+	_ "time/tzdata"
+)
+
+var (
+	zoneMu    sync.Mutex
+	zoneCache = map[string]*time.Location{}
+)
+
+// Location loads an IANA zone name. An empty or unknown name is the local
+// zone, which is what tasks without a timezone are kept in.
+func Location(name string) *time.Location {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.Local
+	}
+	zoneMu.Lock()
+	defer zoneMu.Unlock()
+	if loc, ok := zoneCache[name]; ok {
+		return loc
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = time.Local
+	}
+	zoneCache[name] = loc
+	return loc
+}
+
+// LocalZoneName is the IANA name of the machine's zone, from $TZ or the
+// /etc/localtime link, or "" when it cannot be told.
+func LocalZoneName() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		if _, err := time.LoadLocation(tz); err == nil && tz != "Local" {
+			return tz
+		}
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if i := strings.LastIndex(target, "zoneinfo/"); i >= 0 {
+			return target[i+len("zoneinfo/"):]
+		}
+	}
+	if name := time.Local.String(); name != "Local" && name != "" {
+		return name
+	}
+	return ""
+}
+
+// DefaultZone is the zone a task without one is stored in: ours by name, or
+// a fixed zone for our current offset when the name cannot be told.
+func DefaultZone() string {
+	if name := LocalZoneName(); name != "" {
+		return name
+	}
+	_, offset := time.Now().Zone()
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	if name, ok := ZoneFromOffset(fmt.Sprintf("UTC%s%02d:%02d", sign, offset/3600, offset%3600/60)); ok {
+		return name
+	}
+	return "UTC"
+}
+
+// stampZone gives every stored task a zone name; an empty one would read as
+// a legacy row in later exports and trash snapshots.
+func stampZone(zone string) string {
+	if zone = strings.TrimSpace(zone); zone != "" {
+		return zone
+	}
+	return DefaultZone()
+}
+
+// zoneFormatVersion is the export and trash format that first stored zone
+// names and real instants; older payloads go through normalizeLegacyZone.
+const zoneFormatVersion = 2
+
+var offsetRe = regexp.MustCompile(`(?i)^(?:utc|gmt)?\s*([+-])\s*(\d{1,2})(?::?(\d{2}))?$`)
+
+// Zones for the offsets that are not whole hours; Etc/GMT only has those.
+var fractionalZones = map[int]string{
+	-(9*60 + 30): "Pacific/Marquesas",
+	-(3*60 + 30): "America/St_Johns",
+	3*60 + 30:    "Asia/Tehran",
+	4*60 + 30:    "Asia/Kabul",
+	5*60 + 30:    "Asia/Kolkata",
+	5*60 + 45:    "Asia/Kathmandu",
+	6*60 + 30:    "Asia/Yangon",
+	8*60 + 45:    "Australia/Eucla",
+	9*60 + 30:    "Australia/Darwin",
+	10*60 + 30:   "Australia/Lord_Howe",
+	12*60 + 45:   "Pacific/Chatham",
+}
+
+// ZoneFromOffset names a zone for a fixed offset such as "UTC+09:00", as
+// older versions stored. The local zone wins when its offset matches now;
+// otherwise whole hours become Etc/GMT zones (whose signs are inverted).
+func ZoneFromOffset(v string) (string, bool) {
+	m := offsetRe.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return "", false
+	}
+	hours, _ := strconv.Atoi(m[2])
+	mins := 0
+	if m[3] != "" {
+		mins, _ = strconv.Atoi(m[3])
+	}
+	if hours > 14 || mins > 59 {
+		return "", false
+	}
+	offset := hours*60 + mins
+	if m[1] == "-" {
+		offset = -offset
+	}
+	if _, local := time.Now().Zone(); local == offset*60 {
+		if name := LocalZoneName(); name != "" {
+			return name, true
+		}
+	}
+	switch {
+	case offset == 0:
+		return "UTC", true
+	case mins != 0:
+		name, ok := fractionalZones[offset]
+		return name, ok
+	case offset > 0:
+		return fmt.Sprintf("Etc/GMT-%d", hours), true
+	default:
+		return fmt.Sprintf("Etc/GMT+%d", hours), true
+	}
+}
+
+// migrateOffsetZones runs once. Older versions stored a task's timezone as
+// a fixed offset and its due and start as the wall clock marked UTC; the
+// zone becomes a name and the times the instants they meant in it.
+func (s *Store) migrateOffsetZones() error {
+	if last, err := s.lastMaintenance("zone_names"); err != nil || !last.IsZero() {
+		return err
+	}
+	type row struct {
+		id         int
+		due, start sql.NullString
+		zone       string
+	}
+	rows, err := s.db.Query(`SELECT id, due, start_at, COALESCE(timezone, '') FROM tasks;`)
+	if err != nil {
+		return err
+	}
+	var tasks []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.due, &r.start, &r.zone); err != nil {
+			rows.Close()
+			return err
+		}
+		tasks = append(tasks, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, r := range tasks {
+		zone := strings.TrimSpace(r.zone)
+		if name, ok := ZoneFromOffset(zone); ok {
+			zone = name
+		} else if _, err := time.LoadLocation(zone); err != nil || zone == "Local" {
+			zone = ""
+		}
+		if zone == "" {
+			zone = DefaultZone()
+		}
+		loc := Location(zone)
+		if _, err := tx.Exec(`UPDATE tasks SET timezone = ?, due = ?, start_at = ? WHERE id = ?;`,
+			zone, wallIn(r.due, loc), wallIn(r.start, loc), r.id); err != nil {
+			return err
+		}
+		if err := rewriteWallTimes(tx, `SELECT id, due FROM completions WHERE task_id = ?;`, `UPDATE completions SET due = ? WHERE id = ?;`, r.id, loc); err != nil {
+			return err
+		}
+		for _, column := range []string{"prev_due", "prev_start"} {
+			if err := rewriteWallTimes(tx, `SELECT id, `+column+` FROM occurrence_overrides WHERE task_id = ?;`,
+				`UPDATE occurrence_overrides SET `+column+` = ? WHERE id = ?;`, r.id, loc); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return s.markMaintenance("zone_names", time.Now())
+}
+
+func rewriteWallTimes(tx *sql.Tx, query, update string, taskID int, loc *time.Location) error {
+	rows, err := tx.Query(query, taskID)
+	if err != nil {
+		return err
+	}
+	values := map[int]sql.NullString{}
+	for rows.Next() {
+		var id int
+		var v sql.NullString
+		if err := rows.Scan(&id, &v); err != nil {
+			rows.Close()
+			return err
+		}
+		values[id] = v
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, v := range values {
+		if _, err := tx.Exec(update, wallIn(v, loc), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wallIn reads a stored time's UTC fields as the wall clock in loc.
+func wallIn(v sql.NullString, loc *time.Location) sql.NullString {
+	if !v.Valid {
+		return v
+	}
+	t := parseTimeWithFallback(v.String)
+	if t.IsZero() {
+		return v
+	}
+	return nullTimeToString(wallTime(sql.NullTime{Time: t, Valid: true}, loc))
+}
+
+func wallTime(v sql.NullTime, loc *time.Location) sql.NullTime {
+	if !v.Valid {
+		return v
+	}
+	t := v.Time.UTC()
+	v.Time = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+	return v
+}
+
+// normalizeLegacyZone brings a task from an export or trash snapshot older
+// than zoneFormatVersion to the current form, as migrateOffsetZones does for
+// the database. Those stored an offset, or no timezone at all, and the wall
+// clock marked UTC. Payloads without a version may also come from a release
+// that already had zone names, so a task with a name is left alone.
+func normalizeLegacyZone(version int, t *Task, h *taskHistory) {
+	if version >= zoneFormatVersion {
+		return
+	}
+	zone := strings.TrimSpace(t.Timezone)
+	if name, ok := ZoneFromOffset(zone); ok {
+		zone = name
+	} else if zone != "" {
+		return
+	}
+	if zone == "" {
+		zone = DefaultZone()
+	}
+	loc := Location(zone)
+	t.Timezone = zone
+	t.Due, t.Start = wallTime(t.Due, loc), wallTime(t.Start, loc)
+	for i := range t.Overrides {
+		o := &t.Overrides[i]
+		o.PrevDue, o.PrevStart = wallTime(o.PrevDue, loc), wallTime(o.PrevStart, loc)
+	}
+	if h != nil {
+		for i := range h.Completions {
+			h.Completions[i].Due = wallTime(h.Completions[i].Due, loc)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+// inSeoul runs the test with Asia/Seoul as our zone, where a wall clock read
+// as UTC is nine hours off.
+func inSeoul(t *testing.T) {
+	t.Helper()
+	loc, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		t.Skipf("no zone data: %v", err)
+	}
+	t.Setenv("TZ", "Asia/Seoul")
+	local := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = local })
+}
+
+func TestTrashRestoreKeepsDueWithoutZone(t *testing.T) {
+	inSeoul(t)
+	s := openTestStore(t)
+	due := time.Date(2026, 5, 4, 1, 0, 0, 0, time.UTC)
+	id, err := s.CreateTask(Task{Title: "no zone", Due: sql.NullTime{Time: due, Valid: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := s.TaskByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Timezone != "Asia/Seoul" {
+		t.Errorf("new task stored in zone %q, want Asia/Seoul", created.Timezone)
+	}
+	if err := s.DeleteTask(id); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := s.ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RestoreTrash(entries); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := s.TaskByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored.Due.Time.Equal(due) {
+		t.Errorf("due after trash round trip = %s, want %s", restored.Due.Time.UTC(), due)
+	}
+}
+
+func TestImportConvertsOnlyLegacyZones(t *testing.T) {
+	inSeoul(t)
+	wall := sql.NullTime{Time: time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC), Valid: true}
+	tests := []struct {
+		name    string
+		version int
+		zone    string
+		want    time.Time
+	}{
+		{"legacy offset", 1, "UTC+05:30", time.Date(2026, 5, 4, 3, 30, 0, 0, time.UTC)},
+		{"legacy without zone", 1, "", time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)},
+		{"legacy with a zone name", 1, "Europe/Berlin", wall.Time},
+		{"current without zone", exportVersion, "", wall.Time},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openTestStore(t)
+			file := ExportFile{Version: tt.version, Tasks: []Task{{ID: 1, Title: tt.name, Timezone: tt.zone, Due: wall}}}
+			if _, err := s.Import(file); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.TaskByID(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Due.Time.Equal(tt.want) {
+				t.Errorf("due = %s, want %s", got.Due.Time.UTC(), tt.want)
+			}
+			if got.Timezone == "" {
+				t.Error("imported task has no zone")
+			}
+		})
+	}
+}
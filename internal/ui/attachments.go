@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/storage"
+)
+
+func (m Model) attachToCurrent(arg string) (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	task, ok := m.currentTask()
+	if !ok {
+		m.status = "Select a task to attach to"
+		return m, nil
+	}
+	managed := m.cfg.Attachments.Copy
+	for {
+		switch {
+		case strings.HasPrefix(arg, "--copy"):
+			managed = true
+			arg = strings.TrimSpace(strings.TrimPrefix(arg, "--copy"))
+			continue
+		case strings.HasPrefix(arg, "--link"):
+			managed = false
+			arg = strings.TrimSpace(strings.TrimPrefix(arg, "--link"))
+			continue
+		}
+		break
+	}
+	target, label := splitAttachArg(arg)
+	if target == "" {
+		m.status = "usage: :attach [--copy|--link] <path-or-url> [label]"
+		return m, nil
+	}
+	a, err := m.store.AddAttachment(task.ID, target, label, managed)
+	if err != nil {
+		m.status = fmt.Sprintf("attach failed: %v", err)
+		return m, nil
+	}
+	if err := m.reloadTasks(); err != nil {
+		m.status = fmt.Sprintf("reload failed: %v", err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("Attached %s to #%d", a.Name(), task.ID)
+	if a.Managed {
+		m.status += " (copied)"
+	}
+	return m, nil
+}
+
+func (m Model) detachFromCurrent(arg string) (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	task, ok := m.currentTask()
+	if !ok {
+		m.status = "Select a task first"
+		return m, nil
+	}
+	a, err := pickAttachment(task, arg)
+	if err != nil {
+		m.status = err.Error()
+		return m, nil
+	}
+	if err := m.store.RemoveAttachment(a); err != nil {
+		m.status = fmt.Sprintf("detach failed: %v", err)
+		return m, nil
+	}
+	if err := m.reloadTasks(); err != nil {
+		m.status = fmt.Sprintf("reload failed: %v", err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("Detached %s from #%d", a.Name(), task.ID)
+	return m, nil
+}
+
+func (m *Model) reloadTasks() error {
+	tasks, err := m.store.FetchTasks()
+	if err != nil {
+		return err
+	}
+	m.tasks = tasks
+	m.sortTasks()
+	m.cursor = clampCursor(m.cursor, len(m.visibleItems()))
+	return nil
+}
+
+// splitAttachArg splits `path label words` where the path may be quoted.
+func splitAttachArg(arg string) (string, string) {
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(arg, `"`) {
+		if end := strings.Index(arg[1:], `"`); end >= 0 {
+			return arg[1 : end+1], strings.TrimSpace(arg[end+2:])
+		}
+	}
+	target, label, _ := strings.Cut(arg, " ")
+	return target, strings.TrimSpace(label)
+}
+
+func pickAttachment(task storage.Task, arg string) (storage.Attachment, error) {
+	if len(task.Attachments) == 0 {
+		return storage.Attachment{}, fmt.Errorf("#%d has no attachments", task.ID)
+	}
+	n := 1
+	if arg = strings.TrimSpace(arg); arg != "" {
+		v, err := strconv.Atoi(arg)
+		if err != nil {
+			return storage.Attachment{}, fmt.Errorf("attachment number expected, got %q", arg)
+		}
+		n = v
+	}
+	if n < 1 || n > len(task.Attachments) {
+		return storage.Attachment{}, fmt.Errorf("attachment %d out of range (1-%d)", n, len(task.Attachments))
+	}
+	return task.Attachments[n-1], nil
+}
+
+func (m Model) openAttachment(task storage.Task, arg string) (tea.Model, tea.Cmd) {
+	a, err := pickAttachment(task, arg)
+	if err != nil {
+		m.status = err.Error()
+		return m, nil
+	}
+	location, err := m.store.AttachmentLocation(a)
+	if err == nil {
+		err = runOpener(m.cfg.Attachments.Opener, location)
+	}
+	if err != nil {
+		m.status = fmt.Sprintf("open failed: %v", err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("Opened %s", a.Name())
+	return m, nil
+}
+
+// runOpener starts the opener detached; "{}" in the command is replaced by
+// the location, otherwise the location is appended.
+func runOpener(opener, location string) error {
+	parts := strings.Fields(opener)
+	if len(parts) == 0 {
+		return errors.New("attachments.opener is empty")
+	}
+	replaced := false
+	for i, p := range parts {
+		if strings.Contains(p, "{}") {
+			parts[i] = strings.ReplaceAll(p, "{}", location)
+			replaced = true
+		}
+	}
+	if !replaced {
+		parts = append(parts, location)
+	}
+	cmd := exec.Command(parts[0], parts[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+func (m Model) attachmentLines(task storage.Task) []string {
+	lines := make([]string, 0, len(task.Attachments))
+	for i, a := range task.Attachments {
+		where := a.Target
+		if a.Managed {
+			where = "managed: " + a.Target
+		}
+		line := fmt.Sprintf("%d) %s", i+1, a.Name())
+		if a.Label != "" || a.Managed {
+			line += m.styles.Muted.Render(" — " + where)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
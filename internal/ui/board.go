@@ -0,0 +1,367 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"bada/internal/storage"
+)
+
+const boardNoTopic = "(no topic)"
+
+type boardColumn struct {
+	name  string
+	tasks []storage.Task
+}
+
+func (m Model) enterBoardView(arg string) (tea.Model, tea.Cmd) {
+	m.input.Blur()
+	group := strings.ToLower(strings.TrimSpace(arg))
+	if group == "" {
+		group = strings.ToLower(strings.TrimSpace(m.cfg.Board.GroupBy))
+	}
+	switch group {
+	case "", "state", "status":
+		m.boardByTopic = false
+	case "topic", "topics":
+		m.boardByTopic = true
+	default:
+		m.mode = modeList
+		m.status = fmt.Sprintf("unknown board grouping %q: use state or topic", arg)
+		return m, nil
+	}
+	m.boardScope = ""
+	if !m.boardByTopic && m.currentTopic != "" && !isSpecialTopic(m.currentTopic) {
+		m.boardScope = m.currentTopic
+	}
+	m.boardCol, m.boardRow = 0, 0
+	if t, ok := m.currentTask(); ok {
+		m.focusBoardCard(t.ID)
+	}
+	m.mode = modeBoard
+	m.status = "Board: h/l columns • j/k cards • H/L move card • tab regroup • esc close"
+	return m, nil
+}
+
+// boardColumns groups the tasks by workflow state (every state gets a
+// column) or by topic (following :filter, with a column for loose tasks).
+// Snoozed tasks stay off the board.
+func (m Model) boardColumns() []boardColumn {
+	if !m.boardByTopic {
+		cols := make([]boardColumn, len(m.cfg.States))
+		for i, st := range m.cfg.States {
+			cols[i].name = st.Name
+		}
+		for _, t := range m.tasks {
+			if m.boardScope != "" && !taskHasTopic(t, m.boardScope) || isSnoozed(t, time.Now()) {
+				continue
+			}
+			if _, idx := m.taskState(t); idx < len(cols) {
+				cols[idx].tasks = append(cols[idx].tasks, t)
+			}
+		}
+		return cols
+	}
+	topics := m.sortedTopics()
+	cols := make([]boardColumn, 0, len(topics)+1)
+	for _, topic := range topics {
+		col := boardColumn{name: topic}
+		for _, t := range m.tasks {
+			if taskHasTopic(t, topic) && m.passesFilter(t) {
+				col.tasks = append(col.tasks, t)
+			}
+		}
+		cols = append(cols, col)
+	}
+	loose := boardColumn{name: boardNoTopic}
+	for _, t := range m.tasks {
+		if len(t.Topics) == 0 && m.passesFilter(t) {
+			loose.tasks = append(loose.tasks, t)
+		}
+	}
+	return append(cols, loose)
+}
+
+func (m Model) currentBoardCard() (storage.Task, bool) {
+	cols := m.boardColumns()
+	if m.boardCol < 0 || m.boardCol >= len(cols) {
+		return storage.Task{}, false
+	}
+	tasks := cols[m.boardCol].tasks
+	if m.boardRow < 0 || m.boardRow >= len(tasks) {
+		return storage.Task{}, false
+	}
+	return tasks[m.boardRow], true
+}
+
+// focusBoardCard puts the cursor on the task's card, preferring the current
+// column when a task sits in several topics.
+func (m *Model) focusBoardCard(id int) {
+	cols := m.boardColumns()
+	order := make([]int, 0, len(cols))
+	if m.boardCol >= 0 && m.boardCol < len(cols) {
+		order = append(order, m.boardCol)
+	}
+	for i := range cols {
+		if i != m.boardCol {
+			order = append(order, i)
+		}
+	}
+	for _, c := range order {
+		for r, t := range cols[c].tasks {
+			if t.ID == id {
+				m.boardCol, m.boardRow = c, r
+				return
+			}
+		}
+	}
+}
+
+func (m Model) wipLimit(column string) int {
+	return m.cfg.Board.WIPLimits[column]
+}
+
+func (m Model) updateBoardMode(key string) (tea.Model, tea.Cmd) {
+	cols := m.boardColumns()
+	rows := func() int {
+		if m.boardCol < len(cols) {
+			return len(cols[m.boardCol].tasks)
+		}
+		return 0
+	}
+	switch key {
+	case "esc", m.cfg.Keys.Quit, "q":
+		m.mode = modeList
+		m.status = "Board closed"
+	case "h", "left":
+		m.boardCol = clampInt(m.boardCol-1, 0, len(cols)-1)
+		m.boardRow = clampCursor(m.boardRow, rows())
+	case "l", "right":
+		m.boardCol = clampInt(m.boardCol+1, 0, len(cols)-1)
+		m.boardRow = clampCursor(m.boardRow, rows())
+	case m.cfg.Keys.Up, "up":
+		if m.boardRow > 0 {
+			m.boardRow--
+		}
+	case m.cfg.Keys.Down, "down":
+		m.boardRow = clampCursor(m.boardRow+1, rows())
+	case "g", "home":
+		m.boardRow = 0
+	case "G", "end":
+		m.boardRow = clampCursor(rows()-1, rows())
+	case "H", "<":
+		return m.moveBoardCard(-1)
+	case "L", ">":
+		return m.moveBoardCard(1)
+	case "tab":
+		m.boardByTopic = !m.boardByTopic
+		m.boardScope = ""
+		card, ok := m.currentBoardCard()
+		m.boardCol, m.boardRow = 0, 0
+		if ok {
+			m.focusBoardCard(card.ID)
+		}
+		m.status = "Board by state"
+		if m.boardByTopic {
+			m.status = "Board by topic"
+		}
+	case m.cfg.Keys.Toggle:
+		card, ok := m.currentBoardCard()
+		if !ok {
+			return m, nil
+		}
+		next, cmd := m.toggleTaskState(card)
+		m = next.(Model)
+		m.focusBoardCard(card.ID)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// moveBoardCard shifts the card to the neighbouring column: a new state on
+// the state board, or swapping the column's topic on the topic board.
+func (m Model) moveBoardCard(delta int) (tea.Model, tea.Cmd) {
+	card, ok := m.currentBoardCard()
+	if !ok {
+		return m, nil
+	}
+	cols := m.boardColumns()
+	target := m.boardCol + delta
+	if target < 0 || target >= len(cols) {
+		return m, nil
+	}
+	from, to := cols[m.boardCol].name, cols[target].name
+	_, err := m.changeTask(card.ID, func(t *storage.Task) {
+		if !m.boardByTopic {
+			applyState(t, m.cfg.States[target])
+			return
+		}
+		topics := make([]string, 0, len(t.Topics)+1)
+		for _, topic := range t.Topics {
+			if topic != from {
+				topics = append(topics, topic)
+			}
+		}
+		if to != boardNoTopic {
+			topics = append(topics, to)
+		}
+		t.Topics = topics
+	})
+	if err != nil {
+		m.status = fmt.Sprintf("move failed: %v", err)
+		return m, nil
+	}
+	if err := m.reloadTasks(); err != nil {
+		m.status = fmt.Sprintf("reload failed: %v", err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("#%d → %s", card.ID, to)
+	m.boardCol = target
+	m.focusBoardCard(card.ID)
+	if limit := m.wipLimit(to); limit > 0 {
+		if n := len(m.boardColumns()[target].tasks); n > limit {
+			m.status += fmt.Sprintf(" (over WIP limit %d/%d)", n, limit)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) boardHeader() string {
+	group := "state"
+	if m.boardByTopic {
+		group = "topic"
+	}
+	title := fmt.Sprintf("# Board by %s", group)
+	if m.boardScope != "" {
+		title += " in " + m.boardScope
+	}
+	return m.renderListBanner() + "\n\n" + m.styles.Accent.Render(title) + "\n\n"
+}
+
+func (m Model) boardFooter() string {
+	return fmt.Sprintf("h/l column • j/k card • H/L move card • tab state/topic • %s toggle done • esc/q close", m.cfg.Keys.Toggle)
+}
+
+func (m Model) boardColumnTitle(col boardColumn) string {
+	n := len(col.tasks)
+	label := fmt.Sprintf("%s (%d)", col.name, n)
+	limit := m.wipLimit(col.name)
+	if limit <= 0 {
+		return m.styles.Heading.Render(label)
+	}
+	label = fmt.Sprintf("%s (%d/%d)", col.name, n, limit)
+	switch {
+	case n > limit:
+		return m.styles.Danger.Render(label)
+	case n == limit:
+		return m.styles.Warning.Render(label)
+	}
+	return m.styles.Heading.Render(label)
+}
+
+// boardCard renders a card as a title line plus a line of badges, which is
+// left out when the task has none.
+func (m Model) boardCard(t storage.Task, width int, selected bool) []string {
+	symbol := m.stateSymbol(t, true)
+	title := truncateTextWidth(fmt.Sprintf("#%d %s", t.ID, t.Title), width-lipgloss.Width(symbol)-1)
+	title = m.stateSymbol(t, selected) + " " + title
+	badges := m.taskBadges(t)
+	if t.Priority > 0 {
+		badges = append([]taskBadge{{text: fmt.Sprintf("P%d", t.Priority), style: m.styles.Accent}}, badges...)
+	}
+	if label := formatEstimate(t.EstimateMinutes); label != "" {
+		badges = append(badges, taskBadge{text: "~" + label, style: m.styles.Muted})
+	}
+	fit := badges[:0:0]
+	used := 1
+	for _, b := range badges {
+		if used+lipgloss.Width(b.text)+1 > width {
+			break
+		}
+		used += lipgloss.Width(b.text) + 1
+		fit = append(fit, b)
+	}
+	lines := []string{title}
+	if len(fit) > 0 {
+		lines = append(lines, " "+renderBadges(fit, selected))
+	}
+	style := lipgloss.NewStyle().Width(width)
+	switch {
+	case selected:
+		style = m.styles.Selection.Width(width)
+	case t.Done:
+		style = m.styles.Done.Width(width)
+	}
+	for i, line := range lines {
+		lines[i] = style.Render(line)
+	}
+	return lines
+}
+
+func (m Model) renderBoardView() string {
+	var b strings.Builder
+	header := m.boardHeader()
+	b.WriteString(header)
+	cols := m.boardColumns()
+	width := m.width
+	if width <= 0 {
+		width = 100
+	}
+	const gap = 2
+	colWidth := max(18, (width-gap*(len(cols)-1))/max(len(cols), 1))
+	shown := max(1, (width+gap)/(colWidth+gap))
+	first := 0
+	if m.boardCol >= shown {
+		first = m.boardCol - shown + 1
+	}
+	last := min(len(cols), first+shown)
+	bodyMax := -1
+	if m.height > 0 {
+		bodyMax = max(1, m.height-1-countLines(header)-2-2)
+	}
+	blocks := make([]string, 0, last-first)
+	for c := first; c < last; c++ {
+		col := cols[c]
+		var lines []string
+		cursorLine := -1
+		for r, t := range col.tasks {
+			selected := c == m.boardCol && r == m.boardRow
+			if selected {
+				cursorLine = len(lines)
+			}
+			lines = append(lines, m.boardCard(t, colWidth, selected)...)
+		}
+		if len(col.tasks) == 0 {
+			lines = append(lines, m.styles.Muted.Render("(empty)"))
+		}
+		if bodyMax > 0 && len(lines) > bodyMax {
+			start := 0
+			if cursorLine >= bodyMax-1 {
+				start = cursorLine - bodyMax + 2
+			}
+			lines = lines[start:min(len(lines), start+bodyMax)]
+		}
+		title := m.boardColumnTitle(col)
+		rule := m.styles.Border.Render(strings.Repeat("─", colWidth))
+		if c == m.boardCol {
+			rule = m.styles.Accent.Render(strings.Repeat("━", colWidth))
+		}
+		block := lipgloss.NewStyle().Width(colWidth).Render(strings.Join(append([]string{title, rule}, lines...), "\n"))
+		blocks = append(blocks, block)
+		if c < last-1 {
+			blocks = append(blocks, strings.Repeat(" ", gap))
+		}
+	}
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, blocks...))
+	b.WriteString("\n\n")
+	footer := m.boardFooter()
+	if first > 0 || last < len(cols) {
+		footer = fmt.Sprintf("columns %d-%d of %d • %s", first+1, last, len(cols), footer)
+	}
+	b.WriteString(m.styles.Muted.Render(footer))
+	return b.String()
+}
@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/storage"
+)
+
+type checklistItem struct {
+	line    int
+	checked bool
+}
+
+// parseCheckbox splits a trimmed markdown list line like "- [x] text" into
+// its state and text.
+func parseCheckbox(trim string) (bool, string, bool) {
+	var rest string
+	switch {
+	case strings.HasPrefix(trim, "- "), strings.HasPrefix(trim, "* "), strings.HasPrefix(trim, "+ "):
+		rest = trim[2:]
+	default:
+		dot := strings.Index(trim, ". ")
+		if dot <= 0 {
+			return false, "", false
+		}
+		if _, err := strconv.Atoi(trim[:dot]); err != nil {
+			return false, "", false
+		}
+		rest = trim[dot+2:]
+	}
+	rest = strings.TrimLeft(rest, " ")
+	if len(rest) < 3 || rest[0] != '[' || rest[2] != ']' || (len(rest) > 3 && rest[3] != ' ') {
+		return false, "", false
+	}
+	switch rest[1] {
+	case ' ':
+		return false, strings.TrimSpace(rest[3:]), true
+	case 'x', 'X':
+		return true, strings.TrimSpace(rest[3:]), true
+	}
+	return false, "", false
+}
+
+// parseChecklist finds the checkbox items in notes, skipping code blocks.
+func parseChecklist(notes string) []checklistItem {
+	var items []checklistItem
+	inCode := false
+	for i, raw := range strings.Split(notes, "\n") {
+		trim := strings.TrimSpace(strings.TrimRight(raw, "\r"))
+		if strings.HasPrefix(trim, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		if checked, _, ok := parseCheckbox(trim); ok {
+			items = append(items, checklistItem{line: i, checked: checked})
+		}
+	}
+	return items
+}
+
+func checklistProgress(notes string) (int, int) {
+	items := parseChecklist(notes)
+	done := 0
+	for _, it := range items {
+		if it.checked {
+			done++
+		}
+	}
+	return done, len(items)
+}
+
+func checklistBadge(notes string) string {
+	done, total := checklistProgress(notes)
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[%d/%d]", done, total)
+}
+
+// toggleChecklistItem flips the n-th checkbox, leaving the rest of the
+// notes byte-for-byte intact.
+func toggleChecklistItem(notes string, n int) (string, bool) {
+	items := parseChecklist(notes)
+	if n < 0 || n >= len(items) {
+		return notes, false
+	}
+	lines := strings.Split(notes, "\n")
+	line := lines[items[n].line]
+	open := strings.Index(line, "[")
+	if open < 0 || open+2 >= len(line) {
+		return notes, false
+	}
+	mark := byte('x')
+	if items[n].checked {
+		mark = ' '
+	}
+	lines[items[n].line] = line[:open+1] + string(mark) + line[open+2:]
+	return strings.Join(lines, "\n"), true
+}
+
+func (m Model) moveChecklistCursor(delta int) (tea.Model, tea.Cmd) {
+	if m.note == nil {
+		return m, nil
+	}
+	items := parseChecklist(m.note.body)
+	if len(items) == 0 {
+		m.status = "No checklist items in this note"
+		return m, nil
+	}
+	if m.noteCheck < 0 {
+		m.noteCheck = 0
+	} else {
+		m.noteCheck = wrapIndex(m.noteCheck+delta, len(items))
+	}
+	m.scrollToChecklistCursor()
+	m.status = fmt.Sprintf("Item %d/%d: space to toggle", m.noteCheck+1, len(items))
+	return m, nil
+}
+
+func (m Model) toggleNoteChecklist() (tea.Model, tea.Cmd) {
+	if m.note == nil {
+		return m, nil
+	}
+	items := parseChecklist(m.note.body)
+	if len(items) == 0 {
+		m.status = "No checklist items in this note"
+		return m, nil
+	}
+	if m.noteCheck < 0 {
+		m.noteCheck = 0
+	}
+	m.noteCheck = clampCursor(m.noteCheck, len(items))
+	notes, ok := toggleChecklistItem(m.note.body, m.noteCheck)
+	if !ok {
+		return m, nil
+	}
+	switch m.note.target.kind {
+	case noteTask:
+		saved, err := m.changeTask(m.note.target.taskID, func(t *storage.Task) { t.Notes = notes })
+		if err != nil {
+			m.status = fmt.Sprintf("note save failed: %v", err)
+			return m, nil
+		}
+		notes = saved.Notes
+		m.applyTaskNoteLocal(m.note.target.taskID, notes)
+	case noteTopic:
+		if err := m.store.UpdateTopicNote(m.note.target.topic, notes); err != nil {
+			m.status = fmt.Sprintf("note save failed: %v", err)
+			return m, nil
+		}
+	}
+	m.note.body = notes
+	done, total := checklistProgress(notes)
+	m.status = fmt.Sprintf("Checklist %d/%d done", done, total)
+	return m, nil
+}
+
+// scrollToChecklistCursor keeps the highlighted item inside the note body.
+func (m *Model) scrollToChecklistCursor() {
+	_, line := m.renderMarkdownChecklist(m.note.body, m.noteCheck)
+	available := m.noteAvailableHeight()
+	if line < 0 || available <= 0 {
+		return
+	}
+	if line < m.noteScroll {
+		m.noteScroll = line
+	} else if line >= m.noteScroll+available {
+		m.noteScroll = line - available + 1
+	}
+	m.noteScroll = clampInt(m.noteScroll, 0, m.noteMaxScroll())
+}
@@ -0,0 +1,306 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/config"
+	"bada/internal/storage"
+)
+
+// builtinCommands is what command mode knows without [commands]; custom
+// commands cannot shadow them.
+var builtinCommands = []string{"agenda", "calendar", "config", "gantt", "help", "backup", "restore", "workspace", "move", "attach", "detach", "open", "timesheet", "focus", "filter", "board", "snooze", "reschedule", "habits", "save-view"}
+
+// commandTimeout stops a [commands] entry that hangs, so its result (and
+// the "Running" status) does not wait forever.
+const commandTimeout = 30 * time.Second
+
+type customCommandMsg struct {
+	name   string
+	output string
+	stdout []byte
+	err    error
+}
+
+type pagerState struct {
+	title  string
+	lines  []string
+	scroll int
+}
+
+func (m Model) customCommand(name string) (config.Command, bool) {
+	name = strings.ToLower(name)
+	for _, b := range builtinCommands {
+		if b == name {
+			return config.Command{}, false
+		}
+	}
+	c, ok := m.cfg.Commands[name]
+	return c, ok
+}
+
+func (m Model) customCommandNames() []string {
+	var names []string
+	for name := range m.cfg.Commands {
+		if _, ok := m.customCommand(name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m Model) commandTasks(input string) ([]storage.Task, error) {
+	switch input {
+	case "current":
+		if t, ok := m.currentTask(); ok {
+			return []storage.Task{t}, nil
+		}
+		return nil, errors.New("no task under the cursor")
+	case "selected":
+		if tasks := m.stateTargets(); len(tasks) > 0 {
+			return tasks, nil
+		}
+		return nil, errors.New("no task selected")
+	case "visible":
+		tasks := []storage.Task{}
+		for _, item := range m.visibleItems() {
+			if item.kind == itemTask {
+				tasks = append(tasks, item.task)
+			}
+		}
+		return tasks, nil
+	}
+	return nil, fmt.Errorf("unknown input %q: use current, selected or visible", input)
+}
+
+// runCustomCommand starts a [commands] entry in the background; args go to
+// the shell as $1, $2, ...
+func (m Model) runCustomCommand(name string, c config.Command, arg string) (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	if c.Output != "pager" && c.Output != "update" && c.Output != "none" {
+		m.status = fmt.Sprintf("%s: unknown output %q: use pager, update or none", name, c.Output)
+		return m, nil
+	}
+	tasks, err := m.commandTasks(c.Input)
+	if err != nil {
+		m.status = fmt.Sprintf("%s: %v", name, err)
+		return m, nil
+	}
+	data, err := json.Marshal(tasks)
+	if err != nil {
+		m.status = fmt.Sprintf("%s failed: %v", name, err)
+		return m, nil
+	}
+	args := append([]string{"-c", c.Run, name}, strings.Fields(arg)...)
+	env := append(os.Environ(), "BADA_COMMAND="+name, "BADA_WORKSPACE="+m.workspace)
+	m.status = fmt.Sprintf("Running :%s on %d task(s)...", name, len(tasks))
+	output := c.Output
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "sh", args...)
+		cmd.Env = env
+		// Children of sh may hold stdout open after sh is killed.
+		cmd.WaitDelay = time.Second
+		cmd.Stdin = bytes.NewReader(data)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s", commandTimeout)
+			} else if msg := firstLine(stderr.String()); msg != "" {
+				err = errors.New(msg)
+			}
+			return customCommandMsg{name: name, err: err}
+		}
+		return customCommandMsg{name: name, output: output, stdout: stdout.Bytes()}
+	}
+}
+
+func (m Model) handleCustomCommandResult(msg customCommandMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("%s failed: %v", msg.name, msg.err)
+		return m, nil
+	}
+	switch msg.output {
+	case "pager":
+		text := strings.TrimRight(string(msg.stdout), "\n")
+		if strings.TrimSpace(text) == "" {
+			m.status = fmt.Sprintf(":%s printed nothing", msg.name)
+			return m, nil
+		}
+		m.pager = &pagerState{title: ":" + msg.name, lines: strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n")}
+		m.mode = modePager
+		m.status = fmt.Sprintf(":%s output", msg.name)
+	case "update":
+		return m.applyCommandUpdates(msg.name, msg.stdout)
+	default:
+		m.status = fmt.Sprintf(":%s done", msg.name)
+		if line := firstLine(string(msg.stdout)); line != "" {
+			m.status = fmt.Sprintf(":%s: %s", msg.name, line)
+		}
+	}
+	return m, nil
+}
+
+// applyCommandUpdates reads a JSON array (or a single object) of partial
+// tasks in export format. "ID" picks the task and the other fields replace
+// its values; each change goes through the hooks like an edit would.
+func (m Model) applyCommandUpdates(name string, out []byte) (tea.Model, tea.Cmd) {
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		m.status = fmt.Sprintf(":%s changed nothing", name)
+		return m, nil
+	}
+	var updates []json.RawMessage
+	if out[0] == '{' {
+		updates = []json.RawMessage{out}
+	} else if err := json.Unmarshal(out, &updates); err != nil {
+		m.status = fmt.Sprintf("%s: invalid update JSON: %v", name, err)
+		return m, nil
+	}
+	changed := 0
+	var failure error
+	for i, raw := range updates {
+		var ref struct{ ID int }
+		if err := json.Unmarshal(raw, &ref); err != nil || ref.ID <= 0 {
+			failure = fmt.Errorf("update %d has no task ID", i+1)
+			break
+		}
+		var decodeErr error
+		_, err := m.changeTask(ref.ID, func(t *storage.Task) {
+			updated := *t
+			if decodeErr = json.Unmarshal(raw, &updated); decodeErr != nil {
+				return
+			}
+			if strings.TrimSpace(updated.Title) == "" {
+				decodeErr = errors.New("title cannot be empty")
+				return
+			}
+			updated.ID, updated.CreatedAt, updated.Attachments = t.ID, t.CreatedAt, t.Attachments
+			*t = updated
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			err = errors.New("no such task")
+		} else if err == nil {
+			err = decodeErr
+		}
+		if err != nil {
+			failure = fmt.Errorf("#%d: %v", ref.ID, err)
+			break
+		}
+		changed++
+	}
+	if err := m.reloadTasks(); err != nil {
+		m.status = fmt.Sprintf("reload failed: %v", err)
+		return m, nil
+	}
+	m.cursor = clampCursor(m.cursor, len(m.visibleItems()))
+	m.status = fmt.Sprintf(":%s updated %d task(s)", name, changed)
+	if failure != nil {
+		m.status = fmt.Sprintf("%s: %d task(s) updated, then failed: %v", name, changed, failure)
+	}
+	return m, nil
+}
+
+func (m Model) customCommandsHelp() string {
+	names := m.customCommandNames()
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nCustom Commands ([commands]):\n")
+	for _, name := range names {
+		c := m.cfg.Commands[name]
+		desc := c.Description
+		if desc == "" {
+			desc = c.Run
+		}
+		fmt.Fprintf(&b, "  :%-16s %s (%s → %s)\n", name, desc, c.Input, c.Output)
+	}
+	return b.String()
+}
+
+func (m Model) pagerBodyHeight() int {
+	if m.height <= 0 || m.pager == nil {
+		return 0
+	}
+	return max(1, m.height-1-countLines(m.pagerHeader())-countLines(m.pagerFooter()))
+}
+
+func (m Model) pagerMaxScroll() int {
+	if m.pager == nil || m.height <= 0 {
+		return 0
+	}
+	return max(0, len(m.pager.lines)-m.pagerBodyHeight())
+}
+
+func (m Model) updatePagerMode(key string) (tea.Model, tea.Cmd) {
+	if m.pager == nil {
+		m.mode = modeList
+		return m, nil
+	}
+	if m.processScrollKey(key, m.pagerMaxScroll(), &m.pager.scroll) {
+		return m, nil
+	}
+	switch key {
+	case "esc", m.cfg.Keys.Quit, "q":
+		m.mode = modeList
+		m.pager = nil
+		m.status = "Output closed"
+	case m.cfg.Keys.Up, "up":
+		if m.pager.scroll > 0 {
+			m.pager.scroll--
+		}
+	case m.cfg.Keys.Down, "down":
+		m.pager.scroll = clampInt(m.pager.scroll+1, 0, m.pagerMaxScroll())
+	case "pgup", "ctrl+u":
+		m.pager.scroll = clampInt(m.pager.scroll-m.pagerBodyHeight()/2, 0, m.pagerMaxScroll())
+	case "pgdown", "ctrl+d", " ":
+		m.pager.scroll = clampInt(m.pager.scroll+m.pagerBodyHeight()/2, 0, m.pagerMaxScroll())
+	}
+	return m, nil
+}
+
+func (m Model) pagerHeader() string {
+	return m.renderListBanner() + "\n\n" + m.styles.Accent.Render(m.pager.title) + "\n\n"
+}
+
+func (m Model) pagerFooter() string {
+	return m.styles.Muted.Render(fmt.Sprintf("%d lines • up/down scroll • space/ctrl+d page • gg/G top/bottom • esc/q close", len(m.pager.lines)))
+}
+
+func (m Model) renderPagerView() string {
+	if m.pager == nil {
+		return ""
+	}
+	lines := m.pager.lines
+	if height := m.pagerBodyHeight(); height > 0 {
+		scroll := clampInt(m.pager.scroll, 0, m.pagerMaxScroll())
+		lines = lines[scroll:min(len(lines), scroll+height)]
+	}
+	width := m.width
+	if width <= 0 {
+		width = 100
+	}
+	shown := make([]string, len(lines))
+	for i, line := range lines {
+		shown[i] = truncateTextWidth(line, width)
+	}
+	return m.pagerHeader() + strings.Join(shown, "\n") + "\n\n" + m.pagerFooter()
+}
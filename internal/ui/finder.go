@@ -0,0 +1,383 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"bada/internal/storage"
+)
+
+type finderKind int
+
+const (
+	finderTask finderKind = iota
+	finderTopic
+	finderTrash
+)
+
+type finderEntry struct {
+	kind      finderKind
+	workspace string
+	text      string
+	task      storage.Task
+	trashPath string
+	deletedAt time.Time
+}
+
+type finderResult struct {
+	entry     int
+	score     int
+	positions []int
+}
+
+type finderState struct {
+	entries []finderEntry
+	results []finderResult
+	cursor  int
+	// skipped names workspaces that could not be opened.
+	skipped []string
+}
+
+// fuzzyMatch looks for pattern's runes in order inside text, fzf style: the
+// shortest window wins, and matches on word starts or right after the
+// previous match score higher. Positions index text's runes.
+func fuzzyMatch(pattern, text string) (int, []int, bool) {
+	pat := []rune(strings.ToLower(pattern))
+	if len(pat) == 0 {
+		return 0, nil, true
+	}
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		lower = runes
+	}
+	end, p := -1, 0
+	for i, r := range lower {
+		if r == pat[p] {
+			p++
+			if p == len(pat) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	start := end
+	for i, p := end, len(pat)-1; i >= 0; i-- {
+		if lower[i] == pat[p] {
+			if p--; p < 0 {
+				start = i
+				break
+			}
+		}
+	}
+	positions := make([]int, 0, len(pat))
+	score := 0
+	p = 0
+	for i := start; i <= end && p < len(pat); i++ {
+		if lower[i] != pat[p] {
+			continue
+		}
+		score += 16
+		if i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]) {
+			score += 10
+		} else if unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]) {
+			score += 8
+		}
+		if len(positions) > 0 && positions[len(positions)-1] == i-1 {
+			score += 8
+		}
+		positions = append(positions, i)
+		p++
+	}
+	score -= (end - start + 1) - len(pat)
+	score -= min(start, 10) / 2
+	return score, positions, true
+}
+
+// enterFinder collects everything the finder can jump to: tasks and topics
+// here, plus tasks and trash of every workspace.
+func (m Model) enterFinder() (tea.Model, tea.Cmd) {
+	f := &finderState{}
+	for _, t := range m.tasks {
+		f.entries = append(f.entries, finderEntry{kind: finderTask, workspace: m.workspace, text: t.Title, task: t})
+	}
+	for _, topic := range m.sortedTopics() {
+		f.entries = append(f.entries, finderEntry{kind: finderTopic, workspace: m.workspace, text: topic})
+	}
+	if entries, _, err := m.loadTrash(); err == nil {
+		f.entries = append(f.entries, trashFinderEntries(m.workspace, entries)...)
+	}
+	for _, name := range m.cfg.WorkspaceNames() {
+		if name == m.workspace {
+			continue
+		}
+		store, err := m.openWorkspaceStore(m.cfg, name)
+		if err != nil {
+			f.skipped = append(f.skipped, name)
+			continue
+		}
+		tasks, err := store.FetchTasks()
+		trash, trashErr := store.ListTrash()
+		store.Close()
+		var unreadable *storage.UnreadableTrash
+		if errors.As(trashErr, &unreadable) {
+			trashErr = nil
+		}
+		if err != nil || trashErr != nil {
+			f.skipped = append(f.skipped, name)
+			continue
+		}
+		for _, t := range tasks {
+			f.entries = append(f.entries, finderEntry{kind: finderTask, workspace: name, text: t.Title, task: t})
+		}
+		f.entries = append(f.entries, trashFinderEntries(name, trash)...)
+	}
+	m.finder = f
+	m.mode = modeFinder
+	m.input.SetValue("")
+	m.input.Placeholder = "Find task, topic or deleted task"
+	m.input.Focus()
+	m.refreshFinder()
+	m.status = "Find: type to filter, up/down or ctrl+j/k to pick, enter to jump, esc to close"
+	if len(f.skipped) > 0 {
+		m.status = fmt.Sprintf("Find: skipped workspace(s) %s", strings.Join(f.skipped, ", "))
+	}
+	return m, nil
+}
+
+func trashFinderEntries(workspace string, entries []storage.TrashEntry) []finderEntry {
+	out := make([]finderEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, finderEntry{kind: finderTrash, workspace: workspace, text: e.Task.Title, task: e.Task, trashPath: e.Path, deletedAt: e.DeletedAt})
+	}
+	return out
+}
+
+// refreshFinder re-ranks the entries for the current input. Ties go to this
+// workspace, then live tasks over topics over trash, then shorter text.
+func (m *Model) refreshFinder() {
+	f := m.finder
+	pattern := strings.TrimSpace(m.input.Value())
+	f.results = f.results[:0]
+	for i, e := range f.entries {
+		score, pos, ok := fuzzyMatch(pattern, e.text)
+		if ok {
+			f.results = append(f.results, finderResult{entry: i, score: score, positions: pos})
+		}
+	}
+	sort.SliceStable(f.results, func(i, j int) bool {
+		a, b := f.results[i], f.results[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		ea, eb := f.entries[a.entry], f.entries[b.entry]
+		if (ea.workspace == m.workspace) != (eb.workspace == m.workspace) {
+			return ea.workspace == m.workspace
+		}
+		if ea.kind != eb.kind {
+			return ea.kind < eb.kind
+		}
+		if pattern != "" && len(ea.text) != len(eb.text) {
+			return len(ea.text) < len(eb.text)
+		}
+		return false
+	})
+	f.cursor = clampCursor(f.cursor, len(f.results))
+}
+
+func (m Model) updateFinderMode(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.finder
+	if f == nil {
+		m.mode = modeList
+		return m, nil
+	}
+	switch key {
+	case m.cfg.Keys.Cancel, "esc", m.cfg.Keys.Finder:
+		m.mode = modeList
+		m.finder = nil
+		m.input.Blur()
+		m.status = "Find closed"
+		return m, nil
+	case "up", "ctrl+k":
+		if f.cursor > 0 {
+			f.cursor--
+		}
+		return m, nil
+	case "down", "ctrl+j", "tab":
+		f.cursor = clampCursor(f.cursor+1, len(f.results))
+		return m, nil
+	case m.cfg.Keys.Confirm, "enter":
+		if f.cursor >= len(f.results) {
+			return m, nil
+		}
+		entry := f.entries[f.results[f.cursor].entry]
+		m.finder = nil
+		m.input.Blur()
+		m.input.SetValue("")
+		m.mode = modeList
+		return m.jumpToFinderEntry(entry)
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	f.cursor = 0
+	m.refreshFinder()
+	return m, cmd
+}
+
+// jumpToFinderEntry switches workspace if needed and puts the cursor on the
+// entry: the task inside its topic, the topic itself, or the trash entry.
+func (m Model) jumpToFinderEntry(e finderEntry) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if e.workspace != m.workspace {
+		next, c := m.switchWorkspace(e.workspace)
+		m = next.(Model)
+		if m.workspace != e.workspace {
+			return m, c
+		}
+		cmd = c
+	}
+	switch e.kind {
+	case finderTrash:
+		next, _ := m.enterTrashView()
+		m = next.(Model)
+		for i, entry := range m.trash {
+			if entry.Path == e.trashPath {
+				m.trashCursor = i
+			}
+		}
+		m.adjustTrashScroll()
+		m.status = fmt.Sprintf("Trash: #%d %s (u to restore)", e.task.ID, e.task.Title)
+		return m, cmd
+	case finderTopic:
+		m.leaveTopic()
+		m.searchQuery = ""
+		m.openTopic(e.text)
+		m.cursor = 0
+		m.status = fmt.Sprintf("Topic: %s", e.text)
+		return m, cmd
+	}
+	idx := m.findTaskIndex(e.task.ID)
+	if idx < 0 {
+		m.status = fmt.Sprintf("Task #%d is gone", e.task.ID)
+		return m, cmd
+	}
+	t := m.tasks[idx]
+	m.leaveTopic()
+	m.searchQuery = ""
+	topic := ""
+	switch {
+	case isSnoozed(t, time.Now()):
+		topic = snoozedTopic
+	case len(t.Topics) > 0:
+		topic = t.Topics[0]
+	}
+	m.status = fmt.Sprintf("#%d %s", t.ID, t.Title)
+	if topic != snoozedTopic && !m.passesFilter(t) {
+		m.filterDone = "all"
+		m.status += " (filter reset to all)"
+	}
+	if topic != "" {
+		m.openTopic(topic)
+	}
+	m.cursor = clampCursor(m.findVisibleTaskIndex(t.ID), len(m.visibleItems()))
+	return m, cmd
+}
+
+// highlightMatches styles the matched runes of text.
+func (m Model) highlightMatches(text string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(text)
+	}
+	hit := m.styles.Accent.Bold(true).Underline(true)
+	var b strings.Builder
+	next := 0
+	for i, r := range []rune(text) {
+		if next < len(positions) && positions[next] == i {
+			b.WriteString(hit.Render(string(r)))
+			next++
+			continue
+		}
+		b.WriteString(base.Render(string(r)))
+	}
+	return b.String()
+}
+
+func (m Model) finderRow(e finderEntry, r finderResult, selected bool, width int) string {
+	icon := "📁"
+	var context []string
+	switch e.kind {
+	case finderTask:
+		icon = m.stateSymbol(e.task, true)
+		if len(e.task.Topics) > 0 {
+			context = append(context, "["+strings.Join(e.task.Topics, ",")+"]")
+		}
+		if e.task.Due.Valid {
+			context = append(context, "due "+m.formatWhen(e.task.Due))
+		}
+	case finderTopic:
+		context = append(context, "topic")
+	case finderTrash:
+		icon = "🗑"
+		context = append(context, "trash, deleted "+e.deletedAt.Local().Format("2006-01-02"))
+	}
+	if e.workspace != m.workspace {
+		context = append(context, "@"+e.workspace)
+	}
+	cursor := "  "
+	base := lipgloss.NewStyle()
+	switch {
+	case selected:
+		cursor = m.styles.Accent.Render("> ")
+		base = m.styles.Heading
+	case e.kind == finderTrash:
+		base = m.styles.Muted
+	}
+	text := e.text
+	if limit := width - 4 - lipgloss.Width(icon); limit > 0 && len([]rune(text)) > limit {
+		text = string([]rune(text)[:limit-1]) + "…"
+	}
+	line := cursor + icon + " " + m.highlightMatches(text, r.positions, base)
+	if len(context) > 0 {
+		line += "  " + m.styles.Muted.Render(strings.Join(context, " "))
+	}
+	return line
+}
+
+func (m Model) renderFinderView() string {
+	f := m.finder
+	if f == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(m.renderListBanner() + "\n\n")
+	b.WriteString(m.styles.Accent.Render("# Find") + "  " + m.styles.Muted.Render(fmt.Sprintf("%d/%d", len(f.results), len(f.entries))) + "\n\n")
+	b.WriteString(m.styles.Heading.Render("> ") + m.input.View() + "\n\n")
+	footer := m.styles.Muted.Render("type to filter • up/down ctrl+j/k pick • enter jump • esc close")
+	rows := len(f.results)
+	if m.height > 0 {
+		rows = min(rows, max(1, m.height-1-countLines(b.String())-2))
+	}
+	start := 0
+	if f.cursor >= rows {
+		start = f.cursor - rows + 1
+	}
+	for i := start; i < min(len(f.results), start+rows); i++ {
+		r := f.results[i]
+		b.WriteString(m.finderRow(f.entries[r.entry], r, i == f.cursor, m.width) + "\n")
+	}
+	if len(f.results) == 0 {
+		b.WriteString(m.styles.Muted.Render("(no matches)") + "\n")
+	}
+	b.WriteString("\n" + footer)
+	return b.String()
+}
@@ -0,0 +1,310 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/storage"
+)
+
+type focusState struct {
+	taskID    int
+	onBreak   bool
+	paused    bool
+	remaining time.Duration
+	endsAt    time.Time
+	length    time.Duration
+	completed int
+	gen       int
+	scroll    int
+	today     int
+	todayTask int
+}
+
+type focusTickMsg struct {
+	gen int
+}
+
+func focusTick(gen int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return focusTickMsg{gen: gen} })
+}
+
+func (m Model) enterFocusView() (tea.Model, tea.Cmd) {
+	m.input.Blur()
+	task, ok := m.currentTask()
+	if !ok {
+		m.mode = modeList
+		m.status = "Select a task to focus on"
+		return m, nil
+	}
+	work, _, _ := m.cfg.Focus.Lengths()
+	m.focusGen++
+	now := time.Now()
+	m.focus = &focusState{taskID: task.ID, endsAt: now.Add(work), remaining: work, length: work, gen: m.focusGen}
+	m.refreshFocusCounts()
+	m.mode = modeFocus
+	m.status = fmt.Sprintf("Focus on #%d for %s", task.ID, formatMinutes(work))
+	return m, focusTick(m.focusGen)
+}
+
+func (m *Model) refreshFocusCounts() {
+	if m.focus == nil {
+		return
+	}
+	now := time.Now()
+	sessions, err := m.store.FocusSessionsSince(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()))
+	if err != nil {
+		m.status = fmt.Sprintf("focus log failed: %v", err)
+		return
+	}
+	m.focus.today, m.focus.todayTask = len(sessions), 0
+	for _, s := range sessions {
+		if s.TaskID == m.focus.taskID {
+			m.focus.todayTask++
+		}
+	}
+}
+
+func (m Model) focusRemaining(now time.Time) time.Duration {
+	if m.focus.paused {
+		return m.focus.remaining
+	}
+	if left := m.focus.endsAt.Sub(now); left > 0 {
+		return left
+	}
+	return 0
+}
+
+func (m Model) handleFocusTick(msg focusTickMsg) (tea.Model, tea.Cmd) {
+	if m.focus == nil || msg.gen != m.focus.gen {
+		return m, nil
+	}
+	now := time.Now()
+	if !m.focus.paused && !now.Before(m.focus.endsAt) {
+		m.finishFocusPhase(now, true)
+	}
+	return m, focusTick(m.focus.gen)
+}
+
+// finishFocusPhase ends the current phase. A work phase is logged only when it
+// ran to completion; after a break the next work phase waits for space.
+func (m *Model) finishFocusPhase(now time.Time, completed bool) {
+	work, short, long := m.cfg.Focus.Lengths()
+	f := m.focus
+	if !f.onBreak {
+		if completed {
+			if err := m.store.LogFocusSession(f.taskID, now.Add(-f.length), now); err != nil {
+				m.status = fmt.Sprintf("focus log failed: %v", err)
+			}
+			f.completed++
+			m.refreshFocusCounts()
+		}
+		brk := short
+		if every := m.cfg.Focus.LongEvery; every > 0 && f.completed > 0 && f.completed%every == 0 {
+			brk = long
+		}
+		f.onBreak = true
+		f.paused = false
+		f.length = brk
+		f.endsAt = now.Add(brk)
+		if completed {
+			m.status = fmt.Sprintf("Session %d done — take a %s break", f.completed, formatMinutes(brk))
+		} else {
+			m.status = fmt.Sprintf("Work skipped — %s break", formatMinutes(brk))
+		}
+		return
+	}
+	f.onBreak = false
+	f.length = work
+	f.remaining = work
+	if completed {
+		f.paused = true
+		m.status = "Break over — press space to start the next session"
+		return
+	}
+	f.paused = false
+	f.endsAt = now.Add(work)
+	m.status = "Break skipped — back to work"
+}
+
+func (m Model) updateFocusMode(key string) (tea.Model, tea.Cmd) {
+	if m.focus == nil {
+		m.mode = modeList
+		return m, nil
+	}
+	now := time.Now()
+	switch key {
+	case "esc", m.cfg.Keys.Quit, "q":
+		m.focus = nil
+		m.mode = modeList
+		m.status = "Focus ended"
+	case " ", "p":
+		if m.focus.paused {
+			m.focus.endsAt = now.Add(m.focus.remaining)
+			m.focus.paused = false
+			m.status = "Resumed"
+		} else {
+			m.focus.remaining = m.focusRemaining(now)
+			m.focus.paused = true
+			m.status = "Paused"
+		}
+	case "s":
+		m.finishFocusPhase(now, false)
+	case m.cfg.Keys.Toggle, "D":
+		idx := m.findTaskIndex(m.focus.taskID)
+		if idx < 0 {
+			return m, nil
+		}
+		if !m.focus.onBreak {
+			// count the interrupted session if most of it was spent
+			elapsed := m.focus.length - m.focusRemaining(now)
+			if elapsed*2 >= m.focus.length {
+				if err := m.store.LogFocusSession(m.focus.taskID, now.Add(-elapsed), now); err != nil {
+					m.status = fmt.Sprintf("focus log failed: %v", err)
+					return m, nil
+				}
+			}
+		}
+		if err := m.markTaskDone(m.focus.taskID); err != nil {
+			m.status = fmt.Sprintf("toggle failed: %v", err)
+			return m, nil
+		}
+		if err := m.reloadTasks(); err != nil {
+			m.status = fmt.Sprintf("reload failed: %v", err)
+			return m, nil
+		}
+		m.focus = nil
+		m.mode = modeList
+		m.status = "Marked done — focus ended"
+	case m.cfg.Keys.Down, "down":
+		if idx := m.findTaskIndex(m.focus.taskID); idx >= 0 && m.focus.scroll < strings.Count(m.tasks[idx].Notes, "\n") {
+			m.focus.scroll++
+		}
+	case m.cfg.Keys.Up, "up":
+		if m.focus.scroll > 0 {
+			m.focus.scroll--
+		}
+	}
+	return m, nil
+}
+
+func (m Model) renderFocusView() string {
+	f := m.focus
+	if f == nil {
+		return ""
+	}
+	idx := m.findTaskIndex(f.taskID)
+	if idx < 0 {
+		return m.styles.Muted.Render("Task no longer exists — press esc")
+	}
+	task := m.tasks[idx]
+	now := time.Now()
+	phase, style := "WORK", m.styles.Accent
+	if f.onBreak {
+		phase, style = "BREAK", m.styles.Success
+	}
+	left := m.focusRemaining(now)
+	state := ""
+	if f.paused {
+		state = m.styles.Warning.Render("  ⏸ paused")
+	}
+
+	var header strings.Builder
+	header.WriteString(m.renderListBanner())
+	header.WriteString("\n\n")
+	header.WriteString(m.styles.Accent.Render(fmt.Sprintf("# Focus  #%d %s", task.ID, task.Title)))
+	header.WriteString("\n")
+	meta := append([]string{}, task.Topics...)
+	if task.Tags != "" {
+		meta = append(meta, task.Tags)
+	}
+	if len(meta) > 0 {
+		header.WriteString(m.styles.Muted.Render(strings.Join(meta, " • ")))
+		header.WriteString("\n")
+	}
+	header.WriteString("\n")
+	header.WriteString(style.Render(fmt.Sprintf("  %s  %s", phase, formatCountdown(left))))
+	header.WriteString("  ")
+	header.WriteString(m.focusBar(f.length-left, f.length))
+	header.WriteString(state)
+	header.WriteString("\n\n")
+	header.WriteString(m.styles.Muted.Render(fmt.Sprintf("  Sessions today: %d (this task %d) • this run %d", f.today, f.todayTask, f.completed)))
+	header.WriteString("\n")
+	header.WriteString(m.styles.Border.Render(m.ruleLine(m.width)))
+	header.WriteString("\n")
+	footer := m.styles.Muted.Render(fmt.Sprintf("space pause/resume • s skip phase • %s done • j/k scroll notes • esc/q leave focus", m.cfg.Keys.Toggle))
+
+	body := []string{m.styles.Muted.Render("(no notes)")}
+	if strings.TrimSpace(task.Notes) != "" {
+		body = strings.Split(m.renderMarkdown(task.Notes), "\n")
+	}
+	if m.height > 0 {
+		avail := m.height - 1 - countLines(header.String()) - 2
+		if avail < 0 {
+			avail = 0
+		}
+		start := clampInt(f.scroll, 0, max(0, len(body)-avail))
+		end := min(len(body), start+avail)
+		body = body[start:end]
+	}
+	var b strings.Builder
+	b.WriteString(header.String())
+	for _, line := range body {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(footer)
+	return b.String()
+}
+
+func (m Model) focusBar(done, total time.Duration) string {
+	const width = 30
+	if total <= 0 {
+		return ""
+	}
+	filled := clampInt(int(width*done/total), 0, width)
+	return m.styles.Accent.Render(strings.Repeat("█", filled)) + m.styles.Muted.Render(strings.Repeat("░", width-filled))
+}
+
+func formatCountdown(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+func formatMinutes(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return d.String()
+}
+
+func (m Model) focusReportLines(sessions []storage.FocusSession) []string {
+	type agg struct {
+		id      int
+		title   string
+		count   int
+		minutes int
+	}
+	var order []int
+	byTask := map[int]*agg{}
+	for _, s := range sessions {
+		a, ok := byTask[s.TaskID]
+		if !ok {
+			a = &agg{id: s.TaskID, title: s.Title}
+			byTask[s.TaskID] = a
+			order = append(order, s.TaskID)
+		}
+		a.count++
+		a.minutes += s.Minutes
+	}
+	lines := make([]string, 0, len(order))
+	for _, id := range order {
+		a := byTask[id]
+		lines = append(lines, fmt.Sprintf("  • #%d %-40s  ×%d (%dm)", a.id, truncateText(a.title, 40), a.count, a.minutes))
+	}
+	return lines
+}
@@ -0,0 +1,240 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/recur"
+	"bada/internal/storage"
+)
+
+type habitMark int
+
+const (
+	habitNone habitMark = iota
+	habitHit
+	habitMiss
+	habitSkipped
+	habitPending
+)
+
+// habitStats is a recurring task's record over the grid's weeks. Misses are
+// occurrences its rule gave that were neither completed nor skipped, from
+// the day the task was created on.
+type habitStats struct {
+	marks  map[string]habitMark
+	from   time.Time
+	today  time.Time
+	hits   int
+	misses int
+	streak int
+	best   int
+}
+
+// plainDate drops the time and zone, so dates from the store, the rule and
+// the clock compare as calendar days.
+func plainDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func habitHistory(t storage.Task, completions []storage.Completion, weeks int, now time.Time) habitStats {
+	today := plainDate(now)
+	from := startOfWeek(today, time.Monday).AddDate(0, 0, -7*(weeks-1))
+	stats := habitStats{marks: map[string]habitMark{}, from: from, today: today}
+	r, ok := taskRule(t)
+	_, current, hasBase := unmoved(t)
+	if !ok || !hasBase {
+		return stats
+	}
+	current = plainDate(current)
+
+	done := map[string]bool{}
+	earliest := plainDate(t.CreatedAt)
+	for _, c := range completions {
+		day := plainDate(c.CompletedAt.Local())
+		if c.Due.Valid {
+			day = plainDate(c.Due.Time.In(taskLocation(t)))
+		}
+		done[day.Format("2006-01-02")] = true
+		if day.Before(earliest) {
+			earliest = day
+		}
+	}
+	skipped := map[string]bool{}
+	for _, o := range t.Overrides {
+		if o.Kind == storage.OverrideSkip {
+			skipped[o.Occurrence.Format("2006-01-02")] = true
+		}
+	}
+	for _, d := range r.Exdates {
+		skipped[d.Format("2006-01-02")] = true
+	}
+
+	lower := from
+	if earliest.After(lower) {
+		lower = earliest
+	}
+	pattern := r.Pattern()
+	anchor := habitAnchor(current, pattern.Freq, max(1, pattern.Interval), lower)
+	var resolved []habitMark
+	for occ, ok := pattern.Next(anchor, anchor.AddDate(0, 0, -1)); ok && !occ.After(today); occ, ok = pattern.Next(anchor, occ) {
+		if occ.Before(lower) {
+			continue
+		}
+		key := occ.Format("2006-01-02")
+		mark := habitMiss
+		switch {
+		case done[key]:
+			mark = habitHit
+		case skipped[key]:
+			mark = habitSkipped
+		case !occ.Before(current) && !t.Done:
+			mark = habitPending
+		}
+		stats.marks[key] = mark
+		if mark == habitHit || mark == habitMiss {
+			resolved = append(resolved, mark)
+		}
+	}
+	// Completions on days the rule does not give (an earlier rule, or an
+	// occurrence done after the rule changed) still show as hits.
+	for key := range done {
+		if _, ok := stats.marks[key]; !ok {
+			stats.marks[key] = habitHit
+		}
+	}
+	run := 0
+	for _, mark := range resolved {
+		if mark == habitHit {
+			stats.hits++
+			run++
+			stats.best = max(stats.best, run)
+		} else {
+			stats.misses++
+			run = 0
+		}
+	}
+	stats.streak = run
+	return stats
+}
+
+// habitAnchor steps back from the current occurrence by whole periods until
+// it is on or before from, so the rule's phase (every other week, every
+// third month) is kept.
+func habitAnchor(current time.Time, freq recur.Freq, interval int, from time.Time) time.Time {
+	anchor := current
+	for i := 1; anchor.After(from) && i < 5000; i++ {
+		switch freq {
+		case recur.Daily:
+			anchor = current.AddDate(0, 0, -interval*i)
+		case recur.Weekly:
+			anchor = current.AddDate(0, 0, -7*interval*i)
+		case recur.Monthly:
+			first := time.Date(current.Year(), current.Month()-time.Month(interval*i), 1, 0, 0, 0, 0, time.UTC)
+			anchor = first.AddDate(0, 0, min(current.Day(), daysInMonth(first))-1)
+		default:
+			first := time.Date(current.Year()-interval*i, current.Month(), 1, 0, 0, 0, 0, time.UTC)
+			anchor = first.AddDate(0, 0, min(current.Day(), daysInMonth(first))-1)
+		}
+	}
+	return anchor
+}
+
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func (s habitStats) summary() string {
+	total := s.hits + s.misses
+	if total == 0 {
+		return fmt.Sprintf("streak %d • no occurrences yet", s.streak)
+	}
+	return fmt.Sprintf("streak %d • best %d • %d/%d done (%d%%)", s.streak, s.best, s.hits, total, s.hits*100/total)
+}
+
+// habitGrid draws the weeks as columns and the weekdays as rows, like a
+// contribution graph: ■ done, □ missed, – skipped, ◌ still open.
+func (m Model) habitGrid(s habitStats) []string {
+	weeks := int(s.today.Sub(s.from).Hours()/24)/7 + 1
+	header := []byte(strings.Repeat(" ", 4+2*weeks+2))
+	free := 0
+	for w := 0; w < weeks; w++ {
+		monday := s.from.AddDate(0, 0, 7*w)
+		pos := 4 + 2*w
+		if (w == 0 || monday.Day() <= 7) && pos >= free {
+			copy(header[pos:], monday.Format("Jan"))
+			free = pos + 4
+		}
+	}
+	lines := []string{m.styles.Muted.Render(strings.TrimRight(string(header), " "))}
+	for row := 0; row < 7; row++ {
+		label := "   "
+		if row%2 == 0 && row < 6 {
+			label = weekdayShort(time.Weekday((row + 1) % 7))
+		}
+		var b strings.Builder
+		b.WriteString(m.styles.Muted.Render(label) + " ")
+		for w := 0; w < weeks; w++ {
+			day := s.from.AddDate(0, 0, 7*w+row)
+			if day.After(s.today) {
+				break
+			}
+			b.WriteString(m.habitCell(s.marks[day.Format("2006-01-02")]) + " ")
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	return lines
+}
+
+func (m Model) habitCell(mark habitMark) string {
+	switch mark {
+	case habitHit:
+		return m.styles.Success.Render("■")
+	case habitMiss:
+		return m.styles.Danger.Render("□")
+	case habitSkipped:
+		return m.styles.Muted.Render("–")
+	case habitPending:
+		return m.styles.Warning.Render("◌")
+	}
+	return m.styles.Border.Render("·")
+}
+
+// habitLines is the summary and grid of one recurring task, for the note
+// preview and :habits.
+func (m Model) habitLines(t storage.Task, completions []storage.Completion) (string, []string) {
+	s := habitHistory(t, completions, max(1, m.cfg.Recurrence.HabitWeeks), time.Now())
+	return s.summary(), m.habitGrid(s)
+}
+
+func (m Model) enterHabitsView() (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	var lines []string
+	for _, t := range m.tasks {
+		if t.Done || !isRecurringTask(t) {
+			continue
+		}
+		completions, err := m.store.Completions(t.ID)
+		if err != nil {
+			m.status = fmt.Sprintf("habits failed: %v", err)
+			return m, nil
+		}
+		summary, grid := m.habitLines(t, completions)
+		lines = append(lines, m.styles.Heading.Render(fmt.Sprintf("#%d %s", t.ID, t.Title))+"  "+m.styles.Muted.Render("["+recurrenceSummary(t)+"]"))
+		lines = append(lines, "    "+summary)
+		lines = append(lines, grid...)
+		lines = append(lines, "")
+	}
+	if len(lines) == 0 {
+		m.status = "No recurring tasks to track"
+		return m, nil
+	}
+	m.pager = &pagerState{title: fmt.Sprintf(":habits (last %d weeks)", m.cfg.Recurrence.HabitWeeks), lines: lines[:len(lines)-1]}
+	m.mode = modePager
+	m.status = "Habits: ■ done • □ missed • – skipped • ◌ open"
+	return m, nil
+}
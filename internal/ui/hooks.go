@@ -0,0 +1,274 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/storage"
+)
+
+const (
+	hookAdd     = "add"
+	hookModify  = "modify"
+	hookDone    = "done"
+	hookDelete  = "delete"
+	hookRestore = "restore"
+
+	// Pre-hooks run while the UI waits for their verdict, so they get far
+	// less time than the on- hooks that run in the background.
+	preHookTimeout = 2 * time.Second
+	hookTimeout    = 10 * time.Second
+)
+
+// hookPayload is what every hook reads on stdin; tasks use the same JSON as
+// exports and trash files, and before/after are null for adds/deletes.
+type hookPayload struct {
+	Event  string        `json:"event"`
+	Before *storage.Task `json:"before"`
+	After  *storage.Task `json:"after"`
+}
+
+func (m Model) hooksDir() string {
+	if strings.TrimSpace(m.configPath) == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(m.configPath), "hooks")
+}
+
+// hookScripts lists the executables named prefix, prefix.* or prefix-* in
+// the hooks directory, in name order.
+func hookScripts(dir, prefix string) []string {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var scripts []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || name != prefix && !strings.HasPrefix(name, prefix+".") && !strings.HasPrefix(name, prefix+"-") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.Mode()&0o111 == 0 {
+			continue
+		}
+		scripts = append(scripts, filepath.Join(dir, name))
+	}
+	return scripts
+}
+
+func runHook(path string, payload hookPayload, timeout time.Duration) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return execHook(path, payload.Event, data, timeout)
+}
+
+func execHook(path, event string, data []byte, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = filepath.Dir(path)
+	cmd.Env = append(os.Environ(), "BADA_HOOK_EVENT="+event)
+	cmd.Stdin = bytes.NewReader(data)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := firstLine(stderr.String())
+		if msg == "" {
+			msg = firstLine(stdout.String())
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			msg = fmt.Sprintf("timed out after %s", timeout)
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("%s: %s", filepath.Base(path), msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
+
+// runPreHooks lets the pre-<event> hooks veto a change by exiting non-zero,
+// or rewrite it by printing task JSON (fields left out keep their value).
+// Each hook sees the previous one's rewrite. They run inside Update and block
+// the UI until they answer, which is why each one is cut off after
+// preHookTimeout.
+func (m Model) runPreHooks(event string, before, after *storage.Task) (*storage.Task, error) {
+	for _, path := range hookScripts(m.hooksDir(), "pre-"+event) {
+		out, err := runHook(path, hookPayload{Event: event, Before: before, After: after}, preHookTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("vetoed by %v", err)
+		}
+		if after == nil || len(bytes.TrimSpace(out)) == 0 {
+			continue
+		}
+		rewritten := *after
+		if err := json.Unmarshal(out, &rewritten); err != nil {
+			return nil, fmt.Errorf("%s: invalid task JSON: %v", filepath.Base(path), err)
+		}
+		if strings.TrimSpace(rewritten.Title) == "" {
+			return nil, fmt.Errorf("%s: rewrite left the title empty", filepath.Base(path))
+		}
+		rewritten.ID, rewritten.CreatedAt, rewritten.Attachments = after.ID, after.CreatedAt, after.Attachments
+		after = &rewritten
+	}
+	return after, nil
+}
+
+// postHook is one on-<event> hook run waiting for its turn, with the
+// payload taken when the change was stored.
+type postHook struct {
+	path  string
+	event string
+	data  []byte
+}
+
+type postHooksDoneMsg struct {
+	failed []string
+}
+
+// runPostHooks queues the on-<event> hooks for a stored change. Update runs
+// them in the background, one batch at a time and in order; their failures
+// cannot undo the change and only show up in the status bar.
+func (m *Model) runPostHooks(event string, before, after *storage.Task) {
+	scripts := hookScripts(m.hooksDir(), "on-"+event)
+	if len(scripts) == 0 {
+		return
+	}
+	data, err := json.Marshal(hookPayload{Event: event, Before: before, After: after})
+	if err != nil {
+		m.hookErr = fmt.Sprintf("on-%s: %v", event, err)
+		return
+	}
+	for _, path := range scripts {
+		m.postHooks = append(m.postHooks, postHook{path: path, event: event, data: data})
+	}
+}
+
+// startPostHooks hands the queued post-hooks to a command unless a batch is
+// still running; the next batch starts when its postHooksDoneMsg arrives.
+func (m Model) startPostHooks() (Model, tea.Cmd) {
+	if m.hooksRunning || len(m.postHooks) == 0 {
+		return m, nil
+	}
+	hooks := m.postHooks
+	m.postHooks = nil
+	m.hooksRunning = true
+	return m, func() tea.Msg {
+		var failed []string
+		for _, h := range hooks {
+			if _, err := execHook(h.path, h.event, h.data, hookTimeout); err != nil {
+				failed = append(failed, err.Error())
+			}
+		}
+		return postHooksDoneMsg{failed: failed}
+	}
+}
+
+func (m Model) handlePostHooksDone(msg postHooksDoneMsg) (tea.Model, tea.Cmd) {
+	m.hooksRunning = false
+	m.hookErr = strings.Join(msg.failed, "; ")
+	return m, nil
+}
+
+// changeTask re-reads a task, applies change and saves the result once the
+// pre-hooks accept it. Completing a task is a "done"
+// event, anything else "modify"; completing a recurring task also moves it
+// on to its next occurrence (see completeRecurring).
+func (m *Model) changeTask(id int, change func(*storage.Task)) (storage.Task, error) {
+	return m.changeTaskSaving(id, change, m.store.UpdateTask)
+}
+
+// changeTaskSaving is changeTask with the write that stores the result, for
+// changes that must land together with other rows.
+func (m *Model) changeTaskSaving(id int, change func(*storage.Task), save func(storage.Task) error) (storage.Task, error) {
+	before, err := m.store.TaskByID(id)
+	if err != nil {
+		return storage.Task{}, err
+	}
+	after := before
+	after.Topics = append([]string(nil), before.Topics...)
+	change(&after)
+	event := hookModify
+	if after.CompletedAt.Valid && !before.CompletedAt.Valid {
+		event = hookDone
+	}
+	final, err := m.runPreHooks(event, &before, &after)
+	if err != nil {
+		return storage.Task{}, err
+	}
+	if event == hookDone && isRecurringTask(*final) {
+		return m.completeRecurring(before, *final)
+	}
+	if err := save(*final); err != nil {
+		return storage.Task{}, err
+	}
+	m.runPostHooks(event, &before, final)
+	return *final, nil
+}
+
+func (m *Model) createTask(t storage.Task) (int, error) {
+	t.CreatedAt = time.Now().UTC()
+	final, err := m.runPreHooks(hookAdd, nil, &t)
+	if err != nil {
+		return 0, err
+	}
+	id, err := m.store.CreateTask(*final)
+	if err != nil {
+		return 0, err
+	}
+	stored, err := m.store.TaskByID(id)
+	if err != nil {
+		return id, err
+	}
+	m.runPostHooks(hookAdd, nil, &stored)
+	return id, nil
+}
+
+// vetoDeletes asks the pre-delete hooks about every task before any of them
+// is touched, so a veto leaves the whole batch in place.
+func (m Model) vetoDeletes(tasks []storage.Task) error {
+	for i := range tasks {
+		if _, err := m.runPreHooks(hookDelete, &tasks[i], nil); err != nil {
+			return fmt.Errorf("#%d %w", tasks[i].ID, err)
+		}
+	}
+	return nil
+}
+
+func (m *Model) announceDeletes(tasks []storage.Task) {
+	for i := range tasks {
+		m.runPostHooks(hookDelete, &tasks[i], nil)
+	}
+}
+
+// markTaskDone completes a task through the first done state.
+func (m *Model) markTaskDone(id int) error {
+	st, ok := m.firstState(true)
+	if !ok {
+		return errors.New("no done state configured")
+	}
+	_, err := m.changeTask(id, func(t *storage.Task) { applyState(t, st) })
+	return err
+}
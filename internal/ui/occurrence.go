@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/storage"
+)
+
+// pendingOverride is a task's latest skip or move while the task still sits
+// on the date it put it on; undo takes it back.
+func pendingOverride(t storage.Task) (storage.Override, bool) {
+	if t.Done || len(t.Overrides) == 0 {
+		return storage.Override{}, false
+	}
+	o := t.Overrides[len(t.Overrides)-1]
+	base, ok := recurrenceBaseDate(t)
+	if !ok || o.MovedTo.Format("2006-01-02") != base.Format("2006-01-02") {
+		return storage.Override{}, false
+	}
+	return o, true
+}
+
+// unmoved puts a task whose current occurrence was moved back on the date
+// its rule gives, which is where the series carries on from.
+func unmoved(t storage.Task) (storage.Task, time.Time, bool) {
+	base, ok := recurrenceBaseDate(t)
+	if !ok {
+		return t, time.Time{}, false
+	}
+	if o, ok := pendingOverride(t); ok && o.Kind == storage.OverrideMove {
+		return shiftDates(t, calendarDays(o.MovedTo, o.Occurrence)), o.Occurrence, true
+	}
+	return t, base, true
+}
+
+func shiftDates(t storage.Task, days int) storage.Task {
+	if t.Due.Valid {
+		t.Due.Time = t.Due.Time.AddDate(0, 0, days)
+	}
+	if t.Start.Valid {
+		t.Start.Time = t.Start.Time.AddDate(0, 0, days)
+	}
+	return t
+}
+
+// calendarDays counts the days from a to b by their dates alone.
+func calendarDays(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+// occurrenceTask is the current task if it is an open, dated recurring one.
+func (m Model) occurrenceTask() (storage.Task, bool) {
+	t, ok := m.currentTask()
+	if !ok || t.Done || !isRecurringTask(t) || !t.Due.Valid && !t.Start.Valid {
+		return storage.Task{}, false
+	}
+	return t, true
+}
+
+// skipOccurrence moves the current task on to its next occurrence without
+// completing this one. The rule is left alone, apart from a count using up
+// the skipped occurrence.
+func (m Model) skipOccurrence() (tea.Model, tea.Cmd) {
+	t, ok := m.occurrenceTask()
+	if !ok {
+		m.status = "Only open recurring tasks with a date can skip an occurrence"
+		return m, nil
+	}
+	series, occurrence, _ := unmoved(t)
+	rule, next, ok := advanceSeries(series, occurrence)
+	if !ok {
+		m.status = fmt.Sprintf("#%d has no later occurrence to skip to", t.ID)
+		return m, nil
+	}
+	skipped := shiftDates(series, calendarDays(occurrence, next))
+	override := storage.Override{TaskID: t.ID, Kind: storage.OverrideSkip, Occurrence: occurrence, MovedTo: next,
+		PrevDue: t.Due, PrevStart: t.Start, PrevRule: t.RecurrenceRule}
+	if err := m.overrideOccurrence(t.ID, skipped.Due, skipped.Start, rule, override); err != nil {
+		m.status = fmt.Sprintf("skip failed: %v", err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("#%d skips %s, next due %s", t.ID, occurrence.Format("Mon 2006-01-02"), next.Format("Mon 2006-01-02"))
+	return m, nil
+}
+
+func (m Model) startReschedule() (tea.Model, tea.Cmd) {
+	if _, ok := m.occurrenceTask(); !ok {
+		m.status = "Only open recurring tasks with a date can move an occurrence"
+		return m, nil
+	}
+	next, cmd := m.startCommand()
+	m = next.(Model)
+	m.input.SetValue("reschedule ")
+	m.input.CursorEnd()
+	m.status = "Move this occurrence to: +3d, tomorrow, friday, YYYY-MM-DD (the rule stays as it is)"
+	return m, cmd
+}
+
+// rescheduleOccurrence moves only the current occurrence; once it is done
+// the series carries on from the date its rule gave.
+func (m Model) rescheduleOccurrence(arg string) (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	t, ok := m.occurrenceTask()
+	if !ok {
+		m.status = "Only open recurring tasks with a date can move an occurrence"
+		return m, nil
+	}
+	to, err := parseRescheduleDate(arg, time.Now())
+	if err != nil {
+		m.status = err.Error()
+		return m, nil
+	}
+	series, occurrence, _ := unmoved(t)
+	if to.Format("2006-01-02") == occurrence.Format("2006-01-02") {
+		if o, ok := pendingOverride(t); ok && o.Kind == storage.OverrideMove {
+			return m.undoOccurrence()
+		}
+		m.status = fmt.Sprintf("#%d is already on %s", t.ID, to.Format("Mon 2006-01-02"))
+		return m, nil
+	}
+	moved := shiftDates(series, calendarDays(occurrence, to))
+	override := storage.Override{TaskID: t.ID, Kind: storage.OverrideMove, Occurrence: occurrence, MovedTo: to,
+		PrevDue: t.Due, PrevStart: t.Start, PrevRule: t.RecurrenceRule}
+	if err := m.overrideOccurrence(t.ID, moved.Due, moved.Start, t.RecurrenceRule, override); err != nil {
+		m.status = fmt.Sprintf("reschedule failed: %v", err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("#%d moved from %s to %s", t.ID, occurrence.Format("Mon 01-02"), to.Format("Mon 2006-01-02"))
+	return m, nil
+}
+
+func parseRescheduleDate(v string, now time.Time) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, fmt.Errorf("move it to when? e.g. +3d, tomorrow, friday, 2006-01-02")
+	}
+	if d, err := time.Parse("2006-01-02", v); err == nil {
+		return d, nil
+	}
+	if d, err := parseWakeDate(v, now); err == nil {
+		return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown date %q: use +3d, tomorrow, friday or YYYY-MM-DD", v)
+}
+
+// undoOccurrence takes back the current task's last skip or move.
+func (m Model) undoOccurrence() (tea.Model, tea.Cmd) {
+	t, ok := m.currentTask()
+	if !ok {
+		m.status = "No task selected"
+		return m, nil
+	}
+	o, ok := pendingOverride(t)
+	if !ok {
+		m.status = fmt.Sprintf("#%d has no skipped or moved occurrence to undo", t.ID)
+		return m, nil
+	}
+	err := m.revertOverride(t.ID, o.PrevDue, o.PrevStart, o.PrevRule, o.ID)
+	if err != nil {
+		m.status = fmt.Sprintf("undo failed: %v", err)
+		return m, nil
+	}
+	verb := "skip"
+	if o.Kind == storage.OverrideMove {
+		verb = "move"
+	}
+	m.status = fmt.Sprintf("#%d: undid the %s, due %s again", t.ID, verb, o.Occurrence.Format("Mon 2006-01-02"))
+	return m, nil
+}
+
+func (m *Model) overrideOccurrence(id int, due, start sql.NullTime, rule string, o storage.Override) error {
+	_, err := m.changeTaskSaving(id, func(t *storage.Task) {
+		t.Due, t.Start, t.RecurrenceRule = due, start, rule
+	}, func(t storage.Task) error {
+		return m.store.ApplyOverride(t, o)
+	})
+	if reloadErr := m.reloadTasks(); err == nil {
+		err = reloadErr
+	}
+	return err
+}
+
+// revertOverride gives a task the dates and rule it goes back to and drops
+// the override that had moved it on.
+func (m *Model) revertOverride(id int, due, start sql.NullTime, rule string, overrideID int) error {
+	_, err := m.changeTaskSaving(id, func(t *storage.Task) {
+		t.Due, t.Start, t.RecurrenceRule = due, start, rule
+	}, func(t storage.Task) error {
+		return m.store.RevertOverride(t, overrideID)
+	})
+	if reloadErr := m.reloadTasks(); err == nil {
+		err = reloadErr
+	}
+	return err
+}
+
+// overrideNote describes a pending skip or move, "skipped 10-24" or
+// "moved from 10-24", for the agenda and the calendar.
+func overrideNote(t storage.Task) string {
+	o, ok := pendingOverride(t)
+	if !ok {
+		return ""
+	}
+	if o.Kind == storage.OverrideMove {
+		return "moved from " + o.Occurrence.Format("01-02")
+	}
+	return "skipped " + o.Occurrence.Format("01-02")
+}
+
+// overridesOnDay lists the occurrences that were skipped or moved away from
+// day, so the calendar can still show where they were.
+func (m Model) overridesOnDay(day time.Time, loc *time.Location) []occurrenceMark {
+	key := dateKey(day, loc)
+	var marks []occurrenceMark
+	for _, t := range m.tasks {
+		for i, o := range t.Overrides {
+			if o.Occurrence.Format("2006-01-02") != key || supersededOverride(t.Overrides, i) {
+				continue
+			}
+			mark := occurrenceMark{task: t, symbol: "↷", detail: "skipped, next " + o.MovedTo.Format("2006-01-02")}
+			if o.Kind == storage.OverrideMove {
+				mark.symbol, mark.detail = "→", "moved to "+o.MovedTo.Format("2006-01-02")
+			}
+			marks = append(marks, mark)
+		}
+	}
+	return marks
+}
+
+// supersededOverride reports an override of an occurrence that was skipped
+// or moved again later; only the last one counts.
+func supersededOverride(overrides []storage.Override, i int) bool {
+	for _, later := range overrides[i+1:] {
+		if later.Occurrence.Equal(overrides[i].Occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+type occurrenceMark struct {
+	task   storage.Task
+	symbol string
+	detail string
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bada/internal/config"
+	"bada/internal/storage"
+)
+
+// parseEstimate reads "30m", "2h", "1h30m" or a bare number of minutes.
+func parseEstimate(v string) (int, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(v); err == nil {
+		if n < 0 {
+			return 0, errors.New("estimate cannot be negative")
+		}
+		return n, nil
+	}
+	d, err := config.ParseDuration(v)
+	if err != nil {
+		return 0, errors.New("expected a duration like 30m, 2h or 1h30m")
+	}
+	return int(d.Round(time.Minute) / time.Minute), nil
+}
+
+func formatEstimate(minutes int) string {
+	switch {
+	case minutes <= 0:
+		return ""
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	default:
+		return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+	}
+}
+
+func filterEstimate(v string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(v) {
+		if (r >= '0' && r <= '9') || r == '.' || r == 'h' || r == 'm' || r == 'd' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func totalEstimate(tasks []storage.Task) int {
+	total := 0
+	for _, t := range tasks {
+		total += t.EstimateMinutes
+	}
+	return total
+}
+
+func (m Model) capacityMinutes() int {
+	return int(m.cfg.Planning.Capacity() / time.Minute)
+}
+
+func (m Model) overloaded(load int) bool {
+	capacity := m.capacityMinutes()
+	return capacity > 0 && load > capacity
+}
+
+// loadLabel renders a day's load as "5h/8h", or just "5h" without a capacity.
+func (m Model) loadLabel(load int) string {
+	label := formatEstimate(load)
+	if label == "" {
+		label = "0h"
+	}
+	if capacity := m.capacityMinutes(); capacity > 0 {
+		label += "/" + formatEstimate(capacity)
+	}
+	if m.overloaded(load) {
+		label += "!"
+	}
+	return label
+}
+
+func estimateSuffix(t storage.Task) string {
+	if t.EstimateMinutes <= 0 {
+		return ""
+	}
+	return " ~" + formatEstimate(t.EstimateMinutes)
+}
+
+// loadReportLines lists the estimated load for the coming week; it stays
+// empty until some open task carries an estimate.
+func (m Model) loadReportLines(today time.Time) []string {
+	const days = 7
+	loads := make([]int, days)
+	planned := false
+	for i := range loads {
+		loads[i] = totalEstimate(m.tasksOnDay(today.AddDate(0, 0, i), today.Location()))
+		planned = planned || loads[i] > 0
+	}
+	if !planned {
+		return nil
+	}
+	capacity := m.capacityMinutes()
+	scale := capacity
+	for _, load := range loads {
+		scale = max(scale, load)
+	}
+	lines := make([]string, 0, days)
+	for i, load := range loads {
+		day := today.AddDate(0, 0, i)
+		width := 0
+		if scale > 0 {
+			width = 20 * load / scale
+		}
+		line := fmt.Sprintf("  %s  %-12s %s", day.Format("Mon 01-02"), m.loadLabel(load), strings.Repeat("█", width))
+		switch {
+		case m.overloaded(load):
+			line = m.styles.Danger.Render(line + "  overloaded")
+		case load == 0:
+			line = m.styles.Muted.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// effortDays is how many working days an estimate occupies at the daily
+// capacity (8h when none is set); unestimated tasks take their due day.
+func (m Model) effortDays(minutes int) int {
+	capacity := m.capacityMinutes()
+	if capacity <= 0 {
+		capacity = 8 * 60
+	}
+	if minutes <= 0 {
+		return 1
+	}
+	return (minutes + capacity - 1) / capacity
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"database/sql"
+	"math"
+	"time"
+
+	"bada/internal/config"
+	"bada/internal/recur"
+	"bada/internal/storage"
+)
+
+func (m Model) onCompleteMode(t storage.Task) string {
+	if mode, ok := config.NormalizeOnComplete(t.OnComplete); ok && mode != "" {
+		return mode
+	}
+	if m.cfg.Recurrence.OnComplete == "spawn" {
+		return "spawn"
+	}
+	return "advance"
+}
+
+// rollForward moves a completed task's dates to next, keeping the gap
+// between start and due, and reopens it. Undated tasks get next as due.
+func (m Model) rollForward(t storage.Task, next time.Time) storage.Task {
+	base, _ := recurrenceBaseDate(t)
+	t = shiftDates(t, int(math.Round(next.Sub(base).Hours()/24)))
+	if !t.Due.Valid && !t.Start.Valid {
+		t.Due = sql.NullTime{Time: next, Valid: true}
+	}
+	t.SnoozedUntil = sql.NullTime{}
+	if st, ok := m.firstState(false); ok {
+		applyState(&t, st)
+	} else {
+		t.Status, t.Done, t.CompletedAt = "", false, sql.NullTime{}
+	}
+	return t
+}
+
+// advanceSeries finds the occurrence after from and the rule that carries on
+// from it, with the occurrences passed taken off its count. Rules that do not
+// parse (a bare interval, or words older versions stored) stay as they are.
+func advanceSeries(t storage.Task, from time.Time) (string, time.Time, bool) {
+	r, err := recur.Parse(t.RecurrenceRule)
+	if err != nil {
+		next, ok := nextRecurrenceAfter(t, from)
+		return t.RecurrenceRule, next, ok
+	}
+	base, ok := recurrenceBaseDate(t)
+	if !ok {
+		return t.RecurrenceRule, time.Time{}, false
+	}
+	r, next, ok := r.Advance(base, from)
+	return r.String(), next, ok
+}
+
+// completeRecurring stores the completion of a recurring task. "advance"
+// saves it already rolled forward to its next occurrence; "spawn" saves it
+// done, without its rule, and adds the next occurrence as a new task that
+// takes over the completion history. Either way the completion is recorded.
+func (m *Model) completeRecurring(before, done storage.Task) (storage.Task, error) {
+	// A moved occurrence counts as the one its rule gave, and the series
+	// carries on from there.
+	series, occurrenceDue := done, before.Due
+	if o, moved := pendingOverride(before); moved && o.Kind == storage.OverrideMove {
+		days := calendarDays(o.MovedTo, o.Occurrence)
+		series = shiftDates(done, days)
+		if occurrenceDue.Valid {
+			occurrenceDue.Time = occurrenceDue.Time.AddDate(0, 0, days)
+		}
+	}
+	from := time.Now()
+	if base, ok := recurrenceBaseDate(series); ok && base.After(from) {
+		from = base
+	}
+	rule, next, ok := advanceSeries(series, from)
+	if !ok {
+		if r, err := recur.Parse(done.RecurrenceRule); err == nil && r.HasEnd() {
+			// The series is over: the last occurrence stays done.
+			done.Recurring, done.RecurrenceRule, done.RecurrenceInterval = false, "", 0
+		}
+		if err := m.store.UpdateTask(done); err != nil {
+			return storage.Task{}, err
+		}
+		m.runPostHooks(hookDone, &before, &done)
+		return done, nil
+	}
+	rolled := m.rollForward(series, next)
+	rolled.RecurrenceRule = rule
+	if m.onCompleteMode(done) != "spawn" {
+		if err := m.store.UpdateTask(rolled); err != nil {
+			return storage.Task{}, err
+		}
+		if err := m.store.RecordCompletion(done.ID, occurrenceDue, done.CompletedAt.Time); err != nil {
+			return rolled, err
+		}
+		m.runPostHooks(hookDone, &before, &rolled)
+		return rolled, nil
+	}
+	finished := done
+	finished.Recurring, finished.RecurrenceRule, finished.RecurrenceInterval = false, "", 0
+	if err := m.store.UpdateTask(finished); err != nil {
+		return storage.Task{}, err
+	}
+	if err := m.store.RecordCompletion(done.ID, occurrenceDue, done.CompletedAt.Time); err != nil {
+		return finished, err
+	}
+	m.runPostHooks(hookDone, &before, &finished)
+	rolled.Attachments = nil
+	id, err := m.createTask(rolled)
+	if err != nil {
+		return finished, err
+	}
+	if err := m.store.MoveCompletions(done.ID, id); err != nil {
+		return finished, err
+	}
+	return finished, m.store.MoveOverrides(done.ID, id)
+}
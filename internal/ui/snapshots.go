@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/storage"
+)
+
+func (m Model) maintenanceOptions() storage.MaintenanceOptions {
+	return storage.MaintenanceOptions{
+		BackupDir:   m.workspacePaths.BackupDir,
+		BackupEvery: m.cfg.Maintenance.BackupEvery(),
+		Keep:        m.cfg.Maintenance.BackupKeep,
+		VacuumEvery: m.cfg.Maintenance.VacuumEvery(),
+	}
+}
+
+func (m *Model) runMaintenance() {
+	report, err := m.store.RunMaintenance(m.maintenanceOptions())
+	if err != nil {
+		m.status = fmt.Sprintf("maintenance failed: %v", err)
+		return
+	}
+	if len(report.Problems) > 0 {
+		m.status = fmt.Sprintf("integrity check failed (%d problem(s)): %s — run :restore", len(report.Problems), report.Problems[0])
+	}
+}
+
+func (m Model) createBackup() (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	path, err := m.store.Backup(m.workspacePaths.BackupDir)
+	if err != nil {
+		m.status = fmt.Sprintf("backup failed: %v", err)
+		return m, nil
+	}
+	pruned, err := m.store.PruneSnapshots(m.workspacePaths.BackupDir, m.cfg.Maintenance.BackupKeep)
+	if err != nil {
+		m.status = fmt.Sprintf("backup saved to %s, prune failed: %v", path, err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("Backup saved to %s", path)
+	if pruned > 0 {
+		m.status += fmt.Sprintf(" (pruned %d old)", pruned)
+	}
+	return m, nil
+}
+
+func (m Model) enterRestoreView() (tea.Model, tea.Cmd) {
+	m.input.Blur()
+	snaps, err := m.store.ListSnapshots(m.workspacePaths.BackupDir)
+	if err != nil {
+		m.mode = modeList
+		m.status = fmt.Sprintf("snapshot list failed: %v", err)
+		return m, nil
+	}
+	infos := make([]storage.SnapshotInfo, 0, len(snaps))
+	for _, snap := range snaps {
+		info, err := m.store.InspectSnapshot(snap)
+		if err != nil {
+			info = storage.SnapshotInfo{Snapshot: snap, Integrity: []string{err.Error()}}
+		}
+		infos = append(infos, info)
+	}
+	m.snapshots = infos
+	m.snapshotCursor = 0
+	m.snapshotConfirm = false
+	m.mode = modeRestore
+	m.status = fmt.Sprintf("Restore: %d snapshot(s). enter to restore, esc to exit", len(infos))
+	return m, nil
+}
+
+func (m Model) updateRestoreMode(key string) (tea.Model, tea.Cmd) {
+	if m.snapshotConfirm {
+		switch key {
+		case "y", "Y":
+			m.snapshotConfirm = false
+			return m.restoreSnapshot()
+		case "n", "N", "esc":
+			m.snapshotConfirm = false
+			m.status = "Restore cancelled"
+		}
+		return m, nil
+	}
+	switch key {
+	case m.cfg.Keys.Cancel, "esc", m.cfg.Keys.Quit, "q":
+		m.mode = modeList
+		m.snapshots = nil
+		m.status = "Exited restore"
+	case m.cfg.Keys.Up, "up":
+		if m.snapshotCursor > 0 {
+			m.snapshotCursor--
+		}
+	case m.cfg.Keys.Down, "down":
+		m.snapshotCursor = clampCursor(m.snapshotCursor+1, len(m.snapshots))
+	case m.cfg.Keys.Confirm, "enter":
+		if len(m.snapshots) == 0 {
+			m.status = "No snapshots to restore"
+			return m, nil
+		}
+		info := m.snapshots[m.snapshotCursor]
+		if len(info.Integrity) > 0 {
+			m.status = fmt.Sprintf("snapshot is damaged: %s", info.Integrity[0])
+			return m, nil
+		}
+		m.snapshotConfirm = true
+		m.status = fmt.Sprintf("Swap in %s (%d tasks, %d open)? Current db is snapshotted first. y/n",
+			filepath.Base(info.Path), info.Tasks, info.Open)
+	}
+	return m, nil
+}
+
+func (m Model) restoreSnapshot() (tea.Model, tea.Cmd) {
+	if m.snapshotCursor >= len(m.snapshots) {
+		return m, nil
+	}
+	info := m.snapshots[m.snapshotCursor]
+	safety, err := m.store.RestoreSnapshot(info.Snapshot, m.workspacePaths.BackupDir)
+	if err != nil {
+		m.status = fmt.Sprintf("restore failed: %v", err)
+		return m, nil
+	}
+	m.tasks, err = m.store.FetchTasks()
+	if err != nil {
+		m.status = fmt.Sprintf("restore succeeded, reload failed: %v", err)
+		return m, nil
+	}
+	m.sortTasks()
+	m.selectedTasks = map[int]bool{}
+	m.cursor = clampCursor(0, len(m.visibleItems()))
+	m.mode = modeList
+	m.snapshots = nil
+	m.status = fmt.Sprintf("Restored %s (previous db saved to %s)", filepath.Base(info.Path), filepath.Base(safety))
+	return m, nil
+}
+
+func (m Model) renderRestoreView() string {
+	var b strings.Builder
+	b.WriteString(m.renderListBanner())
+	b.WriteString("\n\n")
+	b.WriteString(m.styles.Accent.Render("# Restore snapshot"))
+	b.WriteString("\n\n")
+	header := fmt.Sprintf("   %-20s %8s %6s %6s %6s %7s  %s", "Created", "Size", "Tasks", "Open", "Done", "Topics", "Check")
+	b.WriteString(m.styles.Heading.Render(header))
+	b.WriteString("\n")
+	b.WriteString(m.styles.Border.Render(m.ruleLine(max(m.width, len(header)))))
+	b.WriteString("\n")
+	if len(m.snapshots) == 0 {
+		b.WriteString(m.styles.Muted.Render(fmt.Sprintf("(no snapshots in %s — run :backup)", m.workspacePaths.BackupDir)))
+		b.WriteString("\n")
+	}
+	rows := m.snapshotRows()
+	if maxRows := m.height - 1 - 12; m.height > 0 && len(rows) > maxRows && maxRows > 0 {
+		start := clampInt(m.snapshotCursor-maxRows+1, 0, len(rows)-maxRows)
+		rows = rows[start : start+maxRows]
+	}
+	for _, row := range rows {
+		b.WriteString(row)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.styles.Muted.Render("up/down move • enter restore (with preview + confirm) • esc/q close"))
+	return b.String()
+}
+
+func (m Model) snapshotRows() []string {
+	rows := make([]string, 0, len(m.snapshots))
+	for i, info := range m.snapshots {
+		check := "ok"
+		if len(info.Integrity) > 0 {
+			check = "damaged"
+		}
+		if info.Encrypted {
+			check += " (encrypted)"
+		}
+		line := fmt.Sprintf("   %-20s %8s %6d %6d %6d %7d  %s",
+			info.CreatedAt.Local().Format("2006-01-02 15:04:05"), humanSize(info.Size), info.Tasks, info.Open, info.Done, info.Topics, check)
+		switch {
+		case i == m.snapshotCursor:
+			line = m.styles.Selection.Render(line)
+		case len(info.Integrity) > 0:
+			line = m.styles.Danger.Render(line)
+		}
+		rows = append(rows, line)
+	}
+	return rows
+}
+
+func humanSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fK", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/storage"
+)
+
+const snoozedTopic = "Snoozed"
+
+// parseWakeDate reads "+3d", "2w", "tomorrow", "until Monday", "next week"
+// or YYYY-MM-DD. Tasks always wake at the start of the day.
+func parseWakeDate(v string, now time.Time) (time.Time, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	v = strings.TrimSpace(strings.TrimPrefix(v, "until "))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch v {
+	case "":
+		return time.Time{}, errors.New("snooze until when? e.g. +3d, tomorrow, monday, 2006-01-02")
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "next week":
+		return today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7), nil
+	case "next month":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), nil
+	}
+	if day, ok := parseWeekday(strings.TrimPrefix(v, "next ")); ok {
+		ahead := (int(day) - int(today.Weekday()) + 7) % 7
+		if ahead == 0 {
+			ahead = 7
+		}
+		return today.AddDate(0, 0, ahead), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", v, now.Location()); err == nil {
+		if !t.After(today) {
+			return time.Time{}, errors.New("wake date must be in the future")
+		}
+		return t, nil
+	}
+	num := strings.TrimPrefix(v, "+")
+	if len(num) >= 2 {
+		n, err := strconv.Atoi(num[:len(num)-1])
+		if err == nil && n > 0 {
+			switch num[len(num)-1] {
+			case 'd':
+				return today.AddDate(0, 0, n), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*n), nil
+			case 'm':
+				return today.AddDate(0, n, 0), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown snooze date %q: use +3d, 2w, tomorrow, monday or YYYY-MM-DD", v)
+}
+
+func isSnoozed(t storage.Task, now time.Time) bool {
+	return t.SnoozedUntil.Valid && now.Before(t.SnoozedUntil.Time)
+}
+
+func wokeToday(t storage.Task, now time.Time) bool {
+	if !t.SnoozedUntil.Valid || isSnoozed(t, now) || t.Done {
+		return false
+	}
+	return isSameDate(t.SnoozedUntil.Time.In(now.Location()), now)
+}
+
+func snoozeBadge(t storage.Task) string {
+	if !isSnoozed(t, time.Now()) {
+		return ""
+	}
+	return fmt.Sprintf("[zz %s]", t.SnoozedUntil.Time.Local().Format("Mon 01-02"))
+}
+
+func (m Model) snoozedTasks() []storage.Task {
+	now := time.Now()
+	var out []storage.Task
+	for _, t := range m.tasks {
+		if isSnoozed(t, now) {
+			out = append(out, t)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].SnoozedUntil.Time.Before(out[j].SnoozedUntil.Time)
+	})
+	return out
+}
+
+func (m Model) startSnooze() (tea.Model, tea.Cmd) {
+	if len(m.stateTargets()) == 0 {
+		return m, nil
+	}
+	next, cmd := m.startCommand()
+	m = next.(Model)
+	m.input.SetValue("snooze ")
+	m.input.CursorEnd()
+	m.status = "Snooze until: +3d, 2w, tomorrow, monday, YYYY-MM-DD (empty wakes it)"
+	return m, cmd
+}
+
+// snoozeTasks snoozes the selection or the current task; "", "off" or "wake"
+// brings them back right away.
+func (m Model) snoozeTasks(arg string) (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	tasks := m.stateTargets()
+	if len(tasks) == 0 {
+		m.status = "No task selected"
+		return m, nil
+	}
+	var until sql.NullTime
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "", "off", "wake", "none":
+	default:
+		wake, err := parseWakeDate(arg, time.Now())
+		if err != nil {
+			m.status = err.Error()
+			return m, nil
+		}
+		until = sql.NullTime{Time: wake, Valid: true}
+	}
+	for _, t := range tasks {
+		if _, err := m.changeTask(t.ID, func(t *storage.Task) { t.SnoozedUntil = until }); err != nil {
+			_ = m.reloadTasks()
+			m.status = fmt.Sprintf("snooze failed: %v", err)
+			return m, nil
+		}
+	}
+	if err := m.reloadTasks(); err != nil {
+		m.status = fmt.Sprintf("reload failed: %v", err)
+		return m, nil
+	}
+	if len(tasks) > 1 {
+		m.selectedTasks = map[int]bool{}
+	}
+	m.cursor = clampCursor(m.cursor, len(m.visibleItems()))
+	m.status = fmt.Sprintf("%d task(s) woken", len(tasks))
+	if until.Valid {
+		m.status = fmt.Sprintf("%d task(s) snoozed until %s", len(tasks), until.Time.Format("Mon 2006-01-02"))
+	}
+	return m, nil
+}
+
+// wakeNotice names the tasks whose snooze ended today, for the status bar
+// at startup.
+func (m Model) wakeNotice(now time.Time) string {
+	var titles []string
+	for _, t := range m.tasks {
+		if wokeToday(t, now) {
+			titles = append(titles, fmt.Sprintf("#%d %s", t.ID, truncateText(t.Title, 24)))
+		}
+	}
+	if len(titles) == 0 {
+		return ""
+	}
+	const shown = 3
+	notice := "Woke up today: " + strings.Join(titles[:min(shown, len(titles))], ", ")
+	if len(titles) > shown {
+		notice += fmt.Sprintf(" (+%d more)", len(titles)-shown)
+	}
+	return notice
+}
@@ -0,0 +1,602 @@
+package ui
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/storage"
+)
+
+type calendarSpan int
+
+const (
+	calendarMonthSpan calendarSpan = iota
+	calendarWeekSpan
+	calendarDaySpan
+)
+
+const (
+	slotMinutes = 30
+	daySlots    = 24 * 60 / slotMinutes
+	// allDaySlot is the row above the hours, for tasks due on a date alone.
+	allDaySlot = -1
+)
+
+// timeBlock is where a task sits on the week and day timelines: from its
+// start time if it has one, otherwise its due time, for the length it was
+// given there (or its estimate, or from start to due, or one slot). Tasks due
+// on a date alone go in the all-day row.
+type timeBlock struct {
+	task   storage.Task
+	day    string
+	start  int
+	length int
+	allDay bool
+}
+
+func (b timeBlock) end() int {
+	return b.start + b.length
+}
+
+func (b timeBlock) covers(slot int) bool {
+	if slot == allDaySlot || b.allDay {
+		return slot == allDaySlot && b.allDay
+	}
+	from := slot * slotMinutes
+	return b.start < from+slotMinutes && b.end() > from
+}
+
+func (b timeBlock) span() string {
+	return fmt.Sprintf("%s–%s", clockLabel(b.start), clockLabel(b.end()))
+}
+
+func clockLabel(minutes int) string {
+	minutes = clampInt(minutes, 0, 24*60)
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// grabState is a block picked up on the timeline, with where the cursor
+// was when it was; the block follows the cursor until it is dropped.
+type grabState struct {
+	id   int
+	day  time.Time
+	slot int
+}
+
+func (m Model) taskBlock(t storage.Task) (timeBlock, bool) {
+	start, due := m.shownTime(t.Start), m.shownTime(t.Due)
+	b := timeBlock{task: t}
+	var at time.Time
+	switch {
+	case start.Valid && !isAllDay(start.Time):
+		at = start.Time
+		if due.Valid && !isAllDay(due.Time) && due.Time.After(at) {
+			b.length = int(due.Time.Sub(at) / time.Minute)
+		}
+	case due.Valid && !isAllDay(due.Time):
+		at = due.Time
+	case due.Valid:
+		b.day, b.allDay = due.Time.Format("2006-01-02"), true
+		return b, true
+	default:
+		return b, false
+	}
+	switch {
+	case t.BlockMinutes > 0:
+		b.length = t.BlockMinutes
+	case t.EstimateMinutes > 0:
+		b.length = t.EstimateMinutes
+	}
+	if b.length <= 0 {
+		b.length = slotMinutes
+	}
+	b.day = at.Format("2006-01-02")
+	b.start = at.Hour()*60 + at.Minute()
+	return b, true
+}
+
+// blocksOn lists the open tasks on day, all-day ones first, with a grabbed
+// task shown where it would be dropped.
+func (m Model) blocksOn(day time.Time) []timeBlock {
+	key := day.Format("2006-01-02")
+	var blocks []timeBlock
+	for _, t := range m.tasks {
+		if t.Done {
+			continue
+		}
+		if g := m.calendarGrab; g != nil && g.id == t.ID {
+			t = m.placeTask(t, *g)
+		}
+		if b, ok := m.taskBlock(t); ok && b.day == key {
+			blocks = append(blocks, b)
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		if blocks[i].allDay != blocks[j].allDay {
+			return blocks[i].allDay
+		}
+		if blocks[i].start != blocks[j].start {
+			return blocks[i].start < blocks[j].start
+		}
+		return blocks[i].task.ID < blocks[j].task.ID
+	})
+	return blocks
+}
+
+func blocksAt(blocks []timeBlock, slot int) []timeBlock {
+	var out []timeBlock
+	for _, b := range blocks {
+		if b.covers(slot) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// cursorBlock is the block under the timeline cursor; tab steps through
+// the ones that overlap there.
+func (m Model) cursorBlock() (timeBlock, bool) {
+	blocks := blocksAt(m.blocksOn(m.calendarDay), m.calendarSlot)
+	if g := m.calendarGrab; g != nil {
+		for _, b := range blocks {
+			if b.task.ID == g.id {
+				return b, true
+			}
+		}
+	}
+	if len(blocks) == 0 {
+		return timeBlock{}, false
+	}
+	return blocks[m.calendarPick%len(blocks)], true
+}
+
+// placeTask moves t the way its grabbed block moved: by whole days and
+// slots, onto the all-day row (dropping the times) or off it (taking the
+// slot's time as due).
+func (m Model) placeTask(t storage.Task, g grabState) storage.Task {
+	days := calendarDays(g.day, m.calendarDay)
+	day := m.calendarDay
+	switch {
+	case m.calendarSlot == allDaySlot && g.slot == allDaySlot:
+		t = shiftDates(t, days)
+	case m.calendarSlot == allDaySlot:
+		loc := taskLocation(t)
+		t.Due = sql.NullTime{Time: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc), Valid: true}
+		if t.Start.Valid && !isAllDay(t.Start.Time) {
+			t.Start.Time = normalizeDate(t.Start.Time.AddDate(0, 0, days))
+		}
+	case g.slot == allDaySlot:
+		loc := taskLocation(t)
+		if m.localTimes {
+			loc = time.Local
+		}
+		at := time.Date(day.Year(), day.Month(), day.Day(), 0, m.calendarSlot*slotMinutes, 0, 0, loc)
+		t.Due = sql.NullTime{Time: at, Valid: true}
+	default:
+		shift := time.Duration((m.calendarSlot-g.slot)*slotMinutes) * time.Minute
+		for _, v := range []*sql.NullTime{&t.Due, &t.Start} {
+			if !v.Valid {
+				continue
+			}
+			timed := !isAllDay(v.Time)
+			v.Time = v.Time.AddDate(0, 0, days)
+			if timed {
+				v.Time = v.Time.Add(shift)
+			}
+		}
+	}
+	return t
+}
+
+func sameTime(a, b sql.NullTime) bool {
+	return a.Valid == b.Valid && (!a.Valid || a.Time.Equal(b.Time))
+}
+
+func (m Model) openCalendar(arg string) (tea.Model, tea.Cmd) {
+	span := m.calendarView
+	switch strings.ToLower(arg) {
+	case "":
+	case "month":
+		span = calendarMonthSpan
+	case "week":
+		span = calendarWeekSpan
+	case "day":
+		span = calendarDaySpan
+	default:
+		m.mode = modeList
+		m.input.Blur()
+		m.status = fmt.Sprintf("unknown calendar view %q: use month, week or day", arg)
+		return m, nil
+	}
+	m.calendarView = span
+	return m.enterCalendarView()
+}
+
+func (m Model) setCalendarSpan(span calendarSpan) Model {
+	m.calendarView = span
+	m.calendarDetail = false
+	m.calendarGrab = nil
+	m.calendarPick = 0
+	switch span {
+	case calendarWeekSpan:
+		m.status = "Week view"
+	case calendarDaySpan:
+		m.status = "Day view"
+	default:
+		m.status = "Calendar view"
+		return m
+	}
+	// Open on the working day, or on the current time when that is later.
+	workStart, _ := m.cfg.Planning.Hours()
+	m.calendarSlot = workStart / slotMinutes
+	if now := time.Now(); isSameDate(m.calendarDay, now) {
+		m.calendarSlot = max(m.calendarSlot, (now.Hour()*60+now.Minute())/slotMinutes)
+	}
+	m.calendarScroll = workStart / slotMinutes
+	return m.keepSlotVisible()
+}
+
+func (m Model) timelineRows() int {
+	if m.height <= 0 {
+		return 20
+	}
+	// title, gap, day header, all-day row, gap, selection, key help
+	return max(4, m.height-1-7)
+}
+
+func (m Model) keepSlotVisible() Model {
+	rows := m.timelineRows()
+	if m.calendarSlot >= 0 {
+		if m.calendarSlot < m.calendarScroll {
+			m.calendarScroll = m.calendarSlot
+		}
+		if m.calendarSlot >= m.calendarScroll+rows {
+			m.calendarScroll = m.calendarSlot - rows + 1
+		}
+	}
+	m.calendarScroll = clampInt(m.calendarScroll, 0, max(0, daySlots-rows))
+	return m
+}
+
+func (m Model) updateTimelineMode(key string) (tea.Model, tea.Cmd) {
+	moveDay := func(days int) {
+		m.calendarDay = m.calendarDay.AddDate(0, 0, days)
+		m.calendarMonth = time.Date(m.calendarDay.Year(), m.calendarDay.Month(), 1, 0, 0, 0, 0, m.calendarDay.Location())
+		m.calendarPick = 0
+	}
+	moveSlot := func(slots int) {
+		if m.calendarSlot == allDaySlot {
+			if slots < 0 {
+				return
+			}
+			m.calendarSlot = m.calendarScroll - 1
+		}
+		m.calendarSlot = clampInt(m.calendarSlot+slots, allDaySlot, daySlots-1)
+		m.calendarPick = 0
+	}
+	switch key {
+	case "esc", m.cfg.Keys.Quit, "q":
+		if m.calendarGrab != nil {
+			m.calendarGrab = nil
+			m.status = "Move cancelled"
+			return m, nil
+		}
+		m.mode = modeList
+		m.status = "Calendar closed"
+		return m, nil
+	case "m":
+		return m.setCalendarSpan(calendarMonthSpan), nil
+	case "w":
+		return m.setCalendarSpan(calendarWeekSpan), nil
+	case "d":
+		return m.setCalendarSpan(calendarDaySpan), nil
+	case "h", "left":
+		moveDay(-1)
+	case "l", "right":
+		moveDay(1)
+	case "H":
+		moveDay(-7)
+	case "L":
+		moveDay(7)
+	case "j", "down":
+		moveSlot(1)
+	case "k", "up":
+		moveSlot(-1)
+	case "J":
+		moveSlot(60 / slotMinutes)
+	case "K":
+		moveSlot(-60 / slotMinutes)
+	case "a":
+		if m.calendarSlot == allDaySlot {
+			m.calendarSlot = m.calendarScroll
+		} else {
+			m.calendarSlot = allDaySlot
+		}
+		m.calendarPick = 0
+	case "tab":
+		m.calendarPick++
+	case "enter", " ":
+		if m.calendarGrab != nil {
+			return m.dropBlock()
+		}
+		b, ok := m.cursorBlock()
+		if !ok {
+			m.status = "No task here to move"
+			return m, nil
+		}
+		m.calendarGrab = &grabState{id: b.task.ID, day: m.calendarDay, slot: m.calendarSlot}
+		m.status = fmt.Sprintf("Moving #%d: h/j/k/l to place it, enter to drop, esc to cancel", b.task.ID)
+		return m, nil
+	case "+", "=":
+		return m.resizeBlock(1)
+	case "-":
+		return m.resizeBlock(-1)
+	case ":":
+		return m.startCommand()
+	default:
+		return m, nil
+	}
+	return m.keepSlotVisible(), nil
+}
+
+// dropBlock saves a grabbed task where its block now is. A recurring task
+// put on another day has only this occurrence moved, as with :reschedule.
+func (m Model) dropBlock() (tea.Model, tea.Cmd) {
+	g := *m.calendarGrab
+	m.calendarGrab = nil
+	idx := m.findTaskIndex(g.id)
+	if idx < 0 || idx >= len(m.tasks) || m.tasks[idx].ID != g.id {
+		m.status = fmt.Sprintf("#%d is gone", g.id)
+		return m, nil
+	}
+	t := m.tasks[idx]
+	moved := m.placeTask(t, g)
+	if sameTime(moved.Due, t.Due) && sameTime(moved.Start, t.Start) {
+		m.status = fmt.Sprintf("#%d stays where it was", t.ID)
+		return m, nil
+	}
+	if err := m.saveBlock(t, moved); err != nil {
+		m.status = fmt.Sprintf("move failed: %v", err)
+		return m, nil
+	}
+	where := m.calendarDay.Format("Mon 01-02")
+	if b, ok := m.taskBlock(moved); ok && !b.allDay {
+		where += " " + b.span()
+	}
+	m.status = fmt.Sprintf("#%d moved to %s", t.ID, where)
+	return m, nil
+}
+
+func (m *Model) saveBlock(t, moved storage.Task) error {
+	before, _ := recurrenceBaseDate(t)
+	after, _ := recurrenceBaseDate(moved)
+	if !isRecurringTask(t) || calendarDays(before, after) == 0 {
+		_, err := m.changeTask(t.ID, func(t *storage.Task) {
+			t.Due, t.Start = moved.Due, moved.Start
+		})
+		if reloadErr := m.reloadTasks(); err == nil {
+			err = reloadErr
+		}
+		return err
+	}
+	_, occurrence, _ := unmoved(t)
+	if o, ok := pendingOverride(t); ok && o.Kind == storage.OverrideMove && calendarDays(occurrence, after) == 0 {
+		// Back on the day its rule gives: the move is undone, the new time kept.
+		return m.revertOverride(t.ID, moved.Due, moved.Start, t.RecurrenceRule, o.ID)
+	}
+	override := storage.Override{TaskID: t.ID, Kind: storage.OverrideMove, Occurrence: occurrence, MovedTo: plainDate(after),
+		PrevDue: t.Due, PrevStart: t.Start, PrevRule: t.RecurrenceRule}
+	return m.overrideOccurrence(t.ID, moved.Due, moved.Start, t.RecurrenceRule, override)
+}
+
+// resizeBlock lengthens or shortens the task under the cursor by whole
+// slots. The length is kept apart from the estimate.
+func (m Model) resizeBlock(slots int) (tea.Model, tea.Cmd) {
+	if m.calendarGrab != nil {
+		return m, nil
+	}
+	b, ok := m.cursorBlock()
+	if !ok || b.allDay {
+		m.status = "No timed task here to resize"
+		return m, nil
+	}
+	length := max(slotMinutes, b.length+slots*slotMinutes)
+	_, err := m.changeTask(b.task.ID, func(t *storage.Task) {
+		t.BlockMinutes = length
+	})
+	if reloadErr := m.reloadTasks(); err == nil {
+		err = reloadErr
+	}
+	if err != nil {
+		m.status = fmt.Sprintf("resize failed: %v", err)
+		return m, nil
+	}
+	b.length = length
+	m.status = fmt.Sprintf("#%d now %s (%s)", b.task.ID, b.span(), formatEstimate(length))
+	return m, nil
+}
+
+func (m Model) timelineDays() []time.Time {
+	if m.calendarView == calendarDaySpan {
+		return []time.Time{m.calendarDay}
+	}
+	start := startOfWeek(m.calendarDay, time.Monday)
+	days := make([]time.Time, 7)
+	for i := range days {
+		days[i] = start.AddDate(0, 0, i)
+	}
+	return days
+}
+
+func (m Model) renderTimeline() string {
+	days := m.timelineDays()
+	title := "Calendar • " + m.calendarDay.Format("Mon, Jan 2, 2006")
+	if m.calendarView == calendarWeekSpan {
+		title = "Calendar • week of " + days[0].Format("Jan 2, 2006")
+	}
+	if m.localTimes {
+		title += " • our time"
+	}
+	width := m.width
+	if width <= 0 {
+		width = 100
+	}
+	const gutter = 7
+	colWidth := max(8, (width-gutter)/len(days))
+	workStart, workEnd := m.cfg.Planning.Hours()
+	now := time.Now()
+
+	blocks := make([][]timeBlock, len(days))
+	for i, day := range days {
+		blocks[i] = m.blocksOn(day)
+	}
+	grabbed := 0
+	if m.calendarGrab != nil {
+		grabbed = m.calendarGrab.id
+	}
+	picked, hasPick := m.cursorBlock()
+
+	var b strings.Builder
+	b.WriteString(m.styles.Accent.Render(title))
+	b.WriteString("\n\n")
+
+	b.WriteString(strings.Repeat(" ", gutter))
+	for _, day := range days {
+		label := day.Format("Mon 01-02")
+		style := m.styles.Border
+		if load := totalEstimate(m.tasksForDay(day)); load > 0 {
+			label += " " + formatEstimate(load)
+			if m.overloaded(load) {
+				label += "!"
+				style = m.styles.Danger
+			}
+		}
+		if isSameDate(day, now) {
+			style = m.styles.Warning
+		}
+		if isSameDate(day, m.calendarDay) {
+			style = m.styles.Heading
+		}
+		b.WriteString(style.Render(padRightWidth(truncateTextWidth(label, colWidth-1), colWidth)))
+	}
+	b.WriteString("\n")
+
+	row := func(slot int, label string, labelStyle func(...string) string) {
+		b.WriteString(labelStyle(padRightWidth(label, gutter)))
+		for i, day := range days {
+			text, style := m.timelineCell(blocks[i], slot, colWidth-1, grabbed)
+			if slot >= 0 && text == "" && (slot*slotMinutes < workStart || slot*slotMinutes >= workEnd) {
+				text, style = "·", m.styles.Border.Render
+			}
+			cell := padRightWidth(truncateTextWidth(text, colWidth-1), colWidth-1)
+			if isSameDate(day, m.calendarDay) && slot == m.calendarSlot {
+				style = m.styles.Selection.Render
+			}
+			b.WriteString(style(cell) + " ")
+		}
+		b.WriteString("\n")
+	}
+	row(allDaySlot, "all", m.styles.Muted.Render)
+	rows := m.timelineRows()
+	for slot := m.calendarScroll; slot < min(daySlots, m.calendarScroll+rows); slot++ {
+		minutes := slot * slotMinutes
+		label, style := "   :30", m.styles.Muted.Render
+		if minutes%60 == 0 {
+			label = clockLabel(minutes)
+			if minutes >= workStart && minutes < workEnd {
+				style = m.styles.Border.Render
+			}
+		}
+		if current := now.Hour()*60 + now.Minute(); current >= minutes && current < minutes+slotMinutes && m.timelineShowsToday(days) {
+			label, style = clockLabel(current), m.styles.Warning.Render
+		}
+		row(slot, label, style)
+	}
+	b.WriteString("\n")
+	switch {
+	case hasPick && m.calendarGrab != nil:
+		b.WriteString(m.styles.Warning.Render(fmt.Sprintf("Moving #%d %s → %s", picked.task.ID, truncateText(picked.task.Title, 40), m.blockWhen(picked))))
+	case hasPick:
+		info := fmt.Sprintf("#%d %s • %s", picked.task.ID, truncateText(picked.task.Title, 40), m.blockWhen(picked))
+		if n := len(blocksAt(m.blocksOn(m.calendarDay), m.calendarSlot)); n > 1 {
+			info += fmt.Sprintf(" • %d/%d here (tab)", m.calendarPick%n+1, n)
+		}
+		b.WriteString(m.styles.Border.Render(info))
+	default:
+		b.WriteString(m.styles.Muted.Render(m.calendarDay.Format("Mon 01-02") + " " + m.slotLabel()))
+	}
+	b.WriteString("\n")
+	b.WriteString(m.styles.Muted.Render("h/l day • j/k 30m • J/K hour • H/L week • a all-day • tab next • enter grab/drop • +/- length • m/w/d view • esc/q close"))
+	return b.String()
+}
+
+func (m Model) timelineShowsToday(days []time.Time) bool {
+	for _, day := range days {
+		if isSameDate(day, time.Now()) {
+			return true
+		}
+	}
+	return false
+}
+
+// timelineCell draws one slot of a day: the first block there, with its
+// time and title on its first row and a bar below, and a count of any
+// others that overlap it.
+func (m Model) timelineCell(blocks []timeBlock, slot, width, grabbed int) (string, func(...string) string) {
+	here := blocksAt(blocks, slot)
+	if len(here) == 0 {
+		return "", m.styles.Muted.Render
+	}
+	first := here[0]
+	style := m.styles.Accent.Render
+	if first.task.ID == grabbed {
+		style = m.styles.Warning.Render
+	}
+	more := ""
+	if len(here) > 1 {
+		more = fmt.Sprintf(" +%d", len(here)-1)
+	}
+	var text string
+	switch {
+	case first.allDay:
+		text = first.task.Title
+	case first.start >= slot*slotMinutes || slot == m.calendarScroll:
+		text = "▌" + clockLabel(first.start) + " " + first.task.Title
+		if m.calendarView == calendarDaySpan {
+			text = fmt.Sprintf("▌%s #%d %s", first.span(), first.task.ID, first.task.Title)
+			if est := formatEstimate(first.length); est != "" {
+				text += " (" + est + ")"
+			}
+			if len(first.task.Topics) > 0 {
+				text += " [" + strings.Join(first.task.Topics, ",") + "]"
+			}
+		}
+	default:
+		text = "▌"
+	}
+	if more != "" {
+		text = truncateTextWidth(text, max(1, width-len(more))) + more
+	}
+	return text, style
+}
+
+func (m Model) blockWhen(b timeBlock) string {
+	day, _ := time.Parse("2006-01-02", b.day)
+	when := day.Format("Mon 01-02")
+	if b.allDay {
+		return when + " all day"
+	}
+	return when + " " + b.span()
+}
+
+func (m Model) slotLabel() string {
+	if m.calendarSlot == allDaySlot {
+		return "all day"
+	}
+	return clockLabel(m.calendarSlot * slotMinutes)
+}
@@ -0,0 +1,271 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/storage"
+)
+
+type timerTickMsg time.Time
+
+func timerTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return timerTickMsg(t) })
+}
+
+// handleTimerTick re-reads the running timer so stops from elsewhere (a
+// deleted task, the CLI) show up, and keeps ticking only while one runs.
+func (m Model) handleTimerTick() (tea.Model, tea.Cmd) {
+	m.refreshTimer()
+	if m.timer == nil {
+		m.ticking = false
+		return m, nil
+	}
+	return m, timerTick()
+}
+
+func (m *Model) refreshTimer() {
+	running, ok, err := m.store.RunningTimer()
+	if err != nil || !ok {
+		m.timer = nil
+		return
+	}
+	m.timer = &running
+}
+
+func (m Model) ensureTicking() (Model, tea.Cmd) {
+	if m.timer == nil || m.ticking {
+		return m, nil
+	}
+	m.ticking = true
+	return m, timerTick()
+}
+
+func (m Model) toggleTimer() (tea.Model, tea.Cmd) {
+	task, ok := m.currentTask()
+	if !ok {
+		if m.timer == nil {
+			return m, nil
+		}
+		return m.stopTimer()
+	}
+	if m.timer != nil && m.timer.TaskID == task.ID {
+		return m.stopTimer()
+	}
+	stopped, wasRunning, err := m.store.StartTimer(task.ID)
+	if err != nil {
+		m.status = fmt.Sprintf("timer failed: %v", err)
+		return m, nil
+	}
+	m.refreshTimer()
+	m.status = fmt.Sprintf("Timer started on #%d", task.ID)
+	if wasRunning {
+		m.status += fmt.Sprintf(" (stopped #%d after %s)", stopped.TaskID, storage.FormatClock(stopped.Duration(time.Now())))
+	}
+	return m.ensureTicking()
+}
+
+func (m Model) stopTimer() (tea.Model, tea.Cmd) {
+	stopped, ok, err := m.store.StopTimer()
+	if err != nil {
+		m.status = fmt.Sprintf("timer failed: %v", err)
+		return m, nil
+	}
+	m.timer = nil
+	if ok {
+		m.status = fmt.Sprintf("Timer stopped on #%d after %s", stopped.TaskID, storage.FormatClock(stopped.Duration(time.Now())))
+	}
+	return m, nil
+}
+
+func (m Model) timerLabel() string {
+	if m.timer == nil {
+		return ""
+	}
+	return fmt.Sprintf("[⏱ %s #%d %s]", storage.FormatClock(m.timer.Duration(time.Now())), m.timer.TaskID, truncateText(m.timer.Title, 24))
+}
+
+func (m Model) enterTimesheetView(arg string) (tea.Model, tea.Cmd) {
+	m.input.Blur()
+	from, to, err := storage.ParseTimeRange(arg, time.Now())
+	if err != nil {
+		m.mode = modeList
+		m.status = err.Error()
+		return m, nil
+	}
+	m.timesheetFrom, m.timesheetTo = from, to
+	if err := m.loadTimesheet(); err != nil {
+		m.mode = modeList
+		m.status = fmt.Sprintf("timesheet failed: %v", err)
+		return m, nil
+	}
+	m.timesheetScroll = 0
+	m.mode = modeTimesheet
+	m.status = "Timesheet: h/l previous/next range • e export CSV • esc close"
+	return m, nil
+}
+
+func (m *Model) loadTimesheet() error {
+	entries, err := m.store.TimeEntries(m.timesheetFrom, m.timesheetTo)
+	if err != nil {
+		return err
+	}
+	m.timesheetEntries = entries
+	return nil
+}
+
+func (m Model) updateTimesheetMode(key string) (tea.Model, tea.Cmd) {
+	if m.processScrollKey(key, m.timesheetMaxScroll(), &m.timesheetScroll) {
+		return m, nil
+	}
+	switch key {
+	case "esc", m.cfg.Keys.Quit, "q":
+		m.mode = modeList
+		m.timesheetEntries = nil
+		m.status = "Timesheet closed"
+	case m.cfg.Keys.Up, "up":
+		if m.timesheetScroll > 0 {
+			m.timesheetScroll--
+		}
+	case m.cfg.Keys.Down, "down":
+		m.timesheetScroll = clampInt(m.timesheetScroll+1, 0, m.timesheetMaxScroll())
+	case "h", "left", "l", "right":
+		span := m.timesheetTo.Sub(m.timesheetFrom)
+		days := int(span.Round(24*time.Hour) / (24 * time.Hour))
+		if key == "h" || key == "left" {
+			days = -days
+		}
+		if m.timesheetFrom.Day() == 1 && m.timesheetTo.Day() == 1 && days*days >= 28*28 {
+			months := 1
+			if days < 0 {
+				months = -1
+			}
+			m.timesheetFrom = m.timesheetFrom.AddDate(0, months, 0)
+			m.timesheetTo = m.timesheetTo.AddDate(0, months, 0)
+		} else {
+			m.timesheetFrom = m.timesheetFrom.AddDate(0, 0, days)
+			m.timesheetTo = m.timesheetTo.AddDate(0, 0, days)
+		}
+		if err := m.loadTimesheet(); err != nil {
+			m.status = fmt.Sprintf("timesheet failed: %v", err)
+		}
+		m.timesheetScroll = 0
+	case "e":
+		path, err := m.exportTimesheet()
+		if err != nil {
+			m.status = fmt.Sprintf("export failed: %v", err)
+			return m, nil
+		}
+		m.status = fmt.Sprintf("Exported %d entries to %s", len(m.timesheetEntries), path)
+	}
+	return m, nil
+}
+
+func (m Model) exportTimesheet() (string, error) {
+	last := m.timesheetTo.AddDate(0, 0, -1)
+	name := fmt.Sprintf("timesheet-%s-%s.csv", m.timesheetFrom.Format("20060102"), last.Format("20060102"))
+	path := filepath.Join(filepath.Dir(m.store.DBPath()), name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := storage.WriteTimeCSV(f, m.timesheetEntries, time.Now()); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+func (m Model) timesheetLines() []string {
+	sum := storage.SummarizeTime(m.timesheetEntries, m.timesheetFrom, m.timesheetTo, time.Now())
+	var lines []string
+	section := func(title string, totals []storage.TimeTotal) {
+		lines = append(lines, m.styles.Heading.Render(title))
+		if len(totals) == 0 {
+			lines = append(lines, m.styles.Muted.Render("  (no time tracked)"), "")
+			return
+		}
+		for _, t := range totals {
+			lines = append(lines, fmt.Sprintf("  %-24s %10s  %s", truncateText(t.Key, 24), storage.FormatClock(t.Duration), m.timeBar(t.Duration, sum.Total)))
+		}
+		lines = append(lines, "")
+	}
+	section("By day", sum.ByDay)
+	section("By topic", sum.ByTopic)
+	section("By tag", sum.ByTag)
+	lines = append(lines, m.styles.Heading.Render("Entries"))
+	now := time.Now()
+	for _, e := range m.timesheetEntries {
+		end := "running"
+		if e.End.Valid {
+			end = e.End.Time.Local().Format("15:04")
+		}
+		line := fmt.Sprintf("  %s %s-%-7s %10s  #%d %s", e.Start.Local().Format("2006-01-02"), e.Start.Local().Format("15:04"), end,
+			storage.FormatClock(e.Duration(now)), e.TaskID, e.Title)
+		if e.Running() {
+			line = m.styles.Accent.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(m.timesheetEntries) == 0 {
+		lines = append(lines, m.styles.Muted.Render(fmt.Sprintf("  (none — press %s on a task to start a timer)", m.cfg.Keys.Timer)))
+	}
+	return lines
+}
+
+func (m Model) timeBar(d, total time.Duration) string {
+	if total <= 0 {
+		return ""
+	}
+	width := int(20 * d / total)
+	return m.styles.Accent.Render(strings.Repeat("█", width))
+}
+
+func (m Model) timesheetHeader() string {
+	sum := storage.SummarizeTime(m.timesheetEntries, m.timesheetFrom, m.timesheetTo, time.Now())
+	last := m.timesheetTo.AddDate(0, 0, -1)
+	return m.renderListBanner() + "\n\n" +
+		m.styles.Accent.Render(fmt.Sprintf("# Timesheet %s → %s   total %s",
+			m.timesheetFrom.Format("2006-01-02"), last.Format("2006-01-02"), storage.FormatClock(sum.Total))) + "\n\n"
+}
+
+const timesheetFooter = "h/l previous/next range • j/k scroll • e export CSV • esc/q close"
+
+func (m Model) timesheetMaxScroll() int {
+	if m.height <= 0 {
+		return 0
+	}
+	bodyMax := m.height - 1 - countLines(m.timesheetHeader()) - 2
+	lines := m.timesheetLines()
+	if bodyMax <= 0 || len(lines) <= bodyMax {
+		return 0
+	}
+	return len(lines) - bodyMax
+}
+
+func (m Model) renderTimesheetView() string {
+	var b strings.Builder
+	b.WriteString(m.timesheetHeader())
+	lines := m.timesheetLines()
+	if m.height > 0 {
+		bodyMax := m.height - 1 - countLines(m.timesheetHeader()) - 2
+		start := clampInt(m.timesheetScroll, 0, m.timesheetMaxScroll())
+		end := len(lines)
+		if bodyMax > 0 && start+bodyMax < end {
+			end = start + bodyMax
+		}
+		lines = lines[start:end]
+	}
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.styles.Muted.Render(timesheetFooter))
+	return b.String()
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -18,6 +17,8 @@ import (
 	"github.com/mattn/go-runewidth"
 
 	"bada/internal/config"
+	"bada/internal/query"
+	"bada/internal/recur"
 	"bada/internal/storage"
 )
 
@@ -37,6 +38,13 @@ const (
 	modeCalendar
 	modeHelp
 	modeGantt
+	modeRestore
+	modeWorkspace
+	modeTimesheet
+	modeFocus
+	modeBoard
+	modePager
+	modeFinder
 )
 
 type noteKind int
@@ -61,8 +69,9 @@ type noteTarget struct {
 }
 
 type noteState struct {
-	target noteTarget
-	body   string
+	target      noteTarget
+	body        string
+	completions []storage.Completion
 }
 
 type noteEditedMsg struct {
@@ -97,6 +106,12 @@ type metaState struct {
 	timezone      string
 	rule          string
 	interval      string
+	estimate      string
+	status        string
+	reminders     string
+	onComplete    string
+	ends          string
+	skip          string
 	recurring     bool
 	index         int
 	completions   []string
@@ -105,58 +120,99 @@ type metaState struct {
 }
 
 type Model struct {
-	store          *storage.Store
-	cfg            config.Config
-	configPath     string
-	tasks          []storage.Task
-	trash          []storage.TrashEntry
-	cursor         int
-	navBuf         string
-	trashCursor    int
-	mode           mode
-	report         string
-	recentLimit    int
-	input          textinput.Model
-	status         string
-	filterDone     string
-	sortMode       string
-	sortBuf        string
-	pendingSort    bool
-	currentTopic   string
-	searchQuery    string
-	styles         uiStyles
-	width          int
-	height         int
-	noteScroll     int
-	noteConfirm    bool
-	notePending    noteTarget
-	confirmDel     bool
-	pendingDel     *storage.Task
-	pendingBatch   []storage.Task
-	reportScroll   int
-	trashScroll    int
-	confirmTopic   bool
-	pendingTopic   string
-	trashSelected  map[int]bool
-	trashConfirm   bool
-	trashPending   []storage.TrashEntry
-	selectedTasks  map[int]bool
-	meta           *metaState
-	note           *noteState
-	renameID       int
-	renameTopic    string
-	renameIsTopic  bool
-	calendarMonth  time.Time
-	calendarDay    time.Time
-	calendarDetail bool
-	helpScroll     int
-	ganttScroll    int
-	configStage    configStage
-	pendingCfgPath string
-	pendingDBPath  string
-}
-
-func Run(store *storage.Store, cfg config.Config, configPath string, firstLaunch bool) error {
+	store            *storage.Store
+	cfg              config.Config
+	configPath       string
+	tasks            []storage.Task
+	trash            []storage.TrashEntry
+	cursor           int
+	navBuf           string
+	trashCursor      int
+	mode             mode
+	report           string
+	recentLimit      int
+	input            textinput.Model
+	status           string
+	filterDone       string
+	sortMode         string
+	statusBuf        bool
+	hookErr          string
+	postHooks        []postHook
+	hooksRunning     bool
+	pager            *pagerState
+	viewPrevSort     string
+	searchHelp       bool
+	finder           *finderState
+	boardByTopic     bool
+	boardScope       string
+	boardCol         int
+	boardRow         int
+	sortBuf          string
+	pendingSort      bool
+	currentTopic     string
+	searchQuery      string
+	styles           uiStyles
+	width            int
+	height           int
+	noteScroll       int
+	noteCheck        int
+	noteConfirm      bool
+	notePending      noteTarget
+	confirmDel       bool
+	pendingDel       *storage.Task
+	pendingBatch     []storage.Task
+	reportScroll     int
+	trashScroll      int
+	confirmTopic     bool
+	pendingTopic     string
+	trashSelected    map[int]bool
+	trashConfirm     bool
+	trashPending     []storage.TrashEntry
+	selectedTasks    map[int]bool
+	meta             *metaState
+	note             *noteState
+	renameID         int
+	renameTopic      string
+	renameIsTopic    bool
+	calendarMonth    time.Time
+	calendarDay      time.Time
+	calendarDetail   bool
+	calendarView     calendarSpan
+	calendarSlot     int
+	calendarScroll   int
+	calendarPick     int
+	calendarGrab     *grabState
+	helpScroll       int
+	ganttScroll      int
+	ganttEffort      bool
+	localTimes       bool
+	configStage      configStage
+	pendingCfgPath   string
+	pendingDBPath    string
+	snapshots        []storage.SnapshotInfo
+	snapshotCursor   int
+	snapshotConfirm  bool
+	workspace        string
+	workspacePaths   config.Workspace
+	workspaceNames   []string
+	workspaceCursor  int
+	workspaceMove    bool
+	timer            *storage.TimeEntry
+	ticking          bool
+	timesheetFrom    time.Time
+	timesheetTo      time.Time
+	timesheetScroll  int
+	timesheetEntries []storage.TimeEntry
+	focus            *focusState
+	focusGen         int
+}
+
+func Run(store *storage.Store, cfg config.Config, configPath, workspace string, firstLaunch bool) error {
+	workspace, paths, err := cfg.ResolveWorkspace(workspace)
+	if err != nil {
+		return err
+	}
+
 	tasks, err := store.FetchTasks()
 	if err != nil {
 		return err
@@ -169,24 +225,32 @@ func Run(store *storage.Store, cfg config.Config, configPath string, firstLaunch
 	ti.Prompt = ""
 
 	m := Model{
-		store:         store,
-		cfg:           cfg,
-		configPath:    configPath,
-		tasks:         tasks,
-		cursor:        clampCursor(0, len(tasks)),
-		trashSelected: map[int]bool{},
-		selectedTasks: map[int]bool{},
-		status:        "",
-		input:         ti,
-		mode:          modeReport,
-		recentLimit:   5,
-		filterDone:    strings.ToLower(cfg.DefaultFilter),
-		sortMode:      "auto",
-		currentTopic:  "",
-		styles:        buildStyles(cfg.Theme),
+		store:          store,
+		cfg:            cfg,
+		configPath:     configPath,
+		tasks:          tasks,
+		cursor:         clampCursor(0, len(tasks)),
+		trashSelected:  map[int]bool{},
+		selectedTasks:  map[int]bool{},
+		status:         "",
+		input:          ti,
+		mode:           modeReport,
+		recentLimit:    5,
+		filterDone:     strings.ToLower(cfg.DefaultFilter),
+		sortMode:       "auto",
+		currentTopic:   "",
+		styles:         buildStyles(cfg.Theme),
+		workspace:      workspace,
+		workspacePaths: paths,
 	}
 	m.sortTasks()
 	m.refreshReport()
+	m.runMaintenance()
+	m.refreshTimer()
+	m.ticking = m.timer != nil
+	if notice := m.wakeNotice(time.Now()); notice != "" {
+		m.status = strings.TrimSuffix(notice+" • "+m.status, " • ")
+	}
 	if firstLaunch {
 		m, _ = m.startConfig()
 	}
@@ -197,13 +261,36 @@ func Run(store *storage.Store, cfg config.Config, configPath string, firstLaunch
 }
 
 func (m Model) Init() tea.Cmd {
+	if m.ticking {
+		return timerTick()
+	}
 	return nil
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(Model); ok {
+		nm, hooks := nm.startPostHooks()
+		if hooks != nil {
+			return nm, tea.Batch(cmd, hooks)
+		}
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case postHooksDoneMsg:
+		return m.handlePostHooksDone(msg)
+	case customCommandMsg:
+		return m.handleCustomCommandResult(msg)
 	case noteEditedMsg:
 		return m.handleNoteEdited(msg)
+	case timerTickMsg:
+		return m.handleTimerTick()
+	case focusTickMsg:
+		return m.handleFocusTick(msg)
 	case tea.KeyMsg:
 		if m.meta != nil {
 			return m.updateMetadataMode(msg.String(), msg)
@@ -229,6 +316,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.mode == modeTrash {
 			return m.updateTrashMode(msg.String(), msg)
 		}
+		if m.mode == modeRestore {
+			return m.updateRestoreMode(msg.String())
+		}
+		if m.mode == modeWorkspace {
+			return m.updateWorkspaceMode(msg.String())
+		}
+		if m.mode == modeTimesheet {
+			return m.updateTimesheetMode(msg.String())
+		}
+		if m.mode == modeFocus {
+			return m.updateFocusMode(msg.String())
+		}
+		if m.mode == modeBoard {
+			return m.updateBoardMode(msg.String())
+		}
+		if m.mode == modePager {
+			return m.updatePagerMode(msg.String())
+		}
+		if m.mode == modeFinder {
+			return m.updateFinderMode(msg.String(), msg)
+		}
 		if m.mode == modeRename {
 			return m.updateRenameMode(msg.String(), msg)
 		}
@@ -264,6 +372,9 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.mode == modeCommand {
 		return m.updateCommandMode(key, msg)
 	}
+	if ok, cmd := m.processStatusKey(key); ok {
+		return m, cmd
+	}
 	if m.processNavKey(key) {
 		return m, nil
 	}
@@ -286,7 +397,7 @@ func (m Model) updateAddMode(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.status = "Title cannot be empty"
 			return m, nil
 		}
-		taskID, err := m.store.AddTask(title)
+		taskID, err := m.createTask(storage.Task{Title: title})
 		if err != nil {
 			m.status = fmt.Sprintf("save failed: %v", err)
 			return m, nil
@@ -332,7 +443,7 @@ func (m Model) updateListMode(key string) (tea.Model, tea.Cmd) {
 	case "h", "left":
 		if m.currentTopic != "" {
 			prevTopic := m.currentTopic
-			m.currentTopic = ""
+			m.leaveTopic()
 			m.cursor = clampCursor(m.findTopicIndex(prevTopic), len(m.visibleItems()))
 			m.status = "Back to root"
 		}
@@ -347,25 +458,32 @@ func (m Model) updateListMode(key string) (tea.Model, tea.Cmd) {
 		}
 	case m.cfg.Keys.Add:
 		return m.startMetadataAdd()
+	case m.cfg.Keys.Open:
+		if task, ok := m.currentTask(); ok {
+			return m.openAttachment(task, "")
+		}
+	case m.cfg.Keys.Timer:
+		return m.toggleTimer()
+	case m.cfg.Keys.Focus:
+		return m.enterFocusView()
+	case m.cfg.Keys.Snooze:
+		return m.startSnooze()
+	case m.cfg.Keys.Finder:
+		return m.enterFinder()
+	case m.cfg.Keys.SkipNext:
+		return m.skipOccurrence()
+	case m.cfg.Keys.Reschedule:
+		return m.startReschedule()
+	case m.cfg.Keys.UndoOverride:
+		return m.undoOccurrence()
+	case m.cfg.Keys.ZoneToggle:
+		return m.toggleZoneDisplay()
 	case m.cfg.Keys.Toggle:
 		task, ok := m.currentTask()
 		if !ok {
 			return m, nil
 		}
-		err := m.store.SetDone(task.ID, !task.Done)
-		if err != nil {
-			m.status = fmt.Sprintf("toggle failed: %v", err)
-			return m, nil
-		}
-		m.tasks, err = m.store.FetchTasks()
-		if err == nil {
-			m.sortTasks()
-			vis = m.visibleItems()
-			m.cursor = clampCursor(m.cursor, len(vis))
-			m.status = "Toggled task"
-		} else {
-			m.status = fmt.Sprintf("reload failed: %v", err)
-		}
+		return m.toggleTaskState(task)
 	case " ":
 		if task, ok := m.currentTask(); ok {
 			m.toggleTaskSelection(task.ID)
@@ -446,7 +564,8 @@ func (m Model) updateListMode(key string) (tea.Model, tea.Cmd) {
 			m.status = "No task selected"
 			return m, nil
 		}
-		info := fmt.Sprintf("Task #%d • %s • %s", task.ID, task.Title, humanDone(task.Done))
+		st, _ := m.taskState(task)
+		info := fmt.Sprintf("Task #%d • %s • %s %s", task.ID, task.Title, st.Symbol, st.Name)
 		if len(task.Topics) > 0 {
 			info += " • topics:" + strings.Join(task.Topics, ",")
 		}
@@ -457,10 +576,10 @@ func (m Model) updateListMode(key string) (tea.Model, tea.Cmd) {
 			info += fmt.Sprintf(" • priority:%d", task.Priority)
 		}
 		if task.Due.Valid {
-			info += " • due:" + formatDateTime(task.Due) + overdueDetail(task)
+			info += " • due:" + m.formatWhen(task.Due) + overdueDetail(task)
 		}
 		if task.Start.Valid {
-			info += " • start:" + task.Start.Time.Format("2006-01-02")
+			info += " • start:" + formatDate(m.shownTime(task.Start))
 		}
 		if recSummary := recurrenceSummary(task); recSummary != "" {
 			info += " • recur:" + recSummary
@@ -477,23 +596,29 @@ func (m Model) updateListMode(key string) (tea.Model, tea.Cmd) {
 		m.sortMode = "due"
 		m.sortTasks()
 		m.status = "Sorted by due date"
+		m.rememberViewSort()
 	case m.cfg.Keys.SortPriority:
 		m.sortMode = "priority"
 		m.sortTasks()
 		m.status = "Sorted by priority"
+		m.rememberViewSort()
 	case m.cfg.Keys.SortCreated:
 		m.sortMode = "created"
 		m.sortTasks()
 		m.status = "Sorted by created time"
+		m.rememberViewSort()
 	case m.cfg.Keys.Trash, "T":
 		return m.enterTrashView()
 	case "l", "right", "enter":
 		if m.currentTopic == "" && len(vis) > 0 && m.cursor < len(vis) {
 			it := vis[m.cursor]
 			if it.kind == itemTopic {
-				m.currentTopic = it.topic
+				m.openTopic(it.topic)
 				m.cursor = clampCursor(0, len(m.visibleItems()))
-				m.status = fmt.Sprintf("Topic: %s", m.currentTopic)
+				m.status = fmt.Sprintf("Topic: %s", topicLabel(m.currentTopic))
+				if v, _, ok := m.savedView(it.topic); ok {
+					m.status = fmt.Sprintf("View: %s (%s) sort:%s", v.Name, v.Query, m.sortMode)
+				}
 				return m, nil
 			}
 		}
@@ -556,6 +681,41 @@ func (m Model) View() string {
 		return m.fillView(b.String())
 	}
 
+	if m.mode == modeRestore {
+		b.WriteString(m.renderRestoreView())
+		return m.fillView(b.String())
+	}
+
+	if m.mode == modeWorkspace {
+		b.WriteString(m.renderWorkspaceView())
+		return m.fillView(b.String())
+	}
+
+	if m.mode == modeTimesheet {
+		b.WriteString(m.renderTimesheetView())
+		return m.fillView(b.String())
+	}
+
+	if m.mode == modeFocus {
+		b.WriteString(m.renderFocusView())
+		return m.fillView(b.String())
+	}
+
+	if m.mode == modeBoard {
+		b.WriteString(m.renderBoardView())
+		return m.fillView(b.String())
+	}
+
+	if m.mode == modePager {
+		b.WriteString(m.renderPagerView())
+		return m.fillView(b.String())
+	}
+
+	if m.mode == modeFinder {
+		b.WriteString(m.renderFinderView())
+		return m.fillView(b.String())
+	}
+
 	header := m.renderListBanner() + "\n"
 	gap := "\n"
 	divider := m.styles.Border.Render(m.ruleLine(m.taskListLineWidth())) + "\n"
@@ -625,6 +785,20 @@ func (m Model) renderFooterPanel() string {
 	case modeSearch:
 		b.WriteString(m.styles.Heading.Render("Search: "))
 		b.WriteString(m.input.View())
+		src := m.input.Value()
+		if _, err := query.Parse(src); err != nil {
+			var qerr *query.Error
+			if errors.As(err, &qerr) {
+				b.WriteString("\n" + strings.Repeat(" ", len("Search: ")+lipgloss.Width(m.input.Prompt)) + m.styles.Danger.Render(qerr.Marker(src)))
+			}
+			b.WriteString("\n" + m.styles.Danger.Render(err.Error()))
+		}
+		if m.searchHelp {
+			b.WriteString("\n\n" + m.styles.Heading.Render("Query fields (? to hide)"))
+			for _, line := range query.Help {
+				b.WriteString("\n  " + m.styles.Muted.Render(line))
+			}
+		}
 		return b.String()
 	default:
 		return m.renderMetadataPanel()
@@ -664,6 +838,12 @@ func (m Model) updateDeleteConfirm(key string) (tea.Model, tea.Cmd) {
 		return m, nil
 	case "y", "Y":
 		if len(m.pendingBatch) > 0 {
+			if err := m.vetoDeletes(m.pendingBatch); err != nil {
+				m.status = fmt.Sprintf("delete failed: %v", err)
+				m.confirmDel = false
+				m.pendingBatch = nil
+				return m, nil
+			}
 			deleted := 0
 			for _, task := range m.pendingBatch {
 				if err := m.store.DeleteTask(task.ID); err != nil {
@@ -674,6 +854,7 @@ func (m Model) updateDeleteConfirm(key string) (tea.Model, tea.Cmd) {
 				}
 				deleted++
 			}
+			m.announceDeletes(m.pendingBatch)
 			var errReload error
 			m.tasks, errReload = m.store.FetchTasks()
 			if errReload == nil {
@@ -690,12 +871,24 @@ func (m Model) updateDeleteConfirm(key string) (tea.Model, tea.Cmd) {
 		}
 		if m.pendingDel == nil {
 			// delete all done
+			var doneTasks []storage.Task
+			for _, t := range m.tasks {
+				if t.Done {
+					doneTasks = append(doneTasks, t)
+				}
+			}
+			if err := m.vetoDeletes(doneTasks); err != nil {
+				m.status = fmt.Sprintf("delete failed: %v", err)
+				m.confirmDel = false
+				return m, nil
+			}
 			n, err := m.store.DeleteDoneTasks()
 			if err != nil {
 				m.status = fmt.Sprintf("delete failed: %v", err)
 				m.confirmDel = false
 				return m, nil
 			}
+			m.announceDeletes(doneTasks)
 			var errReload error
 			m.tasks, errReload = m.store.FetchTasks()
 			if errReload == nil {
@@ -708,12 +901,20 @@ func (m Model) updateDeleteConfirm(key string) (tea.Model, tea.Cmd) {
 			m.confirmDel = false
 			return m, nil
 		}
+		deleting := []storage.Task{*m.pendingDel}
+		if err := m.vetoDeletes(deleting); err != nil {
+			m.status = fmt.Sprintf("delete failed: %v", err)
+			m.confirmDel = false
+			m.pendingDel = nil
+			return m, nil
+		}
 		if err := m.store.DeleteTask(m.pendingDel.ID); err != nil {
 			m.status = fmt.Sprintf("delete failed: %v", err)
 			m.confirmDel = false
 			m.pendingDel = nil
 			return m, nil
 		}
+		m.announceDeletes(deleting)
 		var err error
 		m.tasks, err = m.store.FetchTasks()
 		if err == nil {
@@ -768,8 +969,19 @@ func (m Model) updateDeleteTopicConfirm(key string) (tea.Model, tea.Cmd) {
 	}
 }
 
-func (m Model) enterTrashView() (tea.Model, tea.Cmd) {
+// loadTrash lists the trash; snapshots that cannot be decrypted are left
+// out and described in the returned note instead of failing the listing.
+func (m Model) loadTrash() ([]storage.TrashEntry, string, error) {
 	entries, err := m.store.ListTrash()
+	var unreadable *storage.UnreadableTrash
+	if errors.As(err, &unreadable) {
+		return entries, "; " + unreadable.Error(), nil
+	}
+	return entries, "", err
+}
+
+func (m Model) enterTrashView() (tea.Model, tea.Cmd) {
+	entries, note, err := m.loadTrash()
 	if err != nil {
 		m.status = fmt.Sprintf("trash load failed: %v", err)
 		return m, nil
@@ -779,7 +991,7 @@ func (m Model) enterTrashView() (tea.Model, tea.Cmd) {
 	m.trashCursor = clampCursor(0, len(entries))
 	m.trashScroll = 0
 	m.mode = modeTrash
-	m.status = fmt.Sprintf("Trash: %d item(s). space to select, u to restore, P to purge, esc to exit", len(entries))
+	m.status = fmt.Sprintf("Trash: %d item(s). space to select, u to restore, U to restore batch, P to purge, esc to exit%s", len(entries), note)
 	m.adjustTrashScroll()
 	return m, nil
 }
@@ -797,9 +1009,7 @@ func (m Model) enterCalendarView() (tea.Model, tea.Cmd) {
 	now := time.Now()
 	m.calendarMonth = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	m.calendarDay = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	m.calendarDetail = false
-	m.status = "Calendar view"
-	return m, nil
+	return m.setCalendarSpan(m.calendarView), nil
 }
 
 func (m Model) enterHelpView() (tea.Model, tea.Cmd) {
@@ -823,12 +1033,13 @@ func (m Model) updateTrashMode(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd)
 			if err := m.store.PurgeTrash(m.trashPending); err != nil {
 				m.status = fmt.Sprintf("purge failed: %v", err)
 			} else {
+				var note string
 				var err error
-				m.trash, err = m.store.ListTrash()
+				m.trash, note, err = m.loadTrash()
 				if err != nil {
 					m.status = fmt.Sprintf("reload trash failed: %v", err)
 				} else {
-					m.status = fmt.Sprintf("Purged %d item(s)", len(m.trashPending))
+					m.status = fmt.Sprintf("Purged %d item(s)%s", len(m.trashPending), note)
 				}
 				m.trashSelected = map[int]bool{}
 				m.trashCursor = clampCursor(m.trashCursor, len(m.trash))
@@ -872,6 +1083,8 @@ func (m Model) updateTrashMode(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		m.trashCursor = clampCursor(m.trashCursor+1, len(m.trash))
 	case "u":
 		return m.restoreTrashSelection()
+	case "U":
+		return m.restoreTrashBatch()
 	case "P":
 		return m.confirmPurgeTrash()
 	}
@@ -903,7 +1116,14 @@ func (m Model) updateReportMode(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd)
 }
 
 func (m Model) updateCalendarMode(key string) (tea.Model, tea.Cmd) {
+	if m.calendarView != calendarMonthSpan {
+		return m.updateTimelineMode(key)
+	}
 	switch key {
+	case "w":
+		return m.setCalendarSpan(calendarWeekSpan), nil
+	case "d":
+		return m.setCalendarSpan(calendarDaySpan), nil
 	case "enter":
 		m.calendarDetail = !m.calendarDetail
 		return m, nil
@@ -981,6 +1201,13 @@ func (m Model) updateGanttMode(key string) (tea.Model, tea.Cmd) {
 		}
 	case m.cfg.Keys.Down, "down":
 		m.ganttScroll = clampInt(m.ganttScroll+1, 0, m.ganttMaxScroll())
+	case "e":
+		m.ganttEffort = !m.ganttEffort
+		m.ganttScroll = 0
+		m.status = "Gantt: bars span start to due"
+		if m.ganttEffort {
+			m.status = "Gantt: bars sized by estimated effort"
+		}
 	default:
 		return m, nil
 	}
@@ -1022,6 +1249,19 @@ func (m Model) updateNoteMode(key string) (tea.Model, tea.Cmd) {
 		return m, nil
 	case m.cfg.Keys.Edit:
 		return m.startNoteEditFromState()
+	case m.cfg.Keys.Open, "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if m.note == nil || m.note.target.kind != noteTask {
+			return m, nil
+		}
+		idx := m.findTaskIndex(m.note.target.taskID)
+		if idx < 0 {
+			return m, nil
+		}
+		arg := key
+		if key == m.cfg.Keys.Open {
+			arg = ""
+		}
+		return m.openAttachment(m.tasks[idx], arg)
 	case "d":
 		if m.note == nil {
 			return m, nil
@@ -1030,6 +1270,12 @@ func (m Model) updateNoteMode(key string) (tea.Model, tea.Cmd) {
 		m.notePending = m.note.target
 		m.status = "Delete note? y/n"
 		return m, nil
+	case "tab":
+		return m.moveChecklistCursor(1)
+	case "shift+tab":
+		return m.moveChecklistCursor(-1)
+	case " ":
+		return m.toggleNoteChecklist()
 	case "j", "down":
 		max := m.noteMaxScroll()
 		if m.noteScroll < max {
@@ -1073,12 +1319,52 @@ func (m Model) restoreTrashSelection() (tea.Model, tea.Cmd) {
 		m.status = "Nothing selected"
 		return m, nil
 	}
-	if err := m.store.RestoreTrash(entries); err != nil {
+	return m.restoreTrashEntries(entries)
+}
+
+func (m Model) restoreTrashBatch() (tea.Model, tea.Cmd) {
+	if len(m.trash) == 0 {
+		m.status = "Trash is empty"
+		return m, nil
+	}
+	if m.trashConfirm || m.trashCursor >= len(m.trash) {
+		return m, nil
+	}
+	batch := m.trash[m.trashCursor].Batch
+	var entries []storage.TrashEntry
+	for _, e := range m.trash {
+		if e.Batch == batch {
+			entries = append(entries, e)
+		}
+	}
+	return m.restoreTrashEntries(entries)
+}
+
+func (m Model) restoreTrashEntries(entries []storage.TrashEntry) (tea.Model, tea.Cmd) {
+	for i := range entries {
+		final, err := m.runPreHooks(hookRestore, nil, &entries[i].Task)
+		if err != nil {
+			m.status = fmt.Sprintf("restore failed: #%d %v", entries[i].Task.ID, err)
+			return m, nil
+		}
+		entries[i].Task = *final
+	}
+	remapped, err := m.store.RestoreTrash(entries)
+	if err != nil {
 		m.status = fmt.Sprintf("restore failed: %v", err)
 		return m, nil
 	}
-	var err error
-	m.trash, err = m.store.ListTrash()
+	for _, e := range entries {
+		id := e.Task.ID
+		if newID, ok := remapped[id]; ok {
+			id = newID
+		}
+		if restored, err := m.store.TaskByID(id); err == nil {
+			m.runPostHooks(hookRestore, nil, &restored)
+		}
+	}
+	var note string
+	m.trash, note, err = m.loadTrash()
 	if err != nil {
 		m.status = fmt.Sprintf("reload trash failed: %v", err)
 		return m, nil
@@ -1089,13 +1375,29 @@ func (m Model) restoreTrashSelection() (tea.Model, tea.Cmd) {
 	m.tasks, err = m.store.FetchTasks()
 	if err == nil {
 		m.sortTasks()
-		m.status = fmt.Sprintf("Restored %d task(s)", len(entries))
+		m.status = fmt.Sprintf("Restored %d task(s)%s%s", len(entries), remapSummary(remapped), note)
 	} else {
 		m.status = fmt.Sprintf("restore succeeded, reload failed: %v", err)
 	}
 	return m, nil
 }
 
+func remapSummary(remapped map[int]int) string {
+	if len(remapped) == 0 {
+		return ""
+	}
+	ids := make([]int, 0, len(remapped))
+	for old := range remapped {
+		ids = append(ids, old)
+	}
+	sort.Ints(ids)
+	parts := make([]string, 0, len(ids))
+	for _, old := range ids {
+		parts = append(parts, fmt.Sprintf("#%d→#%d", old, remapped[old]))
+	}
+	return " (id taken, remapped " + strings.Join(parts, ", ") + ")"
+}
+
 func (m Model) selectedTrashEntries() []storage.TrashEntry {
 	if len(m.trashSelected) == 0 {
 		return nil
@@ -1169,6 +1471,9 @@ func (m Model) selectedTaskList() []storage.Task {
 }
 
 func (m Model) renderCalendarView() string {
+	if m.calendarView != calendarMonthSpan {
+		return m.renderTimeline()
+	}
 	if m.calendarDetail {
 		return m.renderCalendarDetail()
 	}
@@ -1182,7 +1487,7 @@ func (m Model) renderCalendarMonth() string {
 	b.WriteString("\n\n")
 	b.WriteString(m.renderCalendarGrid())
 	b.WriteString("\n")
-	b.WriteString(m.styles.Muted.Render("h/l day • j/k week • H/L month • enter day • esc/q close"))
+	b.WriteString(m.styles.Muted.Render("h/l day • j/k week • H/L month • enter day • w/d week/day timeline • esc/q close"))
 	return b.String()
 }
 
@@ -1190,6 +1495,13 @@ func (m Model) renderCalendarDetail() string {
 	var b strings.Builder
 	title := fmt.Sprintf("Calendar • %s", m.calendarDay.Format("Mon, Jan 2, 2006"))
 	b.WriteString(m.styles.Accent.Render(title))
+	if load := totalEstimate(m.tasksForDay(m.calendarDay)); load > 0 {
+		style := m.styles.Muted
+		if m.overloaded(load) {
+			style = m.styles.Danger
+		}
+		b.WriteString(style.Render(" • load " + m.loadLabel(load)))
+	}
 	b.WriteString("\n\n")
 	b.WriteString(m.renderCalendarDayList())
 	b.WriteString("\n\n")
@@ -1266,20 +1578,41 @@ func (m Model) renderCalendarCellLines(day time.Time, width, height, maxTasks in
 	if len(tasks) > 0 {
 		dateLabel = fmt.Sprintf("%2d (%d)", day.Day(), len(tasks))
 	}
+	if load := totalEstimate(tasks); load > 0 {
+		full := dateLabel + " " + m.loadLabel(load)
+		if lipgloss.Width(full) > width {
+			// drop the capacity before truncating so the load stays visible
+			full = fmt.Sprintf("%2d %s", day.Day(), formatEstimate(load))
+			if m.overloaded(load) {
+				full += "!"
+			}
+		}
+		dateLabel = full
+		if m.overloaded(load) && !isSameDate(day, m.calendarDay) {
+			headerStyle = m.styles.Danger
+		}
+	}
 	dateLabel = padRightWidth(truncateTextWidth(dateLabel, width), width)
 	lines = append(lines, headerStyle.Render(dateLabel))
 
-	showCount := len(tasks)
+	entries := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		entries = append(entries, "• "+t.Title)
+	}
+	for _, mark := range m.overridesOnDay(day, m.calendarDay.Location()) {
+		entries = append(entries, mark.symbol+" "+mark.task.Title)
+	}
+	showCount := len(entries)
 	if showCount > maxTasks {
 		showCount = maxTasks
 	}
 	for i := 0; i < showCount; i++ {
-		text := "• " + truncateTextWidth(tasks[i].Title, width-2)
+		text := truncateTextWidth(entries[i], width)
 		lines = append(lines, taskStyle.Render(padRightWidth(text, width)))
 	}
 
-	if len(tasks) > maxTasks {
-		overflow := fmt.Sprintf("+%d more", len(tasks)-maxTasks)
+	if len(entries) > maxTasks {
+		overflow := fmt.Sprintf("+%d more", len(entries)-maxTasks)
 		overflow = padRightWidth(truncateTextWidth(overflow, width), width)
 		overflowStyle := taskStyle
 		if inMonth && !isSameDate(day, time.Now()) && !isSameDate(day, m.calendarDay) {
@@ -1296,22 +1629,31 @@ func (m Model) renderCalendarCellLines(day time.Time, width, height, maxTasks in
 
 func (m Model) renderCalendarDayList() string {
 	tasks := m.tasksForDay(m.calendarDay)
-	if len(tasks) == 0 {
+	marks := m.overridesOnDay(m.calendarDay, m.calendarDay.Location())
+	if len(tasks) == 0 && len(marks) == 0 {
 		return m.styles.Muted.Render("(no tasks)")
 	}
 	var b strings.Builder
 	for _, t := range tasks {
 		due := "no due"
 		if t.Due.Valid {
-			due = formatDateTime(t.Due)
+			due = m.formatWhen(t.Due)
 		}
-		line := fmt.Sprintf("  • #%d %-40s  %s", t.ID, truncateText(t.Title, 40), due)
+		line := fmt.Sprintf("  • #%d %-40s  %s", t.ID, truncateText(t.Title, 40), due) + estimateSuffix(t)
 		if rec := recurrenceSummary(t); rec != "" {
 			line += " [" + rec + "]"
 		}
+		if note := overrideNote(t); note != "" {
+			line += " (" + note + ")"
+		}
 		b.WriteString(line)
 		b.WriteString("\n")
 	}
+	for _, mark := range marks {
+		line := fmt.Sprintf("  %s #%d %-40s  %s", mark.symbol, mark.task.ID, truncateText(mark.task.Title, 40), mark.detail)
+		b.WriteString(m.styles.Muted.Render(line))
+		b.WriteString("\n")
+	}
 	return strings.TrimRight(b.String(), "\n")
 }
 
@@ -1319,6 +1661,9 @@ func (m Model) renderCalendarInfo() string {
 	day := m.calendarDay
 	tasks := m.tasksForDay(day)
 	label := fmt.Sprintf("%s • tasks: %d", day.Format("Mon, Jan 2, 2006"), len(tasks))
+	if load := totalEstimate(tasks); load > 0 {
+		label += " • load " + m.loadLabel(load)
+	}
 	if len(tasks) == 0 {
 		return m.styles.Muted.Render(label)
 	}
@@ -1334,17 +1679,21 @@ func (m Model) renderCalendarInfo() string {
 }
 
 func (m Model) tasksForDay(day time.Time) []storage.Task {
-	dayKey := dateKey(day, m.calendarDay.Location())
+	return m.tasksOnDay(day, m.calendarDay.Location())
+}
+
+func (m Model) tasksOnDay(day time.Time, loc *time.Location) []storage.Task {
+	dayKey := dateKey(day, loc)
 	var list []storage.Task
 	for _, t := range m.tasks {
 		if t.Done {
 			continue
 		}
-		if t.Due.Valid && dateKey(t.Due.Time, m.calendarDay.Location()) == dayKey {
+		if t.Due.Valid && m.dueDay(t) == dayKey {
 			list = append(list, t)
 			continue
 		}
-		if next, ok := nextRecurrenceDate(t); ok && dateKey(next, m.calendarDay.Location()) == dayKey {
+		if next, ok := nextRecurrenceDate(t); ok && next.Format("2006-01-02") == dayKey {
 			list = append(list, t)
 		}
 	}
@@ -1523,46 +1872,97 @@ func (m Model) helpContent() string {
   :calendar  Open calendar view
   :config    Update config and db paths
   :help      Open this help screen
+  :backup    Snapshot the database now
+  :restore   Pick a snapshot, preview it and swap it in
+  :workspace [name]  Switch workspace (picker without a name)
+  :move [name]       Move selected/current tasks to another workspace
+  :attach [--copy|--link] <path|url> [label]  Attach a file or URL
+  :detach [N]        Remove attachment N (default 1)
+  :open [N]          Open attachment N with attachments.opener
+  :timesheet [range] Time per day/topic/tag (week, month, today, 7d, YYYY-MM-DD [YYYY-MM-DD])
+  :focus             Pomodoro focus on the current task
+  :filter [f]        Show all, open, closed or one workflow state (e.g. :filter doing)
+  :board [state|topic] Kanban board (h/l columns, j/k cards, H/L move card, tab regroup)
+  :snooze [when]     Hide the selected/current tasks until +3d, tomorrow, monday, YYYY-MM-DD (empty wakes them)
+  :reschedule <when> Move only the current occurrence of a recurring task (+3d, friday, YYYY-MM-DD)
+  :habits            Streaks and hit/miss grids of the recurring tasks
+  :save-view <name>  Save the current search, filter and sort as a view in the root list
+  :<name> [args]     Custom command from [commands.<name>] (see Custom Commands below)
 
 List Navigation:
   %s/%s  Move cursor
   %s     Rename
   %s     Search
+  %s Fuzzy finder (tasks, topics and trash in every workspace)
   %s     Quit
   gg/G   Jump to top/bottom
 
 Tasks:
   %s     Add task (opens metadata editor)
   %s     Toggle done
+  %s<k>  Set workflow state (S then the state's key; applies to the selection)
   %s     Delete (purge to trash)
   %s     Edit metadata
   %s     Notes
+  %s     Open attachment (1-9 pick one in notes)
+  %s     Start/stop timer (one at a time)
+  %s     Focus mode (Pomodoro; space pause, s skip, d done)
+  %s     Snooze (hide until a date; see the Snoozed list)
+  %s     Skip this occurrence of a recurring task
+  %s     Move this occurrence to another date (:reschedule)
+  %s     Undo the last skip or move
+  %s     Show times in each task's zone or in ours
   space  Select task (multi-select)
   %s     Delete selected (with confirm)
   %s     Delete all done (with confirm)
 
+Notes View:
+  tab/shift+tab  Move between checklist items (- [ ] step)
+  space          Toggle the item; saved to the notes right away
+  The task list shows checklist progress as [done/total]
+
 Metadata Editor:
   up/down or tab/shift+tab  Move fields
   enter                    Save/next field
   esc                      Save and close
+  Estimate accepts 30m, 2h, 1h30m or plain minutes
+  Reminders: offsets before due (1d, 1h, 0m), "off", or empty for reminders.offsets
+  Timezone: an IANA name (Asia/Seoul); due and start are read in it
 
 Recurrence:
   Recurrence field supports:
-    every day
-    every 3 days
-    every 2 weeks
-    every 2 weeks on Mon
-    every month
-    every month on Fri
-    daily | weekly | monthly (aliases)
+    every day | every 3 days | weekdays | weekends
+    every 2 weeks on Mon, Wed
+    every month on the 15th | on the 1st, 15th | on the last day
+    every month on the 2nd Tue | on the last Fri | on the last workday
+    every year on Mar 15 | every year on the 2nd Sun in May
+    daily | weekly | monthly | yearly (aliases)
+    RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1 (raw RFC 5545)
   Weekdays: Mon/Tue/Wed/Thu/Fri/Sat/Sun (short or long)
   Interval alone means "every N days"
+  The metadata panel previews the next five occurrences
+  Completing one advances it to the next occurrence, or with "spawn"
+  (On Complete field or recurrence.on_complete) keeps it and adds a copy
+  Ends: a last date (2026-12-31) or a count (5 times); empty = never
+  Skip Dates: one-off days to leave out (2026-12-24, 2026-12-31)
 
 Calendar:
   h/l day • j/k week • H/L month
   enter day detail • esc/q close
+  Days show estimated load vs planning.daily_capacity; "!" marks overload
+  w/d (or :calendar week|day) open the week or day timeline, m the month
+
+Week/Day Timeline:
+  Tasks sit at their start or due time for their length or estimate; date-only dues in the all-day row
+  h/l day • j/k 30m • J/K hour • H/L week • a all-day row
+  enter/space grab the task under the cursor, move it, enter again to drop; esc cancels
+  tab next overlapping task • +/- lengthen/shorten by 30m (the estimate is kept)
+  A recurring task dropped on another day moves only that occurrence
 
-`, m.cfg.Keys.Up, m.cfg.Keys.Down, m.cfg.Keys.Rename, m.cfg.Keys.Search, m.cfg.Keys.Quit, m.cfg.Keys.Add, m.cfg.Keys.Toggle, m.cfg.Keys.Delete, m.cfg.Keys.Edit, m.cfg.Keys.NoteView, m.cfg.Keys.Delete, m.cfg.Keys.DeleteAllDone), "\n")
+Gantt:
+  e  Toggle effort sizing (bars end on the due date, one day per daily capacity)
+
+`, m.cfg.Keys.Up, m.cfg.Keys.Down, m.cfg.Keys.Rename, m.cfg.Keys.Search, m.cfg.Keys.Finder, m.cfg.Keys.Quit, m.cfg.Keys.Add, m.cfg.Keys.Toggle, m.cfg.Keys.Status, m.cfg.Keys.Delete, m.cfg.Keys.Edit, m.cfg.Keys.NoteView, m.cfg.Keys.Open, m.cfg.Keys.Timer, m.cfg.Keys.Focus, m.cfg.Keys.Snooze, m.cfg.Keys.SkipNext, m.cfg.Keys.Reschedule, m.cfg.Keys.UndoOverride, m.cfg.Keys.ZoneToggle, m.cfg.Keys.Delete, m.cfg.Keys.DeleteAllDone)+queryHelp()+m.customCommandsHelp(), "\n")
 }
 
 func (m Model) helpMaxScroll() int {
@@ -1627,7 +2027,7 @@ func (m Model) trashViewHeader() string {
 }
 
 func (m Model) trashViewFooter() string {
-	return m.styles.Muted.Render("up/down scroll • space select • u restore • P purge • esc/q close")
+	return m.styles.Muted.Render("up/down scroll • space select • u restore • U restore batch • P purge • esc/q close")
 }
 
 func (m Model) renderTrashHeaderLines() string {
@@ -1671,6 +2071,10 @@ func (m Model) renderTrashBody(maxLines int) string {
 
 func (m Model) trashRows() []string {
 	rows := make([]string, 0, len(m.trash))
+	batchSizes := map[string]int{}
+	for _, entry := range m.trash {
+		batchSizes[entry.Batch]++
+	}
 	for i, entry := range m.trash {
 		cursor := " "
 		title := entry.Task.Title
@@ -1679,6 +2083,9 @@ func (m Model) trashRows() []string {
 		}
 		deleted := entry.DeletedAt.Format("2006-01-02 15:04")
 		line := fmt.Sprintf("%s 🗑 %-18s %-30s %-16s", cursor, deleted, title, strings.Join(entry.Task.Topics, ","))
+		if n := batchSizes[entry.Batch]; n > 1 {
+			line += fmt.Sprintf(" [batch of %d]", n)
+		}
 		if m.mode == modeTrash && m.trashCursor == i {
 			line = m.styles.Selection.Render(line)
 		} else if m.trashSelected != nil && m.trashSelected[i] {
@@ -1723,7 +2130,7 @@ func (m *Model) adjustTrashScroll() {
 func (m Model) renderGanttView() string {
 	title := m.renderListBanner() + "\n\n" + m.styles.Accent.Render("Gantt View") + "\n\n"
 	header := title + m.renderGanttHeaderLines() + "\n"
-	footer := m.styles.Muted.Render("up/down scroll • esc/q close")
+	footer := m.styles.Muted.Render(ganttFooter)
 	bodyMax := 0
 	if m.height > 0 {
 		bodyMax = m.height - 1 - countLines(header) - countLines(footer)
@@ -1743,6 +2150,8 @@ func (m Model) renderGanttView() string {
 	return b.String()
 }
 
+const ganttFooter = "up/down scroll • e toggle effort sizing • esc/q close"
+
 func (m Model) renderGanttHeaderLines() string {
 	_, header := m.ganttDataRowsWithHeader()
 	if strings.TrimSpace(header) == "" {
@@ -1774,7 +2183,7 @@ func (m Model) ganttMaxScroll() int {
 	}
 	title := m.renderListBanner() + "\n\n" + m.styles.Accent.Render("Gantt View") + "\n\n"
 	header := title + m.renderGanttHeaderLines() + "\n"
-	footer := m.styles.Muted.Render("up/down scroll • esc/q close")
+	footer := m.styles.Muted.Render(ganttFooter)
 	bodyMax := m.height - 1 - countLines(header) - countLines(footer)
 	if bodyMax <= 0 {
 		return 0
@@ -1828,6 +2237,10 @@ func (m Model) ganttDataRowsWithHeader() ([]string, string) {
 		}
 		start = normalizeDate(start)
 		due := normalizeDate(t.Due.Time)
+		if m.ganttEffort {
+			// work back from the due date by as many days as the estimate fills
+			start = due.AddDate(0, 0, 1-m.effortDays(t.EstimateMinutes))
+		}
 		if due.Before(start) {
 			start = due
 		}
@@ -1839,7 +2252,7 @@ func (m Model) ganttDataRowsWithHeader() ([]string, string) {
 			barWidth = clampInt(m.width-52, 20, 60)
 		}
 		fallbackStart := normalizeDate(time.Now())
-		header := buildGanttHeader(fallbackStart, 14, barWidth)
+		header := buildGanttHeader(fallbackStart, 14, barWidth, m.ganttEffort)
 		return nil, header
 	}
 	sort.SliceStable(items, func(i, j int) bool {
@@ -1867,21 +2280,32 @@ func (m Model) ganttDataRowsWithHeader() ([]string, string) {
 		barWidth = clampInt(m.width-52, 20, 60)
 	}
 	rows := make([]string, 0, len(items))
-	header := buildGanttHeader(minDate, spanDays, barWidth)
+	header := buildGanttHeader(minDate, spanDays, barWidth, m.ganttEffort)
 	today := normalizeDate(time.Now())
 	for _, it := range items {
 		title := truncateText(it.task.Title, 24)
 		bar := renderGanttBar(minDate, spanDays, barWidth, it.start, it.due, today)
-		line := fmt.Sprintf("%-4d %-24s %-10s %-10s %s", it.task.ID, title, it.start.Format("2006-01-02"), it.due.Format("2006-01-02"), bar)
+		first := it.start.Format("2006-01-02")
+		if m.ganttEffort {
+			first = formatEstimate(it.task.EstimateMinutes)
+			if first == "" {
+				first = "-"
+			}
+		}
+		line := fmt.Sprintf("%-4d %-24s %-10s %-10s %s", it.task.ID, title, first, it.due.Format("2006-01-02"), bar)
 		rows = append(rows, line)
 	}
 	return rows, header
 }
 
-func buildGanttHeader(start time.Time, spanDays, barWidth int) string {
+func buildGanttHeader(start time.Time, spanDays, barWidth int, effort bool) string {
 	scaleLine, labelLine := renderGanttScaleLines(start, spanDays, barWidth)
+	first := "Start"
+	if effort {
+		first = "Effort"
+	}
 	return fmt.Sprintf("%-4s %-24s %-10s %-10s %s\n%-4s %-24s %-10s %-10s %s",
-		"ID", "Title", "Start", "Due", scaleLine,
+		"ID", "Title", first, "Due", scaleLine,
 		"", "", "", "", labelLine,
 	)
 }
@@ -2001,7 +2425,13 @@ func (m Model) renderTaskListWithHeight(maxLines int) string {
 		switch it.kind {
 		case itemTopic:
 			line := ""
-			if isSpecialTopic(it.topic) {
+			if v, _, ok := m.savedView(it.topic); ok {
+				stat, err := m.viewStats(v)
+				line = fmt.Sprintf("   %-2s %s (%d/%d)", "🔎", v.Name, stat.overdue, stat.total)
+				if err != nil {
+					line = fmt.Sprintf("   %-2s %s (query error)", "🔎", v.Name)
+				}
+			} else if isSpecialTopic(it.topic) {
 				line = fmt.Sprintf("   %-2s %s", "📁", it.topic)
 			} else {
 				stat := m.topicStats()[it.topic]
@@ -2020,28 +2450,13 @@ func (m Model) renderTaskListWithHeight(maxLines int) string {
 			if len(title) > 40 {
 				title = title[:40]
 			}
-			state := humanDone(it.task.Done)
-			due := displayDate(it.task.Due)
-			badge := overdueBadge(it.task)
-			recBadge := recurrenceBadge(it.task)
+			state := m.stateSymbol(it.task, m.cursor == i && m.mode == modeList || m.isTaskSelected(it.task.ID))
+			due := m.displayDate(it.task.Due)
 			if due == "" {
 				due = "pending"
 			}
 			body := fmt.Sprintf("   %-2s %-40s %-10s", state, title, due)
-			if badge != "" {
-				if m.cursor == i && m.mode == modeList {
-					body += " " + badge
-				} else {
-					body += " " + m.styles.Danger.Render(badge)
-				}
-			}
-			if recBadge != "" {
-				if m.cursor == i && m.mode == modeList {
-					body += " " + recBadge
-				} else {
-					body += " " + m.styles.Warning.Render(recBadge)
-				}
-			}
+			body += renderBadges(m.taskBadges(it.task), m.cursor == i && m.mode == modeList)
 			if m.searchActive() && len(it.task.Topics) > 0 {
 				body += " [" + strings.Join(it.task.Topics, ",") + "]"
 			}
@@ -2104,7 +2519,12 @@ func (m Model) startNoteView() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	m.note = &noteState{target: target, body: notes}
+	if target.kind == noteTask {
+		// A missing history only leaves the habit grid empty.
+		m.note.completions, _ = m.store.Completions(target.taskID)
+	}
 	m.noteScroll = 0
+	m.noteCheck = -1
 	m.mode = modeNote
 	m.status = fmt.Sprintf("Notes: %s", target.label())
 	return m, nil
@@ -2157,8 +2577,8 @@ func (m Model) renderNoteView() string {
 		headerLines = append(headerLines, metaLines...)
 		headerLines = append(headerLines, m.noteMetaSeparator(), "")
 	}
-	footerLine := m.styles.Muted.Render(fmt.Sprintf("Press %s/%s/enter to close, %s to edit, %s to purge",
-		m.cfg.Keys.Cancel, m.cfg.Keys.Quit, m.cfg.Keys.Edit, m.cfg.Keys.Delete))
+	footerLine := m.styles.Muted.Render(fmt.Sprintf("Press %s/%s/enter to close, %s to edit, %s to purge, %s/1-9 to open an attachment, tab/space for checklist items",
+		m.cfg.Keys.Cancel, m.cfg.Keys.Quit, m.cfg.Keys.Edit, m.cfg.Keys.Delete, m.cfg.Keys.Open))
 
 	bodyLines := m.noteBodyLines()
 	available := m.noteAvailableHeight()
@@ -2303,10 +2723,12 @@ func (m Model) handleNoteEdited(msg noteEditedMsg) (tea.Model, tea.Cmd) {
 	}
 	switch msg.target.kind {
 	case noteTask:
-		if err := m.store.UpdateTaskNotes(msg.target.taskID, msg.notes); err != nil {
+		saved, err := m.changeTask(msg.target.taskID, func(t *storage.Task) { t.Notes = msg.notes })
+		if err != nil {
 			m.status = fmt.Sprintf("note save failed: %v", err)
 			return m, nil
 		}
+		msg.notes = saved.Notes
 		m.applyTaskNoteLocal(msg.target.taskID, msg.notes)
 		m.status = fmt.Sprintf("Saved note: %s", msg.target.label())
 	case noteTopic:
@@ -2360,18 +2782,24 @@ func (t noteTarget) matches(other noteTarget) bool {
 
 func (m Model) startMetadataEdit(t storage.Task) (tea.Model, tea.Cmd) {
 	m.meta = &metaState{
-		taskID:    t.ID,
-		title:     t.Title,
-		topic:     strings.Join(t.Topics, ","),
-		tags:      t.Tags,
-		priority:  fmt.Sprintf("%d", t.Priority),
-		due:       formatDateTime(t.Due),
-		start:     defaultStart(t),
-		timezone:  defaultTimezone(t.Timezone),
-		rule:      t.RecurrenceRule,
-		interval:  intervalString(t.RecurrenceInterval),
-		recurring: t.Recurring,
-		index:     0,
+		taskID:     t.ID,
+		title:      t.Title,
+		topic:      strings.Join(t.Topics, ","),
+		tags:       t.Tags,
+		priority:   fmt.Sprintf("%d", t.Priority),
+		due:        formatDateTime(t.Due),
+		start:      defaultStart(t),
+		timezone:   defaultTimezone(t.Timezone),
+		rule:       editableRule(t),
+		interval:   intervalString(t.RecurrenceInterval),
+		estimate:   formatEstimate(t.EstimateMinutes),
+		status:     m.stateName(t),
+		reminders:  t.Reminders,
+		onComplete: t.OnComplete,
+		ends:       ruleEnds(t),
+		skip:       ruleSkips(t),
+		recurring:  t.Recurring,
+		index:      0,
 	}
 	m.input.SetValue(m.meta.currentValue())
 	m.input.CursorEnd()
@@ -2388,18 +2816,22 @@ func (m Model) startMetadataAdd() (tea.Model, tea.Cmd) {
 		defaultTopic = m.currentTopic
 	}
 	m.meta = &metaState{
-		taskID:    0,
-		title:     "",
-		topic:     defaultTopic,
-		tags:      "",
-		priority:  "",
-		due:       "",
-		start:     "",
-		timezone:  defaultTimezone(""),
-		rule:      "",
-		interval:  "",
-		recurring: false,
-		index:     0,
+		taskID:     0,
+		title:      "",
+		topic:      defaultTopic,
+		tags:       "",
+		priority:   "",
+		due:        "",
+		start:      "",
+		timezone:   defaultTimezone(""),
+		rule:       "",
+		interval:   "",
+		estimate:   "",
+		status:     m.stateName(storage.Task{}),
+		reminders:  "",
+		onComplete: "",
+		recurring:  false,
+		index:      0,
 	}
 	m.input.SetValue(m.meta.currentValue())
 	m.input.CursorEnd()
@@ -2604,6 +3036,12 @@ func (m *Model) applyMetaInputSanitizer() {
 		m.input.SetValue(filterRule(m.input.Value()))
 	case 8: // interval
 		m.input.SetValue(filterDigits(m.input.Value()))
+	case 9: // estimate
+		m.input.SetValue(filterEstimate(m.input.Value()))
+	case 13: // ends
+		m.input.SetValue(filterRule(m.input.Value()))
+	case 14: // skip dates
+		m.input.SetValue(filterDateList(m.input.Value()))
 	}
 	m.meta.setCurrentValue(m.input.Value())
 }
@@ -2616,9 +3054,15 @@ func metaFields() []string {
 		"Priority",
 		"Due (YYYY-MM-DD or YYYY-MM-DD HH:MM)",
 		"Start Date (YYYY-MM-DD)",
-		"Timezone (UTC±HH:MM)",
-		"Recurrence",
+		"Timezone (IANA name, e.g. Europe/Berlin; empty = ours)",
+		"Recurrence (words or RRULE:...)",
 		"Interval",
+		"Estimate (30m, 2h, 1h30m)",
+		"Status",
+		"Reminders (1d, 1h; off; empty = default)",
+		"On Complete (advance, spawn; empty = default)",
+		"Ends (YYYY-MM-DD or N times; empty = never)",
+		"Skip Dates (YYYY-MM-DD, ...)",
 	}
 }
 
@@ -2646,6 +3090,18 @@ func (ms metaState) currentValue() string {
 		return ms.rule
 	case 8:
 		return ms.interval
+	case 9:
+		return ms.estimate
+	case 10:
+		return ms.status
+	case 11:
+		return ms.reminders
+	case 12:
+		return ms.onComplete
+	case 13:
+		return ms.ends
+	case 14:
+		return ms.skip
 	default:
 		return ""
 	}
@@ -2671,6 +3127,18 @@ func (ms *metaState) setCurrentValue(v string) {
 		ms.rule = v
 	case 8:
 		ms.interval = v
+	case 9:
+		ms.estimate = v
+	case 10:
+		ms.status = v
+	case 11:
+		ms.reminders = v
+	case 12:
+		ms.onComplete = v
+	case 13:
+		ms.ends = v
+	case 14:
+		ms.skip = v
 	}
 }
 
@@ -2712,57 +3180,115 @@ func (m Model) applyMetadataAndReload() (Model, error) {
 		m.status = fmt.Sprintf("priority invalid: %v", err)
 		return m, nil
 	}
-	due, err := parseDateTime(m.meta.due)
+	timezone, err := normalizeTimezone(m.meta.timezone)
+	if err != nil {
+		m.status = fmt.Sprintf("timezone invalid: %v", err)
+		return m, nil
+	}
+	loc := storage.Location(timezone)
+	due, err := parseDateTime(m.meta.due, loc)
 	if err != nil {
 		m.status = fmt.Sprintf("due date invalid: %v", err)
 		return m, nil
 	}
-	start, err := parseDate(m.meta.start)
+	start, err := parseDate(m.meta.start, loc)
 	if err != nil {
 		m.status = fmt.Sprintf("start date invalid: %v", err)
 		return m, nil
 	}
-	timezone := normalizeTimezone(m.meta.timezone)
+	estimate, err := parseEstimate(m.meta.estimate)
+	if err != nil {
+		m.status = fmt.Sprintf("estimate invalid: %v", err)
+		return m, nil
+	}
+	reminders := strings.TrimSpace(m.meta.reminders)
+	if _, err := config.ParseReminders(reminders); err != nil {
+		m.status = fmt.Sprintf("reminders invalid: %v", err)
+		return m, nil
+	}
+	onComplete, ok := config.NormalizeOnComplete(m.meta.onComplete)
+	if !ok {
+		m.status = "on complete invalid: use advance, spawn or leave empty"
+		return m, nil
+	}
+	stateIdx := m.stateIndex(m.meta.status)
+	if strings.TrimSpace(m.meta.status) != "" && stateIdx < 0 {
+		m.status = fmt.Sprintf("status invalid: use one of %s", strings.Join(m.stateNames(), ", "))
+		return m, nil
+	}
+	until, count, err := recur.ParseEnd(m.meta.ends)
+	if err != nil {
+		m.status = fmt.Sprintf("ends invalid: %v", err)
+		return m, nil
+	}
+	exdates, err := recur.ParseExdates(m.meta.skip)
+	if err != nil {
+		m.status = fmt.Sprintf("skip dates invalid: %v", err)
+		return m, nil
+	}
 	ruleInput := strings.TrimSpace(m.meta.rule)
-	rule := strings.TrimSpace(ruleInput)
 	interval := parseInterval(m.meta.interval)
-	recurring := rule != "" || interval > 0
-	if spec, ok := parseRecurrenceSpec(ruleInput); ok {
-		rule = spec.label
-		interval = 0
-		recurring = true
-	}
-	if strings.EqualFold(ruleInput, "none") || strings.EqualFold(ruleInput, "off") {
-		rule = ""
-		interval = 0
-		recurring = false
-	}
-	if strings.TrimSpace(rule) == "" || strings.EqualFold(rule, "none") {
-		if interval > 0 {
-			rule = fmt.Sprintf("every %d days", interval)
-			interval = 0
-		} else {
-			rule = ""
+	rule := ""
+	recurring := false
+	var series recur.Rule
+	hasSeries := false
+	switch {
+	case strings.EqualFold(ruleInput, "none") || strings.EqualFold(ruleInput, "off"):
+	case ruleInput != "":
+		r, err := recur.Parse(ruleInput)
+		if err == nil {
+			series, hasSeries, recurring = r, true, true
+			break
+		}
+		// A custom rule saved before it had to parse stays until changed.
+		if idx := m.findTaskIndex(m.meta.taskID); idx < 0 || m.tasks[idx].RecurrenceRule != ruleInput {
+			m.status = fmt.Sprintf("recurrence invalid: %v", err)
+			return m, nil
+		}
+		rule, recurring = ruleInput, true
+	case interval > 0:
+		series, hasSeries, recurring = recur.Rule{Freq: recur.Daily, Interval: interval}, true, true
+	default:
+		recurring = m.meta.taskID != 0 && m.meta.recurring
+	}
+	if hasSeries {
+		// The Ends field wins over an end typed into the rule; the Skip
+		// Dates field is the whole list.
+		if !until.IsZero() || count > 0 {
+			series.Until, series.Count = until, count
+		}
+		series.Exdates = exdates
+		rule = series.String()
+	}
+	interval = 0
+
+	apply := func(t *storage.Task) {
+		t.Title = title
+		t.Topics = storage.SplitTopics(m.meta.topic)
+		t.Tags = m.meta.tags
+		t.Timezone = timezone
+		t.Priority = priority
+		t.Due = due
+		t.Start = start
+		t.Recurring = recurring
+		t.RecurrenceRule = rule
+		t.RecurrenceInterval = interval
+		t.EstimateMinutes = estimate
+		t.Reminders = reminders
+		t.OnComplete = onComplete
+		if stateIdx >= 0 && m.stateName(*t) != m.cfg.States[stateIdx].Name {
+			applyState(t, m.cfg.States[stateIdx])
 		}
 	}
-	if !recurring && m.meta.taskID != 0 && m.meta.recurring {
-		recurring = true
-	}
-
 	if taskID == 0 {
-		newID, err := m.store.AddTask(title)
+		var t storage.Task
+		apply(&t)
+		newID, err := m.createTask(t)
 		if err != nil {
 			return m, err
 		}
 		taskID = newID
-	}
-	if err := m.store.UpdateTaskMetadata(taskID, m.meta.topic, m.meta.tags, timezone, priority, due, start, recurring); err != nil {
-		return m, err
-	}
-	if err := m.store.UpdateRecurrence(taskID, rule, interval); err != nil {
-		return m, err
-	}
-	if err := m.store.UpdateTitle(taskID, title); err != nil {
+	} else if _, err := m.changeTask(taskID, apply); err != nil {
 		return m, err
 	}
 
@@ -2794,26 +3320,28 @@ func parsePriority(v string) (int, error) {
 	return val, nil
 }
 
-func parseDate(v string) (sql.NullTime, error) {
+// parseDate and parseDateTime read the metadata fields as the wall clock
+// in the task's zone.
+func parseDate(v string, loc *time.Location) (sql.NullTime, error) {
 	v = strings.TrimSpace(v)
 	if v == "" {
 		return sql.NullTime{}, nil
 	}
-	t, err := time.Parse("2006-01-02", v)
+	t, err := time.ParseInLocation("2006-01-02", v, loc)
 	if err != nil {
 		return sql.NullTime{}, err
 	}
 	return sql.NullTime{Time: t, Valid: true}, nil
 }
 
-func parseDateTime(v string) (sql.NullTime, error) {
+func parseDateTime(v string, loc *time.Location) (sql.NullTime, error) {
 	v = strings.TrimSpace(v)
 	if v == "" {
 		return sql.NullTime{}, nil
 	}
 	layouts := []string{"2006-01-02 15:04", "2006-01-02"}
 	for _, layout := range layouts {
-		if t, err := time.Parse(layout, v); err == nil {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
 			return sql.NullTime{Time: t, Valid: true}, nil
 		}
 	}
@@ -2837,9 +3365,9 @@ func formatDateTime(t sql.NullTime) string {
 	return t.Time.Format("2006-01-02 15:04")
 }
 
-func displayDate(t sql.NullTime) string {
+func (m Model) displayDate(t sql.NullTime) string {
 	if t.Valid {
-		return formatDateTime(t)
+		return m.formatWhen(t)
 	}
 	return "Unknown"
 }
@@ -2879,6 +3407,12 @@ func (m Model) renderMetaBox() string {
 		m.meta.timezone,
 		m.meta.rule,
 		m.meta.interval,
+		m.meta.estimate,
+		m.meta.status,
+		m.meta.reminders,
+		m.meta.onComplete,
+		m.meta.ends,
+		m.meta.skip,
 	}
 	var b strings.Builder
 	for i, name := range fields {
@@ -2906,6 +3440,9 @@ func (m *Model) refreshReport() {
 
 	var overdue, todayList, upcoming, recurring []storage.Task
 	for _, t := range m.tasks {
+		if isSnoozed(t, now) {
+			continue
+		}
 		if isRecurringTask(t) && !t.Done {
 			recurring = append(recurring, t)
 		}
@@ -2913,6 +3450,10 @@ func (m *Model) refreshReport() {
 			continue
 		}
 		d := t.Due.Time
+		if isAllDay(d) {
+			// A bare date is that day here too, whatever the task's zone.
+			d = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, now.Location())
+		}
 		if d.Before(today) {
 			overdue = append(overdue, t)
 			continue
@@ -2964,8 +3505,8 @@ func (m *Model) refreshReport() {
 				return
 			}
 			for _, t := range tasks {
-				due := formatDateTime(t.Due)
-				line := fmt.Sprintf("  • #%d %-40s  due %s", t.ID, truncateText(t.Title, 40), due)
+				due := m.formatWhen(t.Due)
+				line := fmt.Sprintf("  • #%d %-40s  due %s", t.ID, truncateText(t.Title, 40), due) + estimateSuffix(t)
 				b.WriteString(style.Render(line))
 				b.WriteString("\n")
 			}
@@ -2986,7 +3527,7 @@ func (m *Model) refreshReport() {
 		for _, t := range recurring {
 			due := "no due"
 			if t.Due.Valid {
-				due = fmt.Sprintf("due %s", formatDateTime(t.Due))
+				due = fmt.Sprintf("due %s", m.formatWhen(t.Due))
 			}
 			next := ""
 			if nextDate, ok := nextRecurrenceDate(t); ok {
@@ -2996,11 +3537,26 @@ func (m *Model) refreshReport() {
 			if next != "" {
 				line += " • " + next
 			}
+			if end := seriesEndLabel(t); end != "" {
+				line += " • " + end
+			}
+			if note := overrideNote(t); note != "" {
+				line += " • " + note
+			}
 			b.WriteString(m.styles.Warning.Render(line))
 			b.WriteString("\n")
 		}
 		b.WriteString("\n")
 	}
+	if lines := m.loadReportLines(today); len(lines) > 0 {
+		b.WriteString(m.styles.Heading.Render("Planned Load (7d)"))
+		b.WriteString("\n")
+		for _, line := range lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
 	writeDivider()
 
 	recentAdd := m.recentlyAdded(m.recentLimit)
@@ -3027,6 +3583,14 @@ func (m *Model) refreshReport() {
 		}
 	}
 	b.WriteString("\n")
+	if sessions, err := m.store.FocusSessionsSince(today); err == nil && len(sessions) > 0 {
+		writeSectionHeader("Focus Sessions Today", len(sessions))
+		for _, line := range m.focusReportLines(sessions) {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
 	m.report = b.String()
 	m.status = "Reminder report"
 	m.reportScroll = 0
@@ -3051,29 +3615,48 @@ func (m Model) renderMetadataPanel() string {
 	}
 	rows := []row{
 		{label: "Title", value: ""},
+		{label: "Status", value: ""},
 		{label: "Topics", value: ""},
 		{label: "Tags", value: ""},
 		{label: "Priority", value: ""},
+		{label: "Estimate", value: ""},
 		{label: "Start", value: ""},
 		{label: "Timezone", value: ""},
 		{label: "Recurrence", value: ""},
+		{label: "Upcoming", value: ""},
+		{label: "Reminders", value: ""},
+		{label: "Attachments", value: ""},
 	}
 	if ok {
 		rows[0].value = task.Title
-		rows[1].value = emptyPlaceholder(strings.Join(task.Topics, ", "))
-		rows[2].value = emptyPlaceholder(task.Tags)
-		rows[3].value = fmt.Sprintf("%d", task.Priority)
-		rows[4].value = defaultStart(task)
-		rows[5].value = defaultTimezone(task.Timezone)
+		st, _ := m.taskState(task)
+		rows[1].value = st.Symbol + " " + st.Name
+		rows[2].value = emptyPlaceholder(strings.Join(task.Topics, ", "))
+		rows[3].value = emptyPlaceholder(task.Tags)
+		rows[4].value = fmt.Sprintf("%d", task.Priority)
+		rows[5].value = emptyPlaceholder(formatEstimate(task.EstimateMinutes))
+		rows[6].value = defaultStart(task)
+		rows[7].value = m.zoneLabel(task)
 		if recSummary := recurrenceSummary(task); recSummary != "" {
 			if next, ok := nextRecurrenceDate(task); ok {
-				rows[6].value = fmt.Sprintf("%s • Next: %s", recSummary, next.Format("2006-01-02"))
+				rows[8].value = fmt.Sprintf("%s • Next: %s • on done: %s", recSummary, next.Format("2006-01-02"), m.onCompleteMode(task))
 			} else {
-				rows[6].value = recSummary
+				rows[8].value = recSummary
 			}
 		} else {
-			rows[6].value = "off"
+			rows[8].value = "off"
+		}
+		var upcoming []string
+		for _, d := range upcomingOccurrences(task, 5) {
+			upcoming = append(upcoming, d.Format("Mon 01-02"))
+		}
+		rows[9].value = emptyPlaceholder(strings.Join(upcoming, ", "))
+		names := make([]string, 0, len(task.Attachments))
+		for _, a := range task.Attachments {
+			names = append(names, a.Name())
 		}
+		rows[10].value = m.remindersLabel(task)
+		rows[11].value = emptyPlaceholder(strings.Join(names, ", "))
 	} else {
 		for i := range rows {
 			rows[i].value = "(empty)"
@@ -3108,10 +3691,18 @@ func truncateText(text string, max int) string {
 }
 
 func (m Model) renderMarkdown(input string) string {
+	out, _ := m.renderMarkdownChecklist(input, -1)
+	return out
+}
+
+// renderMarkdownChecklist renders notes with checklist item `cursor`
+// highlighted and reports which output line it landed on (-1 if none).
+func (m Model) renderMarkdownChecklist(input string, cursor int) (string, int) {
 	var b strings.Builder
 	lines := strings.Split(input, "\n")
 	inCode := false
 	var codeLines []string
+	checkIndex, cursorLine := 0, -1
 	for _, raw := range lines {
 		line := strings.TrimRight(raw, "\r")
 		trim := strings.TrimSpace(line)
@@ -3149,6 +3740,21 @@ func (m Model) renderMarkdown(input string) string {
 			b.WriteString("\n")
 			continue
 		}
+		if checked, rest, ok := parseCheckbox(trim); ok {
+			box, text := "  ☐ ", m.renderInlineMarkdown(rest)
+			if checked {
+				box, text = "  ☑ ", m.styles.Done.Render(rest)
+			}
+			if checkIndex == cursor {
+				cursorLine = strings.Count(b.String(), "\n")
+				b.WriteString(m.styles.Selection.Render(box + rest))
+			} else {
+				b.WriteString(m.styles.Accent.Render(box) + text)
+			}
+			b.WriteString("\n")
+			checkIndex++
+			continue
+		}
 		if prefix, rest, ok := parseList(trim); ok {
 			b.WriteString(prefix)
 			b.WriteString(m.renderInlineMarkdown(rest))
@@ -3165,7 +3771,7 @@ func (m Model) renderMarkdown(input string) string {
 		b.WriteString(m.renderInlineMarkdown(line))
 		b.WriteString("\n")
 	}
-	return strings.TrimRight(b.String(), "\n")
+	return strings.TrimRight(b.String(), "\n"), cursorLine
 }
 
 func (m Model) noteBodyLines() []string {
@@ -3176,7 +3782,7 @@ func (m Model) noteBodyLines() []string {
 	if strings.TrimSpace(body) == "" {
 		return []string{m.styles.Muted.Render("(empty)")}
 	}
-	rendered := m.renderMarkdown(body)
+	rendered, _ := m.renderMarkdownChecklist(body, m.noteCheck)
 	return strings.Split(rendered, "\n")
 }
 
@@ -3204,14 +3810,32 @@ func (m Model) noteMetaBlockLines() []string {
 				recurrence = recSummary
 			}
 		}
+		st, _ := m.taskState(task)
 		rows = []row{
+			{label: "Status", value: st.Symbol + " " + st.Name},
 			{label: "Topics", value: emptyPlaceholder(strings.Join(task.Topics, ", "))},
 			{label: "Tags", value: emptyPlaceholder(task.Tags)},
 			{label: "Priority", value: fmt.Sprintf("%d", task.Priority)},
-			{label: "Due", value: emptyPlaceholder(formatDateTime(task.Due))},
-			{label: "Start", value: emptyPlaceholder(formatDate(task.Start))},
-			{label: "Timezone", value: emptyPlaceholder(defaultTimezone(task.Timezone))},
+			{label: "Estimate", value: emptyPlaceholder(formatEstimate(task.EstimateMinutes))},
+			{label: "Due", value: emptyPlaceholder(m.formatWhen(task.Due))},
+			{label: "Start", value: emptyPlaceholder(formatDate(m.shownTime(task.Start)))},
+			{label: "Timezone", value: m.zoneLabel(task)},
 			{label: "Recurrence", value: recurrence},
+			{label: "Reminders", value: m.remindersLabel(task)},
+		}
+		if isRecurringTask(task) {
+			summary, grid := m.habitLines(task, m.note.completions)
+			rows = append(rows, row{label: "Habit", value: summary})
+			for _, line := range grid {
+				rows = append(rows, row{value: line})
+			}
+		}
+		for i, line := range m.attachmentLines(task) {
+			label := ""
+			if i == 0 {
+				label = "Attachments"
+			}
+			rows = append(rows, row{label: label, value: line})
 		}
 	case noteTopic:
 		stats := m.topicStats()[m.note.target.topic]
@@ -3278,10 +3902,11 @@ func (m Model) noteMaxScroll() int {
 	return m.noteMaxScrollWith(available, len(bodyLines))
 }
 
-func (m Model) clearNote(target noteTarget) error {
+func (m *Model) clearNote(target noteTarget) error {
 	switch target.kind {
 	case noteTask:
-		return m.store.UpdateTaskNotes(target.taskID, "")
+		_, err := m.changeTask(target.taskID, func(t *storage.Task) { t.Notes = "" })
+		return err
 	case noteTopic:
 		return m.store.DeleteTopicNote(target.topic)
 	default:
@@ -3499,7 +4124,7 @@ func (m Model) renderStatusBar() string {
 		style = m.styles.StatusAlt
 	}
 	if m.mode == modeReport {
-		return style.Render(fmt.Sprintf("[bada] [%s] %s", modeLabel, m.status))
+		return style.Render(fmt.Sprintf("%s [%s] %s", m.statusTag(), modeLabel, m.status))
 	}
 	if m.mode == modeNote {
 		target := ""
@@ -3507,9 +4132,9 @@ func (m Model) renderStatusBar() string {
 			target = m.note.target.label()
 		}
 		if target != "" {
-			return style.Render(fmt.Sprintf("[bada] [%s] %s  %s", modeLabel, target, m.status))
+			return style.Render(fmt.Sprintf("%s [%s] %s  %s", m.statusTag(), modeLabel, target, m.status))
 		}
-		return style.Render(fmt.Sprintf("[bada] [%s] %s", modeLabel, m.status))
+		return style.Render(fmt.Sprintf("%s [%s] %s", m.statusTag(), modeLabel, m.status))
 	}
 	if m.mode == modeTrash {
 		sel := m.selectedTrashCount()
@@ -3518,7 +4143,7 @@ func (m Model) renderStatusBar() string {
 		if total > 0 {
 			cur = m.trashCursor + 1
 		}
-		return style.Render(fmt.Sprintf("[bada] [%s] cur:%d/%d sel:%d path:%s  %s", modeLabel, cur, total, sel, m.store.TrashDir(), m.status))
+		return style.Render(fmt.Sprintf("%s [%s] cur:%d/%d sel:%d path:%s  %s", m.statusTag(), modeLabel, cur, total, sel, m.store.TrashDir(), m.status))
 	}
 	total := len(m.visibleItems())
 	cursor := 0
@@ -3526,10 +4151,13 @@ func (m Model) renderStatusBar() string {
 		cursor = clampCursor(m.cursor, total) + 1
 	}
 	search := ""
+	if f := strings.ToLower(m.filterDone); f != "" && f != "all" {
+		search = " filter:" + f
+	}
 	if m.searchActive() {
-		search = fmt.Sprintf(" search:%q", m.searchQuery)
+		search += fmt.Sprintf(" search:%q", m.searchQuery)
 	}
-	return style.Render(fmt.Sprintf("[bada] [%s] sort:%s%s  %d/%d  %s", modeLabel, m.sortMode, search, cursor, total, m.status))
+	return style.Render(fmt.Sprintf("%s [%s] sort:%s%s  %d/%d  %s", m.statusTag(), modeLabel, m.sortMode, search, cursor, total, m.status))
 }
 
 func (m Model) fillView(body string) string {
@@ -3583,6 +4211,20 @@ func (m Model) modeLabel() string {
 		return "NOTE"
 	case modeReport:
 		return "REPORT"
+	case modeRestore:
+		return "RESTORE"
+	case modeWorkspace:
+		return "WORKSPACE"
+	case modeTimesheet:
+		return "TIMESHEET"
+	case modeFocus:
+		return "FOCUS"
+	case modeBoard:
+		return "BOARD"
+	case modePager:
+		return "OUTPUT"
+	case modeFinder:
+		return "FIND"
 	default:
 		return "?"
 	}
@@ -3613,9 +4255,10 @@ func (m Model) startConfig() (Model, tea.Cmd) {
 func (m Model) startSearch() (tea.Model, tea.Cmd) {
 	m.mode = modeSearch
 	m.input.SetValue(m.searchQuery)
-	m.input.Placeholder = "Search tasks"
+	m.input.Placeholder = "Search tasks (e.g. tag:urgent due<=+7d is:open)"
 	m.input.Focus()
-	m.status = "Search: type a query, Enter to apply, Esc to cancel"
+	m.searchHelp = false
+	m.status = "Search: type a query, ? for fields, Enter to apply, Esc to cancel"
 	return m, nil
 }
 
@@ -3627,24 +4270,69 @@ func (m Model) updateCommandMode(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd
 		m.status = "Command cancelled"
 		return m, nil
 	case "tab":
-		m.input.SetValue(completeCommand(m.input.Value()))
+		m.input.SetValue(m.completeCommand(m.input.Value()))
 		m.input.CursorEnd()
 		return m, nil
 	case m.cfg.Keys.Confirm, "enter":
 		cmd := strings.TrimSpace(m.input.Value())
-		cmdLower := strings.TrimPrefix(strings.ToLower(cmd), ":")
-		switch cmdLower {
+		name, arg, _ := strings.Cut(strings.TrimPrefix(cmd, ":"), " ")
+		arg = strings.TrimSpace(arg)
+		switch strings.ToLower(name) {
 		case "help":
 			return m.enterHelpView()
 		case "agenda":
 			return m.enterReportView()
 		case "calendar":
-			return m.enterCalendarView()
+			return m.openCalendar(arg)
 		case "gantt":
 			return m.enterGanttView()
 		case "config":
 			return m.startConfig()
+		case "backup":
+			return m.createBackup()
+		case "restore":
+			return m.enterRestoreView()
+		case "attach":
+			return m.attachToCurrent(arg)
+		case "detach":
+			return m.detachFromCurrent(arg)
+		case "open":
+			if task, ok := m.currentTask(); ok {
+				m.mode = modeList
+				m.input.Blur()
+				return m.openAttachment(task, arg)
+			}
+			m.status = "Select a task first"
+		case "timesheet":
+			return m.enterTimesheetView(arg)
+		case "focus":
+			return m.enterFocusView()
+		case "filter":
+			return m.applyFilter(arg)
+		case "board":
+			return m.enterBoardView(arg)
+		case "snooze":
+			return m.snoozeTasks(arg)
+		case "reschedule":
+			return m.rescheduleOccurrence(arg)
+		case "habits":
+			return m.enterHabitsView()
+		case "save-view":
+			return m.saveView(arg)
+		case "workspace", "ws":
+			if arg == "" {
+				return m.enterWorkspaceView(false)
+			}
+			return m.switchWorkspace(arg)
+		case "move":
+			if arg == "" {
+				return m.enterWorkspaceView(true)
+			}
+			return m.moveToWorkspace(arg)
 		default:
+			if custom, ok := m.customCommand(name); ok {
+				return m.runCustomCommand(strings.ToLower(name), custom, arg)
+			}
 			m.status = fmt.Sprintf("unknown command: %s", cmd)
 		}
 		m.mode = modeList
@@ -3657,7 +4345,7 @@ func (m Model) updateCommandMode(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd
 	}
 }
 
-func completeCommand(input string) string {
+func (m Model) completeCommand(input string) string {
 	raw := strings.TrimSpace(input)
 	prefix := ""
 	if strings.HasPrefix(raw, ":") {
@@ -3665,7 +4353,7 @@ func completeCommand(input string) string {
 		raw = strings.TrimPrefix(raw, ":")
 	}
 	cmd := strings.ToLower(raw)
-	commands := []string{"agenda", "calendar", "config", "gantt", "help"}
+	commands := append(append([]string{}, builtinCommands...), m.customCommandNames()...)
 	if cmd == "" {
 		return prefix + commands[0]
 	}
@@ -3705,14 +4393,14 @@ func (m Model) updateConfigMode(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd)
 			}
 			m.pendingCfgPath = value
 			m.configStage = configStageDB
-			m.input.SetValue(m.cfg.DBPath)
+			m.input.SetValue(m.workspacePaths.DBPath)
 			m.input.Placeholder = "DB path"
 			m.input.CursorEnd()
 			m.status = "DB path: Enter to save, Esc to cancel"
 			return m, nil
 		}
 		if value == "" {
-			value = m.cfg.DBPath
+			value = m.workspacePaths.DBPath
 		}
 		m.pendingDBPath = value
 		return m.applyConfigChanges()
@@ -3730,8 +4418,17 @@ func (m Model) updateSearchMode(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		m.input.Blur()
 		m.status = "Search cancelled"
 		return m, nil
+	case "?":
+		m.searchHelp = !m.searchHelp
+		return m, nil
 	case m.cfg.Keys.Confirm, "enter":
-		m.searchQuery = strings.TrimSpace(m.input.Value())
+		src := strings.TrimSpace(m.input.Value())
+		if _, err := query.Parse(src); err != nil {
+			m.status = fmt.Sprintf("query invalid: %v", err)
+			return m, nil
+		}
+		m.searchQuery = src
+		m.searchHelp = false
 		m.mode = modeList
 		m.input.Blur()
 		if m.searchActive() {
@@ -3755,18 +4452,18 @@ func (m Model) applyConfigChanges() (tea.Model, tea.Cmd) {
 	}
 	newDBPath := strings.TrimSpace(m.pendingDBPath)
 	if newDBPath == "" {
-		newDBPath = m.cfg.DBPath
+		newDBPath = m.workspacePaths.DBPath
 	}
 
 	oldConfigPath := m.configPath
-	oldDBPath := m.cfg.DBPath
+	oldDBPath := m.workspacePaths.DBPath
 
 	cfg := m.cfg
-	cfg.DBPath = newDBPath
+	cfg.SetWorkspaceDBPath(m.workspace, newDBPath)
 
 	var newStore *storage.Store
 	if newDBPath != oldDBPath {
-		store, err := storage.Open(newDBPath, cfg.TrashDir)
+		store, err := m.openWorkspaceStore(cfg, m.workspace)
 		if err != nil {
 			m.mode = modeList
 			m.input.Blur()
@@ -3800,6 +4497,9 @@ func (m Model) applyConfigChanges() (tea.Model, tea.Cmd) {
 	}
 
 	m.cfg = cfg
+	if _, paths, err := cfg.ResolveWorkspace(m.workspace); err == nil {
+		m.workspacePaths = paths
+	}
 	if newStore != nil {
 		_ = m.store.Close()
 		m.store = newStore
@@ -3884,7 +4584,7 @@ func (m Model) updateRenameMode(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd)
 				m.status = fmt.Sprintf("reload failed: %v", err)
 			}
 		} else {
-			if err := m.store.UpdateTitle(m.renameID, title); err != nil {
+			if _, err := m.changeTask(m.renameID, func(t *storage.Task) { t.Title = title }); err != nil {
 				m.status = fmt.Sprintf("rename failed: %v", err)
 				return m, nil
 			}
@@ -3942,15 +4642,16 @@ func (m Model) bumpPriority(delta int) (tea.Model, tea.Cmd) {
 	if newPrio > 5 {
 		newPrio = 5
 	}
-	if err := m.store.UpdatePriority(t.ID, newPrio); err != nil {
+	saved, err := m.changeTask(t.ID, func(t *storage.Task) { t.Priority = newPrio })
+	if err != nil {
 		m.status = fmt.Sprintf("priority failed: %v", err)
 		return m, nil
 	}
 	if idx := m.findTaskIndex(t.ID); idx >= 0 && idx < len(m.tasks) {
-		m.tasks[idx].Priority = newPrio
+		m.tasks[idx] = saved
 	}
 	m.pendingSort = true
-	m.status = fmt.Sprintf("Priority set to %d", newPrio)
+	m.status = fmt.Sprintf("Priority set to %d", saved.Priority)
 	return m, nil
 }
 
@@ -3959,17 +4660,19 @@ func (m Model) shiftDue(days int) (tea.Model, tea.Cmd) {
 	if !ok {
 		return m, nil
 	}
-	if err := m.store.ShiftDue(t.ID, days); err != nil {
+	saved, err := m.changeTask(t.ID, func(t *storage.Task) {
+		base := time.Now().UTC()
+		if t.Due.Valid {
+			base = t.Due.Time
+		}
+		t.Due = sql.NullTime{Time: base.AddDate(0, 0, days), Valid: true}
+	})
+	if err != nil {
 		m.status = fmt.Sprintf("shift due failed: %v", err)
 		return m, nil
 	}
-	base := time.Now().UTC()
-	if t.Due.Valid {
-		base = t.Due.Time
-	}
-	newTime := base.AddDate(0, 0, days)
 	if idx := m.findTaskIndex(t.ID); idx >= 0 && idx < len(m.tasks) {
-		m.tasks[idx].Due = sql.NullTime{Time: newTime, Valid: true}
+		m.tasks[idx] = saved
 	}
 	m.pendingSort = true
 	m.status = fmt.Sprintf("Due shifted by %+dd", days)
@@ -4000,14 +4703,7 @@ func (m *Model) sortTasks() {
 			return a.ID < b.ID
 		})
 	case "state":
-		sort.SliceStable(m.tasks, func(i, j int) bool {
-			a := m.tasks[i]
-			b := m.tasks[j]
-			if a.Done != b.Done {
-				return !a.Done && b.Done
-			}
-			return a.ID < b.ID
-		})
+		m.sortByState()
 	case "due":
 		sort.SliceStable(m.tasks, func(i, j int) bool {
 			di, dj := m.tasks[i].Due, m.tasks[j].Due
@@ -4079,6 +4775,59 @@ func overdueBadge(t storage.Task) string {
 	return fmt.Sprintf("[+%dd]", days)
 }
 
+type taskBadge struct {
+	text  string
+	style lipgloss.Style
+}
+
+// taskBadges collects the overdue, recurrence, checklist and snooze badges shown on
+// list rows and board cards.
+func (m Model) taskBadges(t storage.Task) []taskBadge {
+	var badges []taskBadge
+	if b := overdueBadge(t); b != "" {
+		badges = append(badges, taskBadge{text: b, style: m.styles.Danger})
+	}
+	if b := recurrenceBadge(t); b != "" {
+		badges = append(badges, taskBadge{text: b, style: m.styles.Warning})
+	}
+	if b := checklistBadge(t.Notes); b != "" {
+		badges = append(badges, taskBadge{text: b, style: m.styles.Muted})
+	}
+	if b := snoozeBadge(t); b != "" {
+		badges = append(badges, taskBadge{text: b, style: m.styles.Muted})
+	}
+	return badges
+}
+
+func renderBadges(badges []taskBadge, plain bool) string {
+	var b strings.Builder
+	for _, badge := range badges {
+		b.WriteString(" ")
+		if plain {
+			b.WriteString(badge.text)
+		} else {
+			b.WriteString(badge.style.Render(badge.text))
+		}
+	}
+	return b.String()
+}
+
+// remindersLabel shows a task's reminder offsets, marking the configured
+// defaults as such.
+func (m Model) remindersLabel(t storage.Task) string {
+	spec := strings.TrimSpace(t.Reminders)
+	if strings.EqualFold(spec, "off") || strings.EqualFold(spec, "none") {
+		return "off"
+	}
+	if spec != "" {
+		return spec
+	}
+	if len(m.cfg.Reminders.Offsets) == 0 {
+		return "off"
+	}
+	return strings.Join(m.cfg.Reminders.Offsets, ", ") + " (default)"
+}
+
 func overdueDetail(t storage.Task) string {
 	if !isOverdue(t) {
 		return ""
@@ -4087,13 +4836,6 @@ func overdueDetail(t storage.Task) string {
 	return fmt.Sprintf(" (overdue %dd)", days)
 }
 
-type recurrenceSpec struct {
-	every   int
-	unit    string
-	weekday *time.Weekday
-	label   string
-}
-
 func recurrenceBadge(t storage.Task) string {
 	if !isRecurringTask(t) {
 		return ""
@@ -4105,14 +4847,11 @@ func recurrenceBadge(t storage.Task) string {
 }
 
 func recurrenceRuleLabel(t storage.Task) string {
-	if spec, ok := parseRecurrenceSpec(t.RecurrenceRule); ok {
-		return spec.label
+	if r, ok := taskRule(t); ok {
+		return r.Label()
 	}
 	rule := strings.TrimSpace(t.RecurrenceRule)
-	if strings.ToLower(rule) == "none" {
-		rule = ""
-	}
-	if rule == "" {
+	if rule == "" || strings.EqualFold(rule, "none") {
 		return "recur"
 	}
 	return rule
@@ -4122,88 +4861,96 @@ func recurrenceSummary(t storage.Task) string {
 	if !isRecurringTask(t) {
 		return ""
 	}
-	if spec, ok := parseRecurrenceSpec(t.RecurrenceRule); ok {
-		return spec.label
+	if r, ok := taskRule(t); ok {
+		if end := seriesEndLabel(t); end != "" {
+			return r.Label() + ", " + end
+		}
+		return r.Label()
 	}
 	rule := strings.TrimSpace(t.RecurrenceRule)
 	if rule == "" || strings.EqualFold(rule, "none") {
 		rule = "custom"
 	}
-	if t.RecurrenceInterval > 0 {
-		return fmt.Sprintf("%s/%dd", rule, t.RecurrenceInterval)
-	}
 	return rule
 }
 
+// seriesEndLabel is "ends 2026-12-31" or "3 left" for a series that stops.
+func seriesEndLabel(t storage.Task) string {
+	r, ok := taskRule(t)
+	switch {
+	case !ok:
+		return ""
+	case !r.Until.IsZero():
+		return "ends " + r.Until.Format("2006-01-02")
+	case r.Count > 0:
+		return fmt.Sprintf("%d left", r.Count)
+	}
+	return ""
+}
+
 func isRecurringTask(t storage.Task) bool {
 	rule := strings.ToLower(strings.TrimSpace(t.RecurrenceRule))
-	return t.Recurring || (rule != "" && rule != "none")
+	if !t.Recurring && (rule == "" || rule == "none") {
+		return false
+	}
+	return !seriesEnded(t)
 }
 
-func parseRecurrenceSpec(input string) (recurrenceSpec, bool) {
-	raw := strings.TrimSpace(input)
-	if raw == "" {
-		return recurrenceSpec{}, false
-	}
-	everyRe := regexp.MustCompile(`(?i)^every\s*(\d+)?\s*(day|days|week|weeks|month|months)(?:\s+on\s+([a-z]+))?$`)
-	dailyRe := regexp.MustCompile(`(?i)^(daily|weekly|monthly)(?:\s+on\s+([a-z]+))?$`)
-	if m := everyRe.FindStringSubmatch(raw); m != nil {
-		count := 1
-		if strings.TrimSpace(m[1]) != "" {
-			if v, err := strconv.Atoi(m[1]); err == nil && v > 0 {
-				count = v
-			}
-		}
-		unit := strings.ToLower(m[2])
-		unit = strings.TrimSuffix(unit, "s")
-		var weekday *time.Weekday
-		if strings.TrimSpace(m[3]) != "" {
-			if wd, ok := parseWeekday(m[3]); ok {
-				weekday = &wd
-			}
-		}
-		label := formatRecurrenceLabel(count, unit, weekday)
-		return recurrenceSpec{every: count, unit: unit, weekday: weekday, label: label}, true
-	}
-	if m := dailyRe.FindStringSubmatch(raw); m != nil {
-		unit := strings.ToLower(m[1])
-		switch unit {
-		case "daily":
-			unit = "day"
-		case "weekly":
-			unit = "week"
-		case "monthly":
-			unit = "month"
-		}
-		var weekday *time.Weekday
-		if strings.TrimSpace(m[2]) != "" {
-			if wd, ok := parseWeekday(m[2]); ok {
-				weekday = &wd
-			}
-		}
-		label := formatRecurrenceLabel(1, unit, weekday)
-		return recurrenceSpec{every: 1, unit: unit, weekday: weekday, label: label}, true
+// seriesEnded reports a series whose end comes before its own date, so no
+// occurrence is left.
+func seriesEnded(t storage.Task) bool {
+	r, err := recur.Parse(t.RecurrenceRule)
+	if err != nil || !r.HasEnd() {
+		return false
+	}
+	base, ok := recurrenceBaseDate(t)
+	if !ok {
+		return false
 	}
-	return recurrenceSpec{}, false
+	_, ok = r.Next(base, base.AddDate(0, 0, -1))
+	return !ok
 }
 
-func formatRecurrenceLabel(every int, unit string, weekday *time.Weekday) string {
-	unitLabel := unit
-	if every == 1 {
-		unitLabel = unit
-	} else {
-		unitLabel = unit + "s"
+// taskRule reads a task's stored rule (an RRULE, or the plain words older
+// versions stored); a bare interval means every N days.
+func taskRule(t storage.Task) (recur.Rule, bool) {
+	if r, err := recur.Parse(t.RecurrenceRule); err == nil {
+		return r, true
 	}
-	base := ""
-	if every == 1 {
-		base = "every " + unitLabel
-	} else {
-		base = fmt.Sprintf("every %d %s", every, unitLabel)
+	if t.RecurrenceInterval > 0 {
+		return recur.Rule{Freq: recur.Daily, Interval: t.RecurrenceInterval}, true
+	}
+	return recur.Rule{}, false
+}
+
+// editableRule is the rule as the metadata editor shows it: the label when
+// it reads back as the same rule, the RRULE otherwise.
+func editableRule(t storage.Task) string {
+	r, err := recur.Parse(t.RecurrenceRule)
+	if err != nil {
+		return t.RecurrenceRule
 	}
-	if weekday != nil {
-		base += " on " + weekdayShort(*weekday)
+	r = r.Pattern()
+	if back, err := recur.Parse(r.Label()); err == nil && back.String() == r.String() {
+		return r.Label()
 	}
-	return base
+	return r.String()
+}
+
+// ruleEnds and ruleSkips fill the metadata editor's Ends and Skip Dates
+// fields, which editableRule leaves out.
+func ruleEnds(t storage.Task) string {
+	if r, err := recur.Parse(t.RecurrenceRule); err == nil {
+		return r.EndText()
+	}
+	return ""
+}
+
+func ruleSkips(t storage.Task) string {
+	if r, err := recur.Parse(t.RecurrenceRule); err == nil {
+		return r.ExdatesText()
+	}
+	return ""
 }
 
 func parseWeekday(input string) (time.Weekday, bool) {
@@ -4247,26 +4994,35 @@ func weekdayShort(day time.Weekday) string {
 }
 
 func nextRecurrenceDate(t storage.Task) (time.Time, bool) {
+	return nextRecurrenceAfter(t, time.Now())
+}
+
+// nextRecurrenceAfter is the first occurrence on a day after from (or the
+// base date itself while that is still ahead).
+func nextRecurrenceAfter(t storage.Task, from time.Time) (time.Time, bool) {
 	if !isRecurringTask(t) {
 		return time.Time{}, false
 	}
-	base, ok := recurrenceBaseDate(t)
+	r, ok := taskRule(t)
 	if !ok {
 		return time.Time{}, false
 	}
-	now := time.Now().In(base.Location())
-	rule := strings.TrimSpace(t.RecurrenceRule)
-	useSpec := strings.HasPrefix(strings.ToLower(rule), "every")
-	if spec, ok := parseRecurrenceSpec(rule); ok && (useSpec || t.RecurrenceInterval == 0) {
-		return nextFromSpec(base, now, spec), true
-	}
-	if t.RecurrenceInterval > 0 {
-		return nextByDays(base, now, t.RecurrenceInterval), true
+	base, ok := recurrenceBaseDate(t)
+	if !ok {
+		return time.Time{}, false
 	}
-	if spec, ok := parseRecurrenceSpec(rule); ok {
-		return nextFromSpec(base, now, spec), true
+	return r.Next(base, from)
+}
+
+// upcomingOccurrences lists the next n occurrences from tomorrow on (or
+// from the base date while that is still ahead).
+func upcomingOccurrences(t storage.Task, n int) []time.Time {
+	r, ok := taskRule(t)
+	base, hasBase := recurrenceBaseDate(t)
+	if !isRecurringTask(t) || !ok || !hasBase {
+		return nil
 	}
-	return time.Time{}, false
+	return r.Upcoming(base, time.Now(), n)
 }
 
 func recurrenceBaseDate(t storage.Task) (time.Time, bool) {
@@ -4287,108 +5043,12 @@ func normalizeDate(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
 
-func nextByDays(base, now time.Time, interval int) time.Time {
-	base = normalizeDate(base)
-	now = normalizeDate(now)
-	if interval <= 0 {
-		return base
-	}
-	if base.After(now) {
-		return base
-	}
-	diffDays := int(now.Sub(base).Hours() / 24)
-	steps := diffDays/interval + 1
-	return base.AddDate(0, 0, steps*interval)
-}
-
-func nextFromSpec(base, now time.Time, spec recurrenceSpec) time.Time {
-	switch spec.unit {
-	case "day":
-		return nextByDays(base, now, spec.every)
-	case "week":
-		if spec.weekday != nil {
-			return nextWeeklyByWeekday(base, now, spec.every, *spec.weekday)
-		}
-		return nextByDays(base, now, spec.every*7)
-	case "month":
-		if spec.weekday != nil {
-			return nextMonthlyByWeekday(base, now, spec.every, *spec.weekday)
-		}
-		return nextByMonths(base, now, spec.every)
-	default:
-		return base
-	}
-}
-
-func nextWeeklyByWeekday(base, now time.Time, every int, weekday time.Weekday) time.Time {
-	if every <= 0 {
-		every = 1
-	}
-	base = normalizeDate(base)
-	now = normalizeDate(now)
-	weekStart := startOfWeek(base, time.Monday)
-	nowWeekStart := startOfWeek(now, time.Monday)
-	weeksSince := int(nowWeekStart.Sub(weekStart).Hours() / 24 / 7)
-	if weeksSince < 0 {
-		weeksSince = 0
-	}
-	adjust := weeksSince % every
-	if adjust != 0 {
-		weeksSince += every - adjust
-	}
-	for {
-		candidateWeek := weekStart.AddDate(0, 0, weeksSince*7)
-		candidate := candidateWeek.AddDate(0, 0, weekdayOffset(time.Monday, weekday))
-		if candidate.After(now) {
-			return candidate
-		}
-		weeksSince += every
-	}
-}
-
-func nextByMonths(base, now time.Time, every int) time.Time {
-	if every <= 0 {
-		every = 1
-	}
-	base = normalizeDate(base)
-	now = normalizeDate(now)
-	candidate := base
-	for !candidate.After(now) {
-		candidate = candidate.AddDate(0, every, 0)
-	}
-	return candidate
-}
-
-func nextMonthlyByWeekday(base, now time.Time, every int, weekday time.Weekday) time.Time {
-	if every <= 0 {
-		every = 1
-	}
-	base = normalizeDate(base)
-	now = normalizeDate(now)
-	candidate := firstWeekdayOfMonth(base, weekday)
-	for !candidate.After(now) {
-		base = base.AddDate(0, every, 0)
-		candidate = firstWeekdayOfMonth(base, weekday)
-	}
-	return candidate
-}
-
-func firstWeekdayOfMonth(date time.Time, weekday time.Weekday) time.Time {
-	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
-	offset := (int(weekday) - int(start.Weekday()) + 7) % 7
-	return start.AddDate(0, 0, offset)
-}
-
 func startOfWeek(date time.Time, weekStart time.Weekday) time.Time {
 	date = normalizeDate(date)
 	offset := (int(date.Weekday()) - int(weekStart) + 7) % 7
 	return date.AddDate(0, 0, -offset)
 }
 
-func weekdayOffset(weekStart, target time.Weekday) int {
-	return (int(target) - int(weekStart) + 7) % 7
-}
-
 func isOverdue(t storage.Task) bool {
 	if t.Done {
 		return false
@@ -4435,10 +5095,11 @@ func (m *Model) processSortKey(key string) bool {
 			m.sortMode = "state"
 			m.sortTasks()
 			m.pendingSort = false
-			m.status = "Sorted by state (pending first)"
+			m.status = "Sorted by state (workflow order)"
 		default:
 			m.status = "Sort cancelled"
 		}
+		m.rememberViewSort()
 		m.sortBuf = ""
 		return true
 	}
@@ -4563,72 +5224,26 @@ func filterYN(v string) string {
 	return ""
 }
 
-func filterRule(v string) string {
+func filterDateList(v string) string {
 	var b strings.Builder
 	for _, r := range v {
-		if r == '-' || r == '_' || r == '/' || r == ',' || r == ' ' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+		if (r >= '0' && r <= '9') || r == '-' || r == ',' || r == ' ' {
 			b.WriteRune(r)
 		}
 	}
 	return b.String()
 }
 
-func filterTimezone(v string) string {
+func filterRule(v string) string {
 	var b strings.Builder
 	for _, r := range v {
-		if r == '+' || r == '-' || r == ':' || r == ' ' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+		if strings.ContainsRune("-_/,:;=+ ", r) || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
 			b.WriteRune(r)
 		}
 	}
 	return b.String()
 }
 
-func defaultTimezone(v string) string {
-	v = strings.TrimSpace(v)
-	if v != "" {
-		return v
-	}
-	return localTimezoneOffset()
-}
-
-func normalizeTimezone(v string) string {
-	v = strings.TrimSpace(v)
-	if v == "" {
-		return localTimezoneOffset()
-	}
-	re := regexp.MustCompile(`(?i)^(utc)?\s*([+-])\s*(\d{1,2})(?::?(\d{2}))?$`)
-	m := re.FindStringSubmatch(v)
-	if m == nil {
-		return v
-	}
-	hours, err := strconv.Atoi(m[3])
-	if err != nil {
-		return v
-	}
-	mins := 0
-	if strings.TrimSpace(m[4]) != "" {
-		if val, err := strconv.Atoi(m[4]); err == nil {
-			mins = val
-		}
-	}
-	if hours < 0 || hours > 23 || mins < 0 || mins > 59 {
-		return v
-	}
-	return fmt.Sprintf("UTC%s%02d:%02d", m[2], hours, mins)
-}
-
-func localTimezoneOffset() string {
-	_, offset := time.Now().In(time.Local).Zone()
-	sign := "+"
-	if offset < 0 {
-		sign = "-"
-		offset = -offset
-	}
-	hours := offset / 3600
-	mins := (offset % 3600) / 60
-	return fmt.Sprintf("UTC%s%02d:%02d", sign, hours, mins)
-}
-
 type itemKind int
 
 const (
@@ -4661,7 +5276,7 @@ func (m Model) recentlyAdded(limit int) []storage.Task {
 func (m Model) recentlyDone(limit int) []storage.Task {
 	var done []storage.Task
 	for _, t := range m.tasks {
-		if t.Done {
+		if st, _ := m.taskState(t); t.Done && st.Done {
 			done = append(done, t)
 		}
 	}
@@ -4731,14 +5346,17 @@ func (m Model) visibleItems() []listItem {
 func (m Model) defaultVisibleItems() []listItem {
 	items := make([]listItem, 0)
 	if m.currentTopic == "" {
-		for _, topic := range []string{"RecentlyAdded", "RecentlyDone"} {
+		for _, topic := range []string{"RecentlyAdded", "RecentlyDone", snoozedTopic} {
 			items = append(items, listItem{kind: itemTopic, topic: topic})
 		}
+		for _, v := range m.cfg.Views {
+			items = append(items, listItem{kind: itemTopic, topic: viewTopic(v.Name)})
+		}
 		for _, topic := range m.sortedTopics() {
 			items = append(items, listItem{kind: itemTopic, topic: topic})
 		}
 		for _, t := range m.tasks {
-			if len(t.Topics) == 0 {
+			if len(t.Topics) == 0 && m.passesFilter(t) {
 				items = append(items, listItem{kind: itemTask, task: t})
 			}
 		}
@@ -4754,9 +5372,20 @@ func (m Model) defaultVisibleItems() []listItem {
 		for _, t := range m.recentlyDone(m.recentLimit) {
 			items = append(items, listItem{kind: itemTask, task: t})
 		}
+	case snoozedTopic:
+		for _, t := range m.snoozedTasks() {
+			items = append(items, listItem{kind: itemTask, task: t})
+		}
 	default:
+		if v, _, ok := m.savedView(m.currentTopic); ok {
+			tasks, _ := m.viewTasks(v)
+			for _, t := range tasks {
+				items = append(items, listItem{kind: itemTask, task: t})
+			}
+			break
+		}
 		for _, t := range m.tasks {
-			if taskHasTopic(t, m.currentTopic) {
+			if taskHasTopic(t, m.currentTopic) && m.passesFilter(t) {
 				items = append(items, listItem{kind: itemTask, task: t})
 			}
 		}
@@ -4765,11 +5394,11 @@ func (m Model) defaultVisibleItems() []listItem {
 }
 
 func (m Model) searchItems() []listItem {
-	query := strings.TrimSpace(m.searchQuery)
-	if query == "" {
+	q, err := query.Parse(strings.TrimSpace(m.searchQuery))
+	if err != nil || q.Empty() {
 		return m.defaultVisibleItems()
 	}
-	q := strings.ToLower(query)
+	env := m.queryEnv()
 	items := make([]listItem, 0)
 	var candidates []storage.Task
 	switch {
@@ -4777,17 +5406,27 @@ func (m Model) searchItems() []listItem {
 		candidates = m.recentlyAdded(m.recentLimit)
 	case m.currentTopic == "RecentlyDone":
 		candidates = m.recentlyDone(m.recentLimit)
+	case m.currentTopic == snoozedTopic:
+		candidates = m.snoozedTasks()
+	case strings.HasPrefix(m.currentTopic, viewTopicPrefix):
+		if v, _, ok := m.savedView(m.currentTopic); ok {
+			candidates, _ = m.viewTasks(v)
+		}
 	case m.currentTopic != "":
 		for _, t := range m.tasks {
-			if taskHasTopic(t, m.currentTopic) {
+			if taskHasTopic(t, m.currentTopic) && m.passesFilter(t) {
 				candidates = append(candidates, t)
 			}
 		}
 	default:
-		candidates = m.tasks
+		for _, t := range m.tasks {
+			if m.passesFilter(t) {
+				candidates = append(candidates, t)
+			}
+		}
 	}
 	for _, t := range candidates {
-		if taskMatchesQuery(t, q) {
+		if q.Match(t, env) {
 			items = append(items, listItem{kind: itemTask, task: t, topic: strings.Join(t.Topics, ",")})
 		}
 	}
@@ -4798,17 +5437,12 @@ func (m Model) searchActive() bool {
 	return strings.TrimSpace(m.searchQuery) != ""
 }
 
-func taskMatchesQuery(t storage.Task, query string) bool {
-	fields := []string{t.Title, strings.Join(t.Topics, " "), t.Tags}
-	if t.Due.Valid {
-		fields = append(fields, formatDateTime(t.Due))
-	}
-	for _, field := range fields {
-		if strings.Contains(strings.ToLower(field), query) {
-			return true
-		}
-	}
-	return false
+func queryHelp() string {
+	return "\nSearch (/, saved views, bada list):\n  " + strings.Join(query.Help, "\n  ") + "\n"
+}
+
+func (m Model) queryEnv() query.Env {
+	return query.Env{Now: time.Now(), States: m.cfg.States}
 }
 
 func taskHasTopic(t storage.Task, topic string) bool {
@@ -4843,8 +5477,9 @@ func uniqueTopics(topics []string) []string {
 
 func (m Model) topicStats() map[string]topicStat {
 	stats := make(map[string]topicStat)
+	now := time.Now()
 	for _, t := range m.tasks {
-		if len(t.Topics) == 0 {
+		if len(t.Topics) == 0 || isSnoozed(t, now) {
 			continue
 		}
 		overdue := isOverdue(t)
@@ -4896,23 +5531,14 @@ func (m Model) sortedTags() []string {
 	return tags
 }
 
-func commonTimezones() []string {
-	return []string{
-		"UTC+00:00", "UTC+01:00", "UTC+02:00", "UTC+03:00", "UTC+04:00",
-		"UTC+05:00", "UTC+05:30", "UTC+06:00", "UTC+07:00", "UTC+08:00",
-		"UTC+09:00", "UTC+10:00", "UTC+11:00", "UTC+12:00",
-		"UTC-01:00", "UTC-02:00", "UTC-03:00", "UTC-04:00", "UTC-05:00",
-		"UTC-06:00", "UTC-07:00", "UTC-08:00", "UTC-09:00", "UTC-10:00",
-		"UTC-11:00", "UTC-12:00",
-	}
-}
-
 func commonRecurrenceRules() []string {
 	return []string{
 		"daily", "weekly", "monthly", "yearly",
-		"every 2 days", "every 3 days", "every 7 days",
-		"every 2 weeks", "every month", "every year",
-		"weekdays", "weekends",
+		"every 2 days", "every 3 days", "every 2 weeks",
+		"every week on Mon, Wed, Fri", "every month on the 1st",
+		"every month on the last day", "every month on the 2nd Tue",
+		"every month on the last workday", "every year on Jan 1",
+		"weekdays", "weekends", "RRULE:FREQ=",
 	}
 }
 
@@ -4925,9 +5551,17 @@ func (m Model) metaCompletions(fieldIndex int, prefix string) []string {
 	case 2: // Tags
 		candidates = m.sortedTags()
 	case 6: // Timezone
-		candidates = commonTimezones()
+		return timezoneCompletions(prefix)
 	case 7: // Rule
 		candidates = commonRecurrenceRules()
+	case 10: // Status
+		candidates = m.stateNames()
+	case 11: // Reminders
+		candidates = []string{"off", "1d, 1h", "1h", "30m", "15m", "0m"}
+	case 12: // On Complete
+		candidates = []string{"advance", "spawn"}
+	case 13: // Ends
+		candidates = []string{"never", "5 times", "10 times", time.Now().AddDate(0, 3, 0).Format("2006-01-02")}
 	default:
 		return nil
 	}
@@ -4946,7 +5580,7 @@ func (m Model) metaCompletions(fieldIndex int, prefix string) []string {
 }
 
 func isSpecialTopic(topic string) bool {
-	return topic == "RecentlyAdded" || topic == "RecentlyDone"
+	return topic == "RecentlyAdded" || topic == "RecentlyDone" || topic == snoozedTopic || strings.HasPrefix(topic, viewTopicPrefix)
 }
 
 func (m Model) currentTopicItem() (string, bool) {
@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/config"
+	"bada/internal/query"
+	"bada/internal/storage"
+)
+
+// Saved views live in the topic list under this prefix so they can never
+// clash with a real topic of the same name.
+const viewTopicPrefix = "view:"
+
+var sortModes = []string{"auto", "state", "due", "priority", "created"}
+
+func viewTopic(name string) string {
+	return viewTopicPrefix + name
+}
+
+// topicLabel is how a topic row or header names a topic.
+func topicLabel(topic string) string {
+	return strings.TrimPrefix(topic, viewTopicPrefix)
+}
+
+func (m Model) savedView(topic string) (config.View, int, bool) {
+	if !strings.HasPrefix(topic, viewTopicPrefix) {
+		return config.View{}, -1, false
+	}
+	name := strings.TrimPrefix(topic, viewTopicPrefix)
+	for i, v := range m.cfg.Views {
+		if strings.EqualFold(v.Name, name) {
+			return v, i, true
+		}
+	}
+	return config.View{}, -1, false
+}
+
+// viewTasks runs a view's query; a query that no longer parses matches
+// nothing and returns the parse error.
+func (m Model) viewTasks(v config.View) ([]storage.Task, error) {
+	q, err := query.Parse(v.Query)
+	if err != nil {
+		return nil, err
+	}
+	filter := v.Filter
+	if filter == "" {
+		filter = m.filterDone
+	}
+	env := m.queryEnv()
+	var out []storage.Task
+	for _, t := range m.tasks {
+		if m.matchesFilter(t, filter) && q.Match(t, env) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (m Model) viewStats(v config.View) (topicStat, error) {
+	now := time.Now()
+	var stat topicStat
+	tasks, err := m.viewTasks(v)
+	for _, t := range tasks {
+		stat.total++
+		if !t.Done && t.Due.Valid && now.After(t.Due.Time) {
+			stat.overdue++
+		}
+	}
+	return stat, err
+}
+
+// openTopic enters a topic; saved views bring their own sort mode, and the
+// previous one comes back when the view is left.
+func (m *Model) openTopic(topic string) {
+	m.currentTopic = topic
+	v, _, ok := m.savedView(topic)
+	if !ok {
+		return
+	}
+	m.viewPrevSort = m.sortMode
+	if v.Sort != "" && v.Sort != m.sortMode {
+		m.sortMode = v.Sort
+		m.sortTasks()
+	}
+}
+
+func (m *Model) leaveTopic() {
+	m.currentTopic = ""
+	if m.viewPrevSort != "" {
+		m.sortMode = m.viewPrevSort
+		m.viewPrevSort = ""
+		m.sortTasks()
+	}
+}
+
+// rememberViewSort stores a sort change made inside a saved view in the
+// config, so the view opens that way next time.
+func (m *Model) rememberViewSort() {
+	v, idx, ok := m.savedView(m.currentTopic)
+	if !ok || v.Sort == m.sortMode {
+		return
+	}
+	m.cfg.Views[idx].Sort = m.sortMode
+	if err := m.saveConfig(); err != nil {
+		m.status = fmt.Sprintf("view sort not saved: %v", err)
+	}
+}
+
+// saveView stores the current search (and the :filter and sort in use) as a
+// view, replacing one with the same name.
+func (m Model) saveView(name string) (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	name = strings.TrimSpace(name)
+	if name == "" {
+		m.status = "usage: :save-view <name>"
+		return m, nil
+	}
+	src := strings.TrimSpace(m.searchQuery)
+	if src == "" {
+		if v, _, ok := m.savedView(m.currentTopic); ok {
+			src = v.Query
+		}
+	}
+	if src == "" {
+		m.status = "Nothing to save: search with / first"
+		return m, nil
+	}
+	if _, err := query.Parse(src); err != nil {
+		m.status = fmt.Sprintf("save view failed: %v", err)
+		return m, nil
+	}
+	view := config.View{Name: name, Query: src, Sort: m.sortMode}
+	if filter := strings.ToLower(strings.TrimSpace(m.filterDone)); filter != "" && filter != "all" {
+		view.Filter = filter
+	}
+	views := append([]config.View{}, m.cfg.Views...)
+	replaced := false
+	for i, v := range views {
+		if strings.EqualFold(v.Name, name) {
+			views[i] = view
+			replaced = true
+		}
+	}
+	if !replaced {
+		views = append(views, view)
+	}
+	prev := m.cfg.Views
+	m.cfg.Views = views
+	if err := m.saveConfig(); err != nil {
+		m.cfg.Views = prev
+		m.status = fmt.Sprintf("save view failed: %v", err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("Saved view %q: %s", name, src)
+	return m, nil
+}
+
+func (m Model) saveConfig() error {
+	if strings.TrimSpace(m.configPath) == "" {
+		return errors.New("no config file")
+	}
+	return config.Save(m.configPath, m.cfg)
+}
@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"bada/internal/config"
+	"bada/internal/storage"
+)
+
+// taskState resolves a task's workflow state. Tasks with no status (added
+// before workflows, or imported without one) or with a state that is no
+// longer configured fall back on their done flag.
+func (m Model) taskState(t storage.Task) (config.State, int) {
+	st, idx := config.ResolveState(m.cfg.States, t.Status, t.Done)
+	if idx == len(m.cfg.States) {
+		st.Symbol = humanDone(t.Done)
+	}
+	return st, idx
+}
+
+func (m Model) stateIndex(name string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return -1
+	}
+	for i, st := range m.cfg.States {
+		if st.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m Model) firstState(closed bool) (config.State, bool) {
+	for _, st := range m.cfg.States {
+		if closed && st.Done || !closed && !st.IsClosed() {
+			return st, true
+		}
+	}
+	return config.State{}, false
+}
+
+func (m Model) stateNames() []string {
+	names := make([]string, 0, len(m.cfg.States))
+	for _, st := range m.cfg.States {
+		names = append(names, st.Name)
+	}
+	return names
+}
+
+func (m Model) stateSymbol(t storage.Task, plain bool) string {
+	st, _ := m.taskState(t)
+	if plain || st.Color == "" || t.Done {
+		return st.Symbol
+	}
+	return applyFg(lipgloss.NewStyle(), st.Color).Render(st.Symbol)
+}
+
+func (m Model) stateTargets() []storage.Task {
+	if selected := m.selectedTaskList(); len(selected) > 0 {
+		return selected
+	}
+	if t, ok := m.currentTask(); ok {
+		return []storage.Task{t}
+	}
+	return nil
+}
+
+func (m Model) setTaskState(tasks []storage.Task, st config.State) (tea.Model, tea.Cmd) {
+	if len(tasks) == 0 {
+		m.status = "No task selected"
+		return m, nil
+	}
+	var recurred []storage.Task
+	for _, t := range tasks {
+		saved, err := m.changeTask(t.ID, func(t *storage.Task) { applyState(t, st) })
+		if err != nil {
+			_ = m.reloadTasks()
+			m.status = fmt.Sprintf("status failed: %v", err)
+			return m, nil
+		}
+		if st.Done && !t.Done && isRecurringTask(t) {
+			recurred = append(recurred, saved)
+		}
+	}
+	if err := m.reloadTasks(); err != nil {
+		m.status = fmt.Sprintf("reload failed: %v", err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("#%d → %s", tasks[0].ID, st.Name)
+	if len(recurred) == 1 && len(tasks) == 1 {
+		if next := recurred[0]; !next.Done {
+			m.status = fmt.Sprintf("#%d → %s, next due %s", next.ID, st.Name, m.formatWhen(next.Due))
+		} else {
+			m.status = fmt.Sprintf("#%d → %s, next occurrence added", next.ID, st.Name)
+		}
+	}
+	if len(tasks) > 1 {
+		m.selectedTasks = map[int]bool{}
+		m.status = fmt.Sprintf("%d task(s) → %s", len(tasks), st.Name)
+	}
+	return m, nil
+}
+
+// applyState is SetStatus on an in-memory task: closed states take it off
+// the open lists and only done states keep a completion time.
+func applyState(t *storage.Task, st config.State) {
+	t.Status = st.Name
+	t.Done = st.IsClosed()
+	if !st.Done {
+		t.CompletedAt = sql.NullTime{}
+	} else if !t.CompletedAt.Valid {
+		t.CompletedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	}
+}
+
+// toggleTaskState flips between the first open and the first done state.
+func (m Model) toggleTaskState(t storage.Task) (tea.Model, tea.Cmd) {
+	st, ok := m.firstState(!t.Done)
+	if !ok {
+		m.status = "no state to toggle to; check [[states]] in config"
+		return m, nil
+	}
+	return m.setTaskState([]storage.Task{t}, st)
+}
+
+func (m Model) stateMenu() string {
+	parts := make([]string, 0, len(m.cfg.States))
+	for _, st := range m.cfg.States {
+		if st.Key != "" {
+			parts = append(parts, fmt.Sprintf("%s %s", st.Key, st.Name))
+		}
+	}
+	return "Status: " + strings.Join(parts, " • ")
+}
+
+// processStatusKey handles the two-key "S <state key>" sequence.
+func (m *Model) processStatusKey(key string) (bool, tea.Cmd) {
+	if !m.statusBuf {
+		if key == m.cfg.Keys.Status && len(m.stateTargets()) > 0 {
+			m.statusBuf = true
+			m.status = m.stateMenu()
+			return true, nil
+		}
+		return false, nil
+	}
+	m.statusBuf = false
+	for _, st := range m.cfg.States {
+		if st.Key != "" && st.Key == key {
+			next, cmd := m.setTaskState(m.stateTargets(), st)
+			*m = next.(Model)
+			return true, cmd
+		}
+	}
+	m.status = "Status unchanged"
+	return true, nil
+}
+
+// passesFilter applies default_filter / :filter: "all", "open", "closed" or
+// a state name. Snoozed tasks never pass; they only show under Snoozed.
+func (m Model) passesFilter(t storage.Task) bool {
+	return m.matchesFilter(t, m.filterDone)
+}
+
+func (m Model) matchesFilter(t storage.Task, filter string) bool {
+	if isSnoozed(t, time.Now()) {
+		return false
+	}
+	switch filter := strings.ToLower(strings.TrimSpace(filter)); filter {
+	case "", "all":
+		return true
+	case "open":
+		return !t.Done
+	case "closed":
+		return t.Done
+	default:
+		if m.stateIndex(filter) < 0 {
+			return true
+		}
+		st, _ := m.taskState(t)
+		return st.Name == filter
+	}
+}
+
+func (m Model) applyFilter(arg string) (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if arg == "" {
+		arg = "all"
+	}
+	if arg != "all" && arg != "open" && arg != "closed" && m.stateIndex(arg) < 0 {
+		m.status = fmt.Sprintf("unknown filter %q: use all, open, closed or %s", arg, strings.Join(m.stateNames(), ", "))
+		return m, nil
+	}
+	m.filterDone = arg
+	m.cursor = clampCursor(m.cursor, len(m.visibleItems()))
+	m.status = fmt.Sprintf("Filter: %s", arg)
+	return m, nil
+}
+
+func (m Model) sortByState() {
+	sort.SliceStable(m.tasks, func(i, j int) bool {
+		_, a := m.taskState(m.tasks[i])
+		_, b := m.taskState(m.tasks[j])
+		if a != b {
+			return a < b
+		}
+		return m.tasks[i].ID < m.tasks[j].ID
+	})
+}
+
+func (m Model) stateName(t storage.Task) string {
+	st, _ := m.taskState(t)
+	return st.Name
+}
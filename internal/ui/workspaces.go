@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/config"
+	"bada/internal/storage"
+)
+
+func (m Model) statusTag() string {
+	tag := fmt.Sprintf("[bada:%s]", m.workspace)
+	if m.workspace == "" || (m.workspace == config.DefaultWorkspaceName && len(m.cfg.Workspaces) == 0) {
+		tag = "[bada]"
+	}
+	if timer := m.timerLabel(); timer != "" {
+		tag += " " + timer
+	}
+	if m.hookErr != "" {
+		tag += " [hook failed: " + truncateText(m.hookErr, 48) + "]"
+	}
+	return tag
+}
+
+func (m Model) openWorkspaceStore(cfg config.Config, name string) (*storage.Store, error) {
+	_, paths, err := cfg.ResolveWorkspace(name)
+	if err != nil {
+		return nil, err
+	}
+	store, err := storage.Open(paths.DBPath, paths.TrashDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.SetEncryption(m.store.Encryption()); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func (m Model) enterWorkspaceView(move bool) (tea.Model, tea.Cmd) {
+	m.input.Blur()
+	m.workspaceNames = m.cfg.WorkspaceNames()
+	m.workspaceMove = move
+	m.workspaceCursor = 0
+	for i, name := range m.workspaceNames {
+		if name == m.workspace {
+			m.workspaceCursor = i
+		}
+	}
+	if move {
+		count := len(m.moveTargets())
+		if count == 0 {
+			m.mode = modeList
+			m.status = "No task to move"
+			return m, nil
+		}
+		m.status = fmt.Sprintf("Move %d task(s) to: enter to pick, esc to cancel", count)
+	} else {
+		m.status = "Workspace: enter to switch, esc to cancel"
+	}
+	m.mode = modeWorkspace
+	return m, nil
+}
+
+func (m Model) updateWorkspaceMode(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case m.cfg.Keys.Cancel, "esc", m.cfg.Keys.Quit, "q":
+		m.mode = modeList
+		m.workspaceNames = nil
+		m.status = "Workspace unchanged"
+	case m.cfg.Keys.Up, "up":
+		if m.workspaceCursor > 0 {
+			m.workspaceCursor--
+		}
+	case m.cfg.Keys.Down, "down":
+		m.workspaceCursor = clampCursor(m.workspaceCursor+1, len(m.workspaceNames))
+	case m.cfg.Keys.Confirm, "enter":
+		if m.workspaceCursor >= len(m.workspaceNames) {
+			return m, nil
+		}
+		name := m.workspaceNames[m.workspaceCursor]
+		m.workspaceNames = nil
+		if m.workspaceMove {
+			return m.moveToWorkspace(name)
+		}
+		return m.switchWorkspace(name)
+	}
+	return m, nil
+}
+
+func (m Model) switchWorkspace(name string) (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	name, paths, err := m.cfg.ResolveWorkspace(name)
+	if err != nil {
+		m.status = err.Error()
+		return m, nil
+	}
+	if name == m.workspace {
+		m.status = fmt.Sprintf("Already in workspace %s", name)
+		return m, nil
+	}
+	store, err := m.openWorkspaceStore(m.cfg, name)
+	if err != nil {
+		m.status = fmt.Sprintf("workspace %s: %v", name, err)
+		return m, nil
+	}
+	tasks, err := store.FetchTasks()
+	if err != nil {
+		store.Close()
+		m.status = fmt.Sprintf("workspace %s: %v", name, err)
+		return m, nil
+	}
+	_ = m.store.Close()
+	m.store = store
+	m.workspace = name
+	m.workspacePaths = paths
+	m.tasks = tasks
+	m.sortTasks()
+	m.leaveTopic()
+	m.searchQuery = ""
+	m.selectedTasks = map[int]bool{}
+	m.trash = nil
+	m.trashSelected = map[int]bool{}
+	m.cursor = clampCursor(0, len(m.visibleItems()))
+	m.refreshReport()
+	m.refreshTimer()
+	m.status = fmt.Sprintf("Workspace %s (%s)", name, paths.DBPath)
+	return m.ensureTicking()
+}
+
+func (m Model) moveTargets() []storage.Task {
+	if selected := m.selectedTaskList(); len(selected) > 0 {
+		return selected
+	}
+	if t, ok := m.currentTask(); ok {
+		return []storage.Task{t}
+	}
+	return nil
+}
+
+func (m Model) moveToWorkspace(name string) (tea.Model, tea.Cmd) {
+	m.mode = modeList
+	m.input.Blur()
+	name, _, err := m.cfg.ResolveWorkspace(name)
+	if err != nil {
+		m.status = err.Error()
+		return m, nil
+	}
+	if name == m.workspace {
+		m.status = fmt.Sprintf("Tasks are already in workspace %s", name)
+		return m, nil
+	}
+	tasks := m.moveTargets()
+	if len(tasks) == 0 {
+		m.status = "No task to move"
+		return m, nil
+	}
+	dst, err := m.openWorkspaceStore(m.cfg, name)
+	if err != nil {
+		m.status = fmt.Sprintf("workspace %s: %v", name, err)
+		return m, nil
+	}
+	defer dst.Close()
+	ids, err := m.store.MoveTasks(tasks, dst)
+	if err != nil {
+		m.status = fmt.Sprintf("move failed: %v", err)
+		return m, nil
+	}
+	m.tasks, err = m.store.FetchTasks()
+	if err != nil {
+		m.status = fmt.Sprintf("reload failed: %v", err)
+		return m, nil
+	}
+	m.sortTasks()
+	m.selectedTasks = map[int]bool{}
+	m.cursor = clampCursor(m.cursor, len(m.visibleItems()))
+	m.refreshReport()
+	m.status = fmt.Sprintf("Moved %d task(s) to %s", len(tasks), name)
+	var remapped []string
+	for _, t := range tasks {
+		if id := ids[t.ID]; id != t.ID {
+			remapped = append(remapped, fmt.Sprintf("#%d→#%d", t.ID, id))
+		}
+	}
+	if len(remapped) > 0 {
+		m.status += fmt.Sprintf(" (id taken, remapped %s)", strings.Join(remapped, ", "))
+	}
+	return m, nil
+}
+
+func (m Model) renderWorkspaceView() string {
+	var b strings.Builder
+	b.WriteString(m.renderListBanner())
+	b.WriteString("\n\n")
+	title := "# Switch workspace"
+	if m.workspaceMove {
+		title = fmt.Sprintf("# Move %d task(s) to workspace", len(m.moveTargets()))
+	}
+	b.WriteString(m.styles.Accent.Render(title))
+	b.WriteString("\n\n")
+	for i, name := range m.workspaceNames {
+		marker := "  "
+		if name == m.workspace {
+			marker = "* "
+		}
+		path := ""
+		if _, paths, err := m.cfg.ResolveWorkspace(name); err == nil {
+			path = paths.DBPath
+		}
+		line := fmt.Sprintf(" %s%-16s %s", marker, name, m.styles.Muted.Render(path))
+		if i == m.workspaceCursor {
+			line = m.styles.Selection.Render(fmt.Sprintf(" %s%-16s %s", marker, name, path))
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.styles.Muted.Render("up/down move • enter pick • esc/q close • add more under [workspaces.<name>] in config"))
+	return b.String()
+}
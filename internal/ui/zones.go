@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"bada/internal/storage"
+)
+
+func taskLocation(t storage.Task) *time.Location {
+	return storage.Location(t.Timezone)
+}
+
+func isAllDay(v time.Time) bool {
+	h, m, s := v.Clock()
+	return h == 0 && m == 0 && s == 0
+}
+
+// shownTime is a task's due or start as the list shows it: in the task's own
+// zone, or in ours once the zone toggle is on. A date without a time is the
+// same day everywhere, so it is left alone.
+func (m Model) shownTime(v sql.NullTime) sql.NullTime {
+	if v.Valid && m.localTimes && !isAllDay(v.Time) {
+		v.Time = v.Time.In(time.Local)
+	}
+	return v
+}
+
+// formatWhen is formatDateTime for a task's due or start, with the zone's
+// abbreviation when a time is shown in a zone other than ours.
+func (m Model) formatWhen(v sql.NullTime) string {
+	v = m.shownTime(v)
+	s := formatDateTime(v)
+	if v.Valid && !isAllDay(v.Time) {
+		if _, offset := v.Time.Zone(); offset != localOffset(v.Time) {
+			s += " " + v.Time.Format("MST")
+		}
+	}
+	return s
+}
+
+func localOffset(at time.Time) int {
+	_, offset := at.In(time.Local).Zone()
+	return offset
+}
+
+// dueDay is the date a task is due on, for the calendar and the agenda.
+func (m Model) dueDay(t storage.Task) string {
+	return m.shownTime(t.Due).Time.Format("2006-01-02")
+}
+
+func (m Model) zoneLabel(t storage.Task) string {
+	label := defaultTimezone(t.Timezone)
+	if m.localTimes && label != defaultTimezone("") {
+		label += " (times shown in ours)"
+	}
+	return label
+}
+
+func (m Model) toggleZoneDisplay() (tea.Model, tea.Cmd) {
+	m.localTimes = !m.localTimes
+	if m.localTimes {
+		m.status = fmt.Sprintf("Showing times in our zone (%s)", defaultTimezone(""))
+	} else {
+		m.status = "Showing times in each task's zone"
+	}
+	return m, nil
+}
+
+func filterTimezone(v string) string {
+	var b strings.Builder
+	for _, r := range v {
+		if strings.ContainsRune("+-:/_ ", r) || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// defaultTimezone is the zone a task is shown with; tasks without one are
+// in ours.
+func defaultTimezone(v string) string {
+	v = strings.TrimSpace(v)
+	if v != "" {
+		return v
+	}
+	return storage.DefaultZone()
+}
+
+// normalizeTimezone turns the Timezone field into the IANA name stored with
+// the task. Names match case-insensitively, spaces stand for underscores,
+// and offsets such as UTC+09:00 still work.
+func normalizeTimezone(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return storage.DefaultZone(), nil
+	}
+	if name, ok := storage.ZoneFromOffset(v); ok {
+		return name, nil
+	}
+	v = strings.ReplaceAll(v, " ", "_")
+	for _, name := range zoneNames() {
+		if strings.EqualFold(name, v) {
+			return name, nil
+		}
+	}
+	if _, err := time.LoadLocation(v); err != nil || v == "Local" {
+		return "", fmt.Errorf("unknown zone %q: use a name such as Europe/Berlin", v)
+	}
+	return v, nil
+}
+
+var (
+	zoneNamesOnce sync.Once
+	zoneNamesList []string
+)
+
+// zoneNames lists the IANA zones in the system's zoneinfo database, or the
+// common ones when there is none to read.
+func zoneNames() []string {
+	zoneNamesOnce.Do(func() {
+		areas := map[string]bool{"Africa": true, "America": true, "Antarctica": true, "Arctic": true, "Asia": true,
+			"Atlantic": true, "Australia": true, "Europe": true, "Indian": true, "Pacific": true, "Etc": true}
+		dirs := []string{os.Getenv("ZONEINFO"), "/usr/share/zoneinfo", "/usr/share/lib/zoneinfo", "/usr/lib/locale/TZ"}
+		seen := map[string]bool{}
+		for _, dir := range dirs {
+			if dir == "" {
+				continue
+			}
+			_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return nil
+				}
+				name, err := filepath.Rel(dir, path)
+				if err != nil {
+					return nil
+				}
+				name = filepath.ToSlash(name)
+				if area, _, ok := strings.Cut(name, "/"); ok && areas[area] && !seen[name] {
+					seen[name] = true
+					zoneNamesList = append(zoneNamesList, name)
+				}
+				return nil
+			})
+			if len(zoneNamesList) > 0 {
+				break
+			}
+		}
+		if len(zoneNamesList) == 0 {
+			zoneNamesList = commonTimezones()
+		} else {
+			zoneNamesList = append(zoneNamesList, "UTC")
+		}
+		sort.Strings(zoneNamesList)
+	})
+	return zoneNamesList
+}
+
+func commonTimezones() []string {
+	return []string{
+		"UTC", "Europe/London", "Europe/Lisbon", "Europe/Paris", "Europe/Berlin",
+		"Europe/Madrid", "Europe/Rome", "Europe/Amsterdam", "Europe/Stockholm",
+		"Europe/Warsaw", "Europe/Athens", "Europe/Istanbul", "Europe/Moscow",
+		"Africa/Cairo", "Africa/Lagos", "Africa/Johannesburg", "Africa/Nairobi",
+		"Asia/Dubai", "Asia/Tehran", "Asia/Karachi", "Asia/Kolkata", "Asia/Kathmandu",
+		"Asia/Dhaka", "Asia/Bangkok", "Asia/Jakarta", "Asia/Singapore", "Asia/Shanghai",
+		"Asia/Hong_Kong", "Asia/Taipei", "Asia/Manila", "Asia/Seoul", "Asia/Tokyo",
+		"Australia/Perth", "Australia/Adelaide", "Australia/Brisbane", "Australia/Sydney",
+		"Pacific/Auckland", "Pacific/Honolulu", "America/Anchorage", "America/Los_Angeles",
+		"America/Denver", "America/Phoenix", "America/Chicago", "America/Mexico_City",
+		"America/New_York", "America/Toronto", "America/Bogota", "America/Lima",
+		"America/Halifax", "America/Santiago", "America/Sao_Paulo", "America/Argentina/Buenos_Aires",
+		"America/St_Johns",
+	}
+}
+
+// timezoneCompletions offers our zone and the common ones for an empty field;
+// otherwise any zone whose name, or any part of it after a slash, starts
+// with what was typed ("seo" finds Asia/Seoul, "new y" America/New_York).
+func timezoneCompletions(prefix string) []string {
+	if prefix == "" {
+		out := []string{defaultTimezone("")}
+		for _, name := range commonTimezones() {
+			if name != out[0] {
+				out = append(out, name)
+			}
+		}
+		return out
+	}
+	prefix = strings.ToLower(strings.ReplaceAll(prefix, " ", "_"))
+	var matches []string
+	for _, name := range zoneNames() {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, prefix) {
+			matches = append(matches, name)
+			continue
+		}
+		for _, part := range strings.Split(lower, "/")[1:] {
+			if strings.HasPrefix(part, prefix) {
+				matches = append(matches, name)
+				break
+			}
+		}
+	}
+	return matches
+}